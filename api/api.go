@@ -0,0 +1,13 @@
+package api
+
+import _ "embed"
+
+// OpenAPI contém a especificação OpenAPI 3 dos Serviços A e B.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// Docs contém a página da documentação interativa (Swagger UI) que carrega a especificação.
+//
+//go:embed docs.html
+var Docs []byte
@@ -1,25 +1,127 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/handlers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/server"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/service"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/logger"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-var serviceName string = "Serviço B"
-
 func main() {
-	config.LoadConfig(".")
+	configDir := flag.String("config", ".", "diretório com config.yaml, config.<perfil>.yaml e .env")
+	printConfig := flag.Bool("print-config", false, "imprime a configuração efetiva, sem segredos, e encerra")
+	flag.Parse()
+
+	if err := config.LoadConfig(*configDir); err != nil {
+		log.Fatalf("falha ao carregar a configuração: %v", err)
+	}
+
+	cfg := config.Get()
+	serviceName := cfg.GetServidorB().Nome
+	errValidacao := cfg.ValidaServicoB()
+
+	if *printConfig {
+		if err := cfg.Imprime(os.Stdout); err != nil {
+			log.Fatalf("falha ao imprimir a configuração: %v", err)
+		}
+		if errValidacao != nil {
+			fmt.Fprintf(os.Stderr, "configuração inválida para o %s:\n%v\n", serviceName, errValidacao)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if errValidacao != nil {
+		log.Fatalf("configuração inválida para o %s:\n%v", serviceName, errValidacao)
+	}
+
+	nivelLog, _ := cfg.GetLog().NivelSlog()
+	logger.Init(nivelLog)
 
-	server := server.NewServer(serviceName, 3001)
+	repositorio, err := historico.Abre(cfg.GetHistorico())
+	if err != nil {
+		log.Fatalf("falha ao abrir o histórico: %v", err)
+	}
+	defer repositorio.Fecha()
+	gravador := historico.NewGravador(repositorio, cfg.GetHistorico().Fila)
 
-	server.Run(func(mux *http.ServeMux) {
+	assinaturas, err := alertas.Abre(cfg.GetAlertas())
+	if err != nil {
+		log.Fatalf("falha ao abrir as assinaturas de alerta: %v", err)
+	}
+	defer assinaturas.Fecha()
+
+	// Só as assinaturas exigem chave de API; as demais rotas atendem o Serviço A, que não envia chave e
+	// identifica o cliente pelo baggage.
+	autenticacao := server.Autenticacao()
+	clienteDoServicoA := server.ClienteDoServicoA()
+	server := server.NewServer(cfg.GetServidorB(), cfg.GetTelemetria())
+	server.Use(clienteDoServicoA)
+	server.ObservaConfiguracao(*configDir, config.ServicoB)
+
+	ctx, cancela := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancela()
+
+	var tarefas sync.WaitGroup
+	server.Run(ctx, func(mux *http.ServeMux) {
 		tracer := otel.GetTracer(serviceName)
 
-		mux.Handle("GET /cidades/{cep}/temperaturas", otelhttp.NewHandler(http.HandlerFunc(handlers.ProcessaTemperaturasHandler(tracer)), "handlerServic-B"))
+		amostrador := service.NewAmostrador(tracer, handlers.NovoTemperaturasService(tracer), gravador)
+		tarefas.Add(1)
+		go func() {
+			defer tarefas.Done()
+			amostrador.Executa(ctx)
+		}()
+
+		avaliador := service.NewAvaliador(tracer, handlers.NovoTemperaturasService(tracer), func(cfg config.AlertasConfig) service.EntregadorWebhook {
+			return clients.NewWebhookClient(tracer, cfg)
+		}, assinaturas)
+		tarefas.Add(1)
+		go func() {
+			defer tarefas.Done()
+			avaliador.Executa(ctx)
+		}()
+
+		mux.HandleFunc("GET /cidades/{cep}/temperaturas", handlers.ProcessaTemperaturasHandler(tracer, gravador))
+		mux.HandleFunc("GET /v2/cidades/{cep}/temperaturas", handlers.ProcessaTemperaturasV2Handler(tracer, gravador))
+		mux.HandleFunc("GET /cidades/{uf}/{cidade}/temperaturas", handlers.ProcessaTemperaturasCidadeHandler(tracer, gravador))
+		mux.HandleFunc("GET /v2/cidades/{uf}/{cidade}/temperaturas", handlers.ProcessaTemperaturasCidadeV2Handler(tracer, gravador))
+		mux.HandleFunc("GET /cidades", handlers.BuscaCidadeHandler(tracer))
+		mux.HandleFunc("GET /ceps/{cep}", handlers.EnderecoHandler(tracer))
+		mux.HandleFunc("GET /historico", handlers.HistoricoHandler(tracer, repositorio))
+		mux.HandleFunc("GET /historico/estatisticas", handlers.EstatisticasHandler(tracer, repositorio))
+		mux.Handle("POST /assinaturas", autenticacao(http.HandlerFunc(handlers.CriaAssinaturaHandler(tracer, assinaturas))))
+		mux.Handle("GET /assinaturas", autenticacao(http.HandlerFunc(handlers.ListaAssinaturasHandler(tracer, assinaturas))))
+		mux.Handle("GET /assinaturas/{id}", autenticacao(http.HandlerFunc(handlers.BuscaAssinaturaHandler(tracer, assinaturas))))
+		mux.Handle("PUT /assinaturas/{id}", autenticacao(http.HandlerFunc(handlers.AtualizaAssinaturaHandler(tracer, assinaturas))))
+		mux.Handle("DELETE /assinaturas/{id}", autenticacao(http.HandlerFunc(handlers.RemoveAssinaturaHandler(tracer, assinaturas))))
+		mux.Handle("GET /assinaturas/{id}/falhas", autenticacao(http.HandlerFunc(handlers.ListaFalhasAssinaturaHandler(tracer, assinaturas))))
+		mux.HandleFunc("GET /openapi.json", handlers.OpenAPIHandler())
+		mux.HandleFunc("GET /docs", handlers.DocsHandler())
 	})
+
+	// O servidor já não atende; as rodadas em andamento terminam e as consultas da fila vão para o histórico
+	// antes que os repositórios sejam fechados.
+	cancela()
+	tarefas.Wait()
+	encerramento, cancelaEncerramento := context.WithTimeout(context.Background(), cfg.GetServidorB().ShutdownTimeout)
+	defer cancelaEncerramento()
+	if err := gravador.Encerra(encerramento); err != nil {
+		log.Printf("falha ao gravar as consultas pendentes no histórico: %v", err)
+	}
 }
@@ -1,22 +1,85 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/handlers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/grupos"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/server"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/logger"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-var serviceName = "Serviço A"
-
 func main() {
-	server := server.NewServer(serviceName, 3000)
+	configDir := flag.String("config", ".", "diretório com config.yaml, config.<perfil>.yaml e .env")
+	printConfig := flag.Bool("print-config", false, "imprime a configuração efetiva, sem segredos, e encerra")
+	flag.Parse()
+
+	if err := config.LoadConfig(*configDir); err != nil {
+		log.Fatalf("falha ao carregar a configuração: %v", err)
+	}
+
+	cfg := config.Get()
+	serviceName := cfg.GetServidorA().Nome
+	errValidacao := cfg.ValidaServicoA()
+
+	if *printConfig {
+		if err := cfg.Imprime(os.Stdout); err != nil {
+			log.Fatalf("falha ao imprimir a configuração: %v", err)
+		}
+		if errValidacao != nil {
+			fmt.Fprintf(os.Stderr, "configuração inválida para o %s:\n%v\n", serviceName, errValidacao)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if errValidacao != nil {
+		log.Fatalf("configuração inválida para o %s:\n%v", serviceName, errValidacao)
+	}
+
+	nivelLog, _ := cfg.GetLog().NivelSlog()
+	logger.Init(nivelLog)
+
+	repositorio, err := grupos.Abre(cfg.GetGrupos())
+	if err != nil {
+		log.Fatalf("falha ao abrir os grupos: %v", err)
+	}
+	defer repositorio.Fecha()
+	leituras := usecases.NewLeiturasCompartilhadas(cfg.GetGrupos().Concorrencia)
+
+	autenticacao := server.Autenticacao("/openapi.json", "/docs")
+	server := server.NewServer(cfg.GetServidorA(), cfg.GetTelemetria())
+	server.ObservaConfiguracao(*configDir, config.ServicoA)
+	server.Use(autenticacao)
+
+	ctx, cancela := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancela()
 
-	server.Run(func(mux *http.ServeMux) {
+	server.Run(ctx, func(mux *http.ServeMux) {
 		tracer := otel.GetTracer(serviceName)
 
-		mux.Handle("POST /temperaturas", otelhttp.NewHandler(http.HandlerFunc(handlers.CapturaTemperaturasHandler(tracer)), "handlerServic-A"))
+		mux.HandleFunc("POST /temperaturas", handlers.CapturaTemperaturasHandler(tracer))
+		mux.HandleFunc("POST /v2/temperaturas", handlers.CapturaTemperaturasV2Handler(tracer))
+		mux.HandleFunc("GET /temperaturas", handlers.ConsultaTemperaturasHandler(tracer))
+		mux.HandleFunc("GET /temperaturas/{cep}", handlers.ConsultaTemperaturasHandler(tracer))
+		mux.HandleFunc("GET /v2/temperaturas", handlers.ConsultaTemperaturasV2Handler(tracer))
+		mux.HandleFunc("GET /v2/temperaturas/{cep}", handlers.ConsultaTemperaturasV2Handler(tracer))
+		mux.HandleFunc("GET /grupos", handlers.ListaGruposHandler(tracer, repositorio))
+		mux.HandleFunc("GET /grupos/{nome}", handlers.BuscaGrupoHandler(tracer, repositorio))
+		mux.HandleFunc("PUT /grupos/{nome}", handlers.SalvaGrupoHandler(tracer, repositorio))
+		mux.HandleFunc("DELETE /grupos/{nome}", handlers.RemoveGrupoHandler(tracer, repositorio))
+		mux.HandleFunc("GET /grupos/{nome}/temperaturas", handlers.TemperaturasGrupoHandler(tracer, repositorio, leituras))
+		mux.HandleFunc("GET /openapi.json", handlers.OpenAPIHandler())
+		mux.HandleFunc("GET /docs", handlers.DocsHandler())
 	})
 }
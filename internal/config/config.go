@@ -2,71 +2,1042 @@ package config
 
 //import viper
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 type configApp struct {
-	AmbientePublicacao string `mapstructure:"AMBIENTE_PUBLICACAO"`
-	WeatherApiKey      string `mapstructure:"WEATHER_API_KEY"`
+	AmbientePublicacao string `mapstructure:"ambiente_publicacao" yaml:"ambiente_publicacao"`
+	WeatherApiKey      string `mapstructure:"weather_api_key" yaml:"weather_api_key"`
+
+	ServidorA   ServidorConfig    `mapstructure:"servidor_a" yaml:"servidor_a"`
+	ServidorB   ServidorConfig    `mapstructure:"servidor_b" yaml:"servidor_b"`
+	Log         LogConfig         `mapstructure:"log" yaml:"log"`
+	Idioma      IdiomaConfig      `mapstructure:"idioma" yaml:"idioma"`
+	Telemetria  TelemetriaConfig  `mapstructure:"telemetria" yaml:"telemetria"`
+	Temperatura TemperaturaConfig `mapstructure:"temperatura" yaml:"temperatura"`
+	Cache       CacheConfig       `mapstructure:"cache" yaml:"cache"`
+	Clima       ClimaConfig       `mapstructure:"clima" yaml:"clima"`
+
+	Autenticacao AutenticacaoConfig `mapstructure:"autenticacao" yaml:"autenticacao"`
+	Historico    HistoricoConfig    `mapstructure:"historico" yaml:"historico"`
+	Grupos       GruposConfig       `mapstructure:"grupos" yaml:"grupos"`
+
+	Monitoramento MonitoramentoConfig `mapstructure:"monitoramento" yaml:"monitoramento"`
+	Alertas       AlertasConfig       `mapstructure:"alertas" yaml:"alertas"`
+
+	ViaCep             UpstreamConfig `mapstructure:"viacep" yaml:"viacep"`
+	WeatherApi         UpstreamConfig `mapstructure:"weatherapi" yaml:"weatherapi"`
+	OpenMeteo          UpstreamConfig `mapstructure:"openmeteo" yaml:"openmeteo"`
+	OpenMeteoGeocoding UpstreamConfig `mapstructure:"openmeteo_geocoding" yaml:"openmeteo_geocoding"`
+	ServicoB           UpstreamConfig `mapstructure:"servico_b" yaml:"servico_b"`
+
+	// arquivosSegredo guarda os arquivos lidos por <CHAVE>_FILE, observados na recarga.
+	arquivosSegredo []string
 }
 
-var config *configApp
+// ServidorConfig identifica o serviço no tracing e define a porta HTTP em que ele escuta, os limites
+// das conexões e o tempo máximo de cada handler, que TimeoutsRotas pode sobrepor por rota. Com H2C, o
+// servidor sem TLS também aceita HTTP/2 em texto puro.
+type ServidorConfig struct {
+	Nome              string            `mapstructure:"nome" yaml:"nome"`
+	Porta             int               `mapstructure:"porta" yaml:"porta"`
+	TLS               TLSServidorConfig `mapstructure:"tls" yaml:"tls"`
+	ReadHeaderTimeout time.Duration     `mapstructure:"read_header_timeout" yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration     `mapstructure:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout      time.Duration     `mapstructure:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout       time.Duration     `mapstructure:"idle_timeout" yaml:"idle_timeout"`
+	MaxHeaderBytes    int               `mapstructure:"max_header_bytes" yaml:"max_header_bytes"`
+	HandlerTimeout    time.Duration     `mapstructure:"handler_timeout" yaml:"handler_timeout"`
+	TimeoutsRotas     string            `mapstructure:"timeouts_rotas" yaml:"timeouts_rotas"`
+	H2C               bool              `mapstructure:"h2c" yaml:"h2c"`
+	ShutdownTimeout   time.Duration     `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
 
-func LoadConfig(path string) {
-	if _, err := os.Stat(filepath.Join(path, ".env")); err == nil {
-		viper.SetConfigName(".env")
-		viper.SetConfigType("env")
-		viper.AddConfigPath(path)
-		viper.AutomaticEnv()
+// TimeoutsPorRota interpreta TimeoutsRotas, com entradas rota=duração separadas por vírgula ou uma por
+// linha. A rota é o padrão registrado no ServeMux, com ou sem o método, como "GET /temperaturas/{cep}"
+// ou "/openapi.json"; a duração zero deixa a rota sem limite.
+func (s ServidorConfig) TimeoutsPorRota() (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	entradas := strings.FieldsFunc(s.TimeoutsRotas, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
 
-		err := viper.ReadInConfig()
-		if err != nil {
-			panic(err)
+	for i, entrada := range entradas {
+		rota, duracao, ok := strings.Cut(strings.TrimSpace(entrada), "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(duracao))
+		if !ok || strings.TrimSpace(rota) == "" || err != nil || timeout < 0 {
+			return nil, fmt.Errorf("a entrada %d não está no formato rota=duração", i+1)
 		}
+		timeouts[strings.TrimSpace(rota)] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// TLSServidorConfig liga o TLS no servidor com o certificado e a chave em PEM. Com CAClientes, o servidor
+// exige dos clientes um certificado assinado por ela (mTLS). Os arquivos são relidos quando mudam.
+type TLSServidorConfig struct {
+	Habilitado  bool   `mapstructure:"habilitado" yaml:"habilitado"`
+	Certificado string `mapstructure:"certificado" yaml:"certificado"`
+	Chave       string `mapstructure:"chave" yaml:"chave"`
+	CAClientes  string `mapstructure:"ca_clientes" yaml:"ca_clientes"`
+}
+
+// TLSClienteConfig ajusta as conexões TLS de saída, usadas quando o endereço é https. CA substitui as
+// autoridades do sistema, Certificado e Chave são apresentados ao servidor (mTLS) e NomeServidor
+// sobrepõe o nome verificado no certificado dele. Os arquivos são relidos quando mudam.
+type TLSClienteConfig struct {
+	CA           string `mapstructure:"ca" yaml:"ca"`
+	Certificado  string `mapstructure:"certificado" yaml:"certificado"`
+	Chave        string `mapstructure:"chave" yaml:"chave"`
+	NomeServidor string `mapstructure:"nome_servidor" yaml:"nome_servidor"`
+}
+
+type LogConfig struct {
+	Nivel string `mapstructure:"nivel" yaml:"nivel"`
+}
+
+// NivelSlog converte o nível configurado (debug, info, warn ou error) para o slog.
+func (l LogConfig) NivelSlog() (slog.Level, error) {
+	var nivel slog.Level
+	err := nivel.UnmarshalText([]byte(l.Nivel))
+	return nivel, err
+}
+
+// IdiomaConfig define o idioma das mensagens de erro e das condições do tempo quando o Accept-Language
+// não indica nenhum dos idiomas do catálogo (pt-BR, en ou es).
+type IdiomaConfig struct {
+	Padrao string `mapstructure:"padrao" yaml:"padrao"`
+}
+
+// TelemetriaConfig define o collector OTLP e a fração dos traces iniciados no serviço que são amostrados (0 a 1).
+// Um endpoint https://host:porta usa TLS com as opções de TLS; sem esquema ou com http://, a conexão é aberta.
+type TelemetriaConfig struct {
+	CollectorEndpoint string           `mapstructure:"collector_endpoint" yaml:"collector_endpoint"`
+	Amostragem        float64          `mapstructure:"amostragem" yaml:"amostragem"`
+	TLS               TLSClienteConfig `mapstructure:"tls" yaml:"tls"`
+}
+
+// TemperaturaConfig guarda as opções padrão de arredondamento, aplicadas quando a requisição não as informa.
+type TemperaturaConfig struct {
+	Arredondamento string `mapstructure:"arredondamento" yaml:"arredondamento"`
+	Precisao       string `mapstructure:"precisao" yaml:"precisao"`
+}
+
+type CacheConfig struct {
+	MaxAge time.Duration `mapstructure:"max_age" yaml:"max_age"`
+}
+
+// ClimaConfig escolhe o provedor consultado pelo Serviço B, como alternar entre as chaves da WeatherAPI
+// e quanto dela pode ser consumido.
+type ClimaConfig struct {
+	Provedor      string     `mapstructure:"provedor" yaml:"provedor"`
+	RotacaoChaves string     `mapstructure:"rotacao_chaves" yaml:"rotacao_chaves"`
+	Cota          CotaConfig `mapstructure:"cota" yaml:"cota"`
+}
+
+// CotaConfig limita as chamadas do Serviço B à WeatherAPI por segundo e por mês, somando todas as chaves.
+// Zero em RequisicoesPorSegundo ou OrcamentoMensal não limita. O consumo do mês é gravado em ArquivoUso,
+// quando definido, para sobreviver a reinícios. Politica decide o que fazer quando um limite é atingido.
+type CotaConfig struct {
+	RequisicoesPorSegundo float64       `mapstructure:"requisicoes_por_segundo" yaml:"requisicoes_por_segundo"`
+	Rajada                int           `mapstructure:"rajada" yaml:"rajada"`
+	OrcamentoMensal       int           `mapstructure:"orcamento_mensal" yaml:"orcamento_mensal"`
+	ArquivoUso            string        `mapstructure:"arquivo_uso" yaml:"arquivo_uso"`
+	Politica              string        `mapstructure:"politica" yaml:"politica"`
+	EsperaMaxima          time.Duration `mapstructure:"espera_maxima" yaml:"espera_maxima"`
+}
+
+// HistoricoConfig define onde o Serviço B guarda as consultas atendidas. Com Arquivo vazio, o histórico
+// fica só em memória e se perde ao reiniciar. Fila é quantas consultas podem aguardar a gravação, que
+// acontece fora da requisição; com a fila cheia, as novas são descartadas.
+type HistoricoConfig struct {
+	Arquivo string `mapstructure:"arquivo" yaml:"arquivo"`
+	Fila    int    `mapstructure:"fila" yaml:"fila"`
+}
+
+// MonitoramentoConfig define os CEPs que o Serviço B consulta sozinho a cada Intervalo, para que o
+// histórico tenha leituras mesmo sem tráfego. Ceps tem os CEPs separados por vírgula ou por linha.
+type MonitoramentoConfig struct {
+	Ceps      string        `mapstructure:"ceps" yaml:"ceps"`
+	Intervalo time.Duration `mapstructure:"intervalo" yaml:"intervalo"`
+}
+
+// intervaloMonitoramentoMinimo protege a cota dos provedores de um intervalo configurado por engano.
+const intervaloMonitoramentoMinimo = time.Minute
+
+// ListaCeps retorna os CEPs monitorados, na ordem em que foram configurados.
+func (m MonitoramentoConfig) ListaCeps() []string {
+	return strings.FieldsFunc(m.Ceps, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// AlertasConfig define as assinaturas de alertas de temperatura do Serviço B. Arquivo é o SQLite das
+// assinaturas e das entregas que falharam; vazio, elas ficam só em memória. As regras são avaliadas a cada
+// Intervalo, e cada webhook é tentado até Tentativas vezes, com espera dobrando a partir de EsperaInicial e
+// Timeout por tentativa.
+type AlertasConfig struct {
+	Arquivo       string        `mapstructure:"arquivo" yaml:"arquivo"`
+	Intervalo     time.Duration `mapstructure:"intervalo" yaml:"intervalo"`
+	Tentativas    int           `mapstructure:"tentativas" yaml:"tentativas"`
+	EsperaInicial time.Duration `mapstructure:"espera_inicial" yaml:"espera_inicial"`
+	Timeout       time.Duration `mapstructure:"timeout" yaml:"timeout"`
+}
 
-		err = viper.Unmarshal(&config)
+// GruposConfig define os grupos de CEPs dos clientes do Serviço A. Arquivo é o SQLite dos grupos; vazio,
+// eles ficam só em memória. MaxCeps limita o tamanho de cada grupo. As leituras dos grupos passam por até
+// Concorrencia consultas simultâneas ao Serviço B, somando todos os clientes, e ficam em cache por Cache
+// (zero desliga o cache).
+type GruposConfig struct {
+	Arquivo      string        `mapstructure:"arquivo" yaml:"arquivo"`
+	MaxCeps      int           `mapstructure:"max_ceps" yaml:"max_ceps"`
+	Concorrencia int           `mapstructure:"concorrencia" yaml:"concorrencia"`
+	Cache        time.Duration `mapstructure:"cache" yaml:"cache"`
+}
+
+// Provedores de clima aceitos em clima.provedor.
+const (
+	ProvedorWeatherApi = "weatherapi"
+	ProvedorOpenMeteo  = "open-meteo"
+)
+
+var provedores = []string{ProvedorWeatherApi, ProvedorOpenMeteo}
+
+// Estratégias aceitas em clima.rotacao_chaves. Em failover a primeira chave disponível é sempre usada;
+// em round-robin as chaves disponíveis se alternam a cada consulta.
+const (
+	RotacaoFailover   = "failover"
+	RotacaoRoundRobin = "round-robin"
+)
+
+var rotacoes = []string{RotacaoFailover, RotacaoRoundRobin}
+
+// Políticas aceitas em clima.cota.politica. Em fila a consulta espera, até espera_maxima, pela vez no
+// limite por segundo; em falha ela é recusada na hora; em alternativo ela segue pela Open-Meteo.
+// O orçamento mensal esgotado não espera em nenhuma delas.
+const (
+	PoliticaFila        = "fila"
+	PoliticaFalha       = "falha"
+	PoliticaAlternativo = "alternativo"
+)
+
+var politicas = []string{PoliticaFila, PoliticaFalha, PoliticaAlternativo}
+
+// AutenticacaoConfig exige chave de API nas rotas do Serviço A e nas assinaturas de alerta do Serviço B. Chaves tem entradas cliente:chave separadas
+// por vírgula ou por linha; Limite vale para todos os clientes e Clientes sobrepõe o limite de cada um.
+type AutenticacaoConfig struct {
+	Habilitada bool                    `mapstructure:"habilitada" yaml:"habilitada"`
+	Chaves     string                  `mapstructure:"chaves" yaml:"chaves"`
+	Limite     LimiteConfig            `mapstructure:"limite" yaml:"limite"`
+	Clientes   map[string]LimiteConfig `mapstructure:"clientes" yaml:"clientes,omitempty"`
+}
+
+// LimiteConfig define o token bucket (requisições por segundo e rajada) e a cota diária de um cliente.
+// A cota diária zero não limita.
+type LimiteConfig struct {
+	RequisicoesPorSegundo float64 `mapstructure:"requisicoes_por_segundo" yaml:"requisicoes_por_segundo"`
+	Rajada                int     `mapstructure:"rajada" yaml:"rajada"`
+	CotaDiaria            int     `mapstructure:"cota_diaria" yaml:"cota_diaria"`
+}
+
+// ClientesPorChave interpreta Chaves e devolve o cliente de cada chave. Os erros não citam as chaves.
+func (a AutenticacaoConfig) ClientesPorChave() (map[string]string, error) {
+	clientes := map[string]string{}
+	entradas := strings.FieldsFunc(a.Chaves, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	for i, entrada := range entradas {
+		cliente, chave, ok := strings.Cut(entrada, ":")
+		if !ok || cliente == "" || chave == "" {
+			return nil, fmt.Errorf("configuração autenticacao.chaves (AUTENTICACAO_CHAVES) inválida: a entrada %d não está no formato cliente:chave", i+1)
+		}
+		if _, repetida := clientes[chave]; repetida {
+			return nil, fmt.Errorf("configuração autenticacao.chaves (AUTENTICACAO_CHAVES) inválida: a chave do cliente %q já pertence ao cliente %q", cliente, clientes[chave])
+		}
+		clientes[chave] = cliente
+	}
+
+	return clientes, nil
+}
+
+// LimiteDo retorna o limite do cliente, completando com Limite os campos que ele não sobrepõe.
+func (a AutenticacaoConfig) LimiteDo(cliente string) LimiteConfig {
+	limite := a.Limite
+	sobreposicao, ok := a.Clientes[cliente]
+	if !ok {
+		return limite
+	}
+
+	if sobreposicao.RequisicoesPorSegundo != 0 {
+		limite.RequisicoesPorSegundo = sobreposicao.RequisicoesPorSegundo
+	}
+	if sobreposicao.Rajada != 0 {
+		limite.Rajada = sobreposicao.Rajada
+	}
+	if sobreposicao.CotaDiaria != 0 {
+		limite.CotaDiaria = sobreposicao.CotaDiaria
+	}
+
+	return limite
+}
+
+// UpstreamConfig reúne o endereço e os limites de conexão de um serviço externo. Com H2C, um endereço
+// http:// é acessado por HTTP/2 em texto puro, o que o servidor precisa aceitar.
+type UpstreamConfig struct {
+	BaseURL        string           `mapstructure:"base_url" yaml:"base_url"`
+	ConnectTimeout time.Duration    `mapstructure:"connect_timeout" yaml:"connect_timeout"`
+	ReadTimeout    time.Duration    `mapstructure:"read_timeout" yaml:"read_timeout"`
+	Timeout        time.Duration    `mapstructure:"timeout" yaml:"timeout"`
+	MaxIdleConns   int              `mapstructure:"max_idle_conns" yaml:"max_idle_conns"`
+	Proxy          string           `mapstructure:"proxy" yaml:"proxy"`
+	TLS            TLSClienteConfig `mapstructure:"tls" yaml:"tls"`
+	H2C            bool             `mapstructure:"h2c" yaml:"h2c"`
+}
+
+// Prefixos das variáveis de cada upstream, por exemplo VIACEP_BASE_URL e SERVICO_B_READ_TIMEOUT.
+const (
+	UpstreamViaCep             = "VIACEP"
+	UpstreamWeatherApi         = "WEATHERAPI"
+	UpstreamOpenMeteo          = "OPENMETEO"
+	UpstreamOpenMeteoGeocoding = "OPENMETEO_GEOCODING"
+	UpstreamServicoB           = "SERVICO_B"
+)
+
+// Perfis aceitos em AMBIENTE_PUBLICACAO. Cada perfil pode sobrepor a configuração base com o arquivo config.<perfil>.yaml.
+const (
+	PerfilLocal = "LOCAL"
+	PerfilDemo  = "DEMO"
+	PerfilProd  = "PROD"
+)
+
+var perfis = []string{PerfilLocal, PerfilDemo, PerfilProd}
+
+var upstreamBaseURLPadrao = map[string]string{
+	UpstreamViaCep:             "https://viacep.com.br/ws/",
+	UpstreamWeatherApi:         "https://api.weatherapi.com/v1/",
+	UpstreamOpenMeteo:          "https://api.open-meteo.com/v1/",
+	UpstreamOpenMeteoGeocoding: "https://geocoding-api.open-meteo.com/v1/",
+	UpstreamServicoB:           "http://service-b:3001/",
+}
+
+// chavesSegredo podem ser lidas de um arquivo indicado em <CHAVE>_FILE (por exemplo WEATHER_API_KEY_FILE),
+// como fazem os secrets do Docker e do Kubernetes.
+var chavesSegredo = []string{"weather_api_key", "autenticacao.chaves"}
+
+// extensoesConfig lista os formatos aceitos para config.<ext> e config.<perfil>.<ext>, em ordem de preferência.
+var extensoesConfig = []string{"yaml", "yml", "toml"}
+
+const valorRedigido = "********"
+
+var config atomic.Pointer[configApp]
+
+func defineValoresPadrao(v *viper.Viper) {
+	v.SetDefault("ambiente_publicacao", "")
+	for _, chave := range chavesSegredo {
+		v.SetDefault(chave, "")
+		v.SetDefault(chave+"_file", "")
+	}
+
+	v.SetDefault("servidor_a.nome", "Serviço A")
+	v.SetDefault("servidor_a.porta", 3000)
+	v.SetDefault("servidor_b.nome", "Serviço B")
+	v.SetDefault("servidor_b.porta", 3001)
+	for _, servidor := range []string{"servidor_a", "servidor_b"} {
+		v.SetDefault(servidor+".tls.habilitado", false)
+		v.SetDefault(servidor+".tls.certificado", "")
+		v.SetDefault(servidor+".tls.chave", "")
+		v.SetDefault(servidor+".tls.ca_clientes", "")
+		v.SetDefault(servidor+".read_header_timeout", 5*time.Second)
+		v.SetDefault(servidor+".read_timeout", 15*time.Second)
+		v.SetDefault(servidor+".write_timeout", 30*time.Second)
+		v.SetDefault(servidor+".idle_timeout", 120*time.Second)
+		v.SetDefault(servidor+".max_header_bytes", 64<<10)
+		v.SetDefault(servidor+".handler_timeout", 25*time.Second)
+		v.SetDefault(servidor+".timeouts_rotas", "")
+		v.SetDefault(servidor+".h2c", false)
+		v.SetDefault(servidor+".shutdown_timeout", 30*time.Second)
+	}
+
+	v.SetDefault("log.nivel", "info")
+	v.SetDefault("idioma.padrao", string(i18n.Ingles))
+	v.SetDefault("telemetria.collector_endpoint", "localhost:4317")
+	v.SetDefault("telemetria.amostragem", 1.0)
+	defineTLSClientePadrao(v, "telemetria.tls.")
+	v.SetDefault("clima.provedor", ProvedorWeatherApi)
+	v.SetDefault("clima.rotacao_chaves", RotacaoFailover)
+	v.SetDefault("clima.cota.requisicoes_por_segundo", 0.0)
+	v.SetDefault("clima.cota.rajada", 1)
+	v.SetDefault("clima.cota.orcamento_mensal", 0)
+	v.SetDefault("clima.cota.arquivo_uso", "")
+	v.SetDefault("clima.cota.politica", PoliticaFila)
+	v.SetDefault("clima.cota.espera_maxima", 2*time.Second)
+	v.SetDefault("temperatura.arredondamento", "")
+	v.SetDefault("temperatura.precisao", "")
+	v.SetDefault("cache.max_age", 5*time.Minute)
+	v.SetDefault("autenticacao.habilitada", false)
+	v.SetDefault("autenticacao.limite.requisicoes_por_segundo", 5.0)
+	v.SetDefault("autenticacao.limite.rajada", 10)
+	v.SetDefault("autenticacao.limite.cota_diaria", 1000)
+	v.SetDefault("historico.arquivo", "")
+	v.SetDefault("historico.fila", 1000)
+	v.SetDefault("monitoramento.ceps", "")
+	v.SetDefault("monitoramento.intervalo", 15*time.Minute)
+	v.SetDefault("alertas.arquivo", "")
+	v.SetDefault("alertas.intervalo", 5*time.Minute)
+	v.SetDefault("alertas.tentativas", 5)
+	v.SetDefault("alertas.espera_inicial", time.Second)
+	v.SetDefault("alertas.timeout", 10*time.Second)
+	v.SetDefault("grupos.arquivo", "")
+	v.SetDefault("grupos.max_ceps", 50)
+	v.SetDefault("grupos.concorrencia", 4)
+	v.SetDefault("grupos.cache", time.Minute)
+
+	for upstream, baseURL := range upstreamBaseURLPadrao {
+		prefixo := strings.ToLower(upstream) + "."
+		v.SetDefault(prefixo+"base_url", baseURL)
+		v.SetDefault(prefixo+"connect_timeout", 5*time.Second)
+		v.SetDefault(prefixo+"read_timeout", 10*time.Second)
+		v.SetDefault(prefixo+"timeout", 10*time.Second)
+		v.SetDefault(prefixo+"max_idle_conns", 100)
+		v.SetDefault(prefixo+"proxy", "")
+		v.SetDefault(prefixo+"h2c", false)
+		defineTLSClientePadrao(v, prefixo+"tls.")
+	}
+}
+
+// defineTLSClientePadrao registra as chaves de TLS de saída para que também possam vir do ambiente.
+func defineTLSClientePadrao(v *viper.Viper, prefixo string) {
+	v.SetDefault(prefixo+"ca", "")
+	v.SetDefault(prefixo+"certificado", "")
+	v.SetDefault(prefixo+"chave", "")
+	v.SetDefault(prefixo+"nome_servidor", "")
+}
+
+// aliasesEnv lista as variáveis aceitas para chaves que também têm um nome herdado, em ordem de prioridade.
+var aliasesEnv = map[string][]string{
+	"telemetria.collector_endpoint": {"TELEMETRIA_COLLECTOR_ENDPOINT", "OTEL_COLLECTOR_ENDPOINT"},
+}
+
+// LoadConfig monta a configuração na ordem: valores padrão, config.yaml (ou .toml), config.<perfil>.yaml,
+// arquivo .env e, por último, variáveis de ambiente. Todos os arquivos são procurados em path e são opcionais.
+func LoadConfig(path string) error {
+	cfg, err := carrega(path)
+	if err != nil {
+		return err
+	}
+
+	config.Store(cfg)
+	return nil
+}
+
+func carrega(path string) (*configApp, error) {
+	v := viper.New()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	defineValoresPadrao(v)
+
+	for chave, nomes := range aliasesEnv {
+		v.BindEnv(append([]string{chave}, nomes...)...)
+	}
+
+	if err := mesclaArquivo(v, path, "config"); err != nil {
+		return nil, err
+	}
+
+	dotEnv, err := leDotEnv(v, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// O .env é mesclado antes e depois do perfil: antes para que possa definir AMBIENTE_PUBLICACAO,
+	// depois para continuar prevalecendo sobre config.<perfil>.yaml.
+	if err := v.MergeConfigMap(dotEnv); err != nil {
+		return nil, err
+	}
+
+	if perfil := strings.ToLower(v.GetString("ambiente_publicacao")); perfil != "" {
+		if err := mesclaArquivo(v, path, "config."+perfil); err != nil {
+			return nil, err
+		}
+		if err := v.MergeConfigMap(dotEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &configApp{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("falha ao interpretar a configuração: %w", err)
+	}
+
+	if err := cfg.leSegredos(v); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *configApp) leSegredos(v *viper.Viper) error {
+	destinos := map[string]*string{
+		"weather_api_key":     &c.WeatherApiKey,
+		"autenticacao.chaves": &c.Autenticacao.Chaves,
+	}
+
+	for _, chave := range chavesSegredo {
+		arquivo := v.GetString(chave + "_file")
+		if arquivo == "" {
+			continue
+		}
+		if *destinos[chave] != "" {
+			return fmt.Errorf("defina somente uma das configurações %s e %s_file (%s_FILE)", chave, chave, variavelAmbiente(chave))
+		}
+
+		conteudo, err := os.ReadFile(arquivo)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("falha ao ler o segredo %s_file (%s_FILE): %w", chave, variavelAmbiente(chave), err)
 		}
 
-		return
+		*destinos[chave] = strings.TrimSpace(string(conteudo))
+		c.arquivosSegredo = append(c.arquivosSegredo, arquivo)
+	}
+
+	return nil
+}
+
+func mesclaArquivo(v *viper.Viper, path, nome string) error {
+	for _, extensao := range extensoesConfig {
+		arquivo := filepath.Join(path, nome+"."+extensao)
+		if _, err := os.Stat(arquivo); err != nil {
+			continue
+		}
+
+		v.SetConfigFile(arquivo)
+		if err := v.MergeInConfig(); err != nil {
+			return fmt.Errorf("falha ao ler %s: %w", arquivo, err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// leDotEnv traduz as variáveis do .env (por exemplo VIACEP_BASE_URL) para as chaves da configuração.
+func leDotEnv(v *viper.Viper, path string) (map[string]any, error) {
+	valores := map[string]any{}
+
+	arquivo := filepath.Join(path, ".env")
+	if _, err := os.Stat(arquivo); err != nil {
+		return valores, nil
 	}
 
-	var ambientepublicacao string
-	if value, exists := os.LookupEnv("AMBIENTE_PUBLICACAO"); !exists {
-		panic("variabel de ambiente AMBIENTE_PUBLICACAO, não definida")
-	} else {
-		ambientepublicacao = value
+	dotEnv := viper.New()
+	dotEnv.SetConfigFile(arquivo)
+	dotEnv.SetConfigType("env")
+	if err := dotEnv.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("falha ao ler %s: %w", arquivo, err)
 	}
 
-	var weatherapikey string
-	if value, exists := os.LookupEnv("WEATHER_API_KEY"); !exists {
-		panic("variavel de ambiente WEATHER_API_KEY, não definida")
-	} else {
-		weatherapikey = value
+	for _, chave := range v.AllKeys() {
+		nomes, ok := aliasesEnv[chave]
+		if !ok {
+			nomes = []string{variavelAmbiente(chave)}
+		}
+
+		for _, nome := range nomes {
+			if !dotEnv.IsSet(nome) {
+				continue
+			}
+
+			atual := valores
+			partes := strings.Split(chave, ".")
+			for _, parte := range partes[:len(partes)-1] {
+				if _, ok := atual[parte]; !ok {
+					atual[parte] = map[string]any{}
+				}
+				atual = atual[parte].(map[string]any)
+			}
+			atual[partes[len(partes)-1]] = dotEnv.Get(nome)
+			break
+		}
 	}
-	config = &configApp{}
-	config.AmbientePublicacao = ambientepublicacao
-	config.WeatherApiKey = weatherapikey
 
-	return
+	return valores, nil
+}
+
+func variavelAmbiente(chave string) string {
+	return strings.ToUpper(strings.ReplaceAll(chave, ".", "_"))
 }
 
 func Get() *configApp {
-	if config == nil{
-		LoadConfig(".")
+	if cfg := config.Load(); cfg != nil {
+		return cfg
+	}
+
+	if err := LoadConfig("."); err != nil {
+		panic(err)
 	}
 
-	return config
+	return config.Load()
+}
+
+// Servico indica qual validação aplicar à configuração.
+type Servico int
+
+const (
+	ServicoA Servico = iota + 1
+	ServicoB
+)
+
+func (c *configApp) Valida(servico Servico) error {
+	if servico == ServicoA {
+		return c.ValidaServicoA()
+	}
+	return c.ValidaServicoB()
+}
+
+// ValidaServicoA verifica somente o que o Serviço A utiliza: o ambiente, o servidor, o cache, a autenticação,
+// os grupos e o endereço do Serviço B.
+func (c *configApp) ValidaServicoA() error {
+	return errors.Join(
+		c.validaComum(),
+		validaServidor("servidor_a", c.ServidorA),
+		validaDuracao("cache.max_age", c.Cache.MaxAge, true),
+		c.validaAutenticacao(),
+		c.validaGrupos(),
+		validaUpstream(UpstreamServicoB, c.ServicoB),
+	)
+}
+
+// ValidaServicoB verifica o ambiente, o servidor, o provedor de clima, as opções de temperatura, o histórico, o monitoramento, os alertas, a autenticação das assinaturas e os endereços da ViaCEP e dos provedores.
+// A chave da WeatherAPI só é exigida quando ela é o provedor escolhido.
+func (c *configApp) ValidaServicoB() error {
+	errs := []error{
+		c.validaComum(),
+		validaServidor("servidor_b", c.ServidorB),
+		c.validaTemperatura(),
+		c.validaAutenticacao(),
+		validaUpstream(UpstreamViaCep, c.ViaCep),
+		validaUpstream(UpstreamWeatherApi, c.WeatherApi),
+		validaUpstream(UpstreamOpenMeteo, c.OpenMeteo),
+		validaUpstream(UpstreamOpenMeteoGeocoding, c.OpenMeteoGeocoding),
+	}
+
+	if !slices.Contains(rotacoes, c.Clima.RotacaoChaves) {
+		errs = append(errs, fmt.Errorf("configuração clima.rotacao_chaves (CLIMA_ROTACAO_CHAVES) inválida: %q, use %s", c.Clima.RotacaoChaves, strings.Join(rotacoes, ", ")))
+	}
+
+	errs = append(errs, c.validaCota(), c.validaMonitoramento(), c.validaAlertas())
+	if c.Historico.Fila < 1 {
+		errs = append(errs, fmt.Errorf("configuração historico.fila (HISTORICO_FILA) inválida: %d", c.Historico.Fila))
+	}
+
+	switch c.Clima.Provedor {
+	case ProvedorWeatherApi:
+		errs = append(errs, requerido("weather_api_key", c.WeatherApiKey))
+	case ProvedorOpenMeteo:
+	default:
+		errs = append(errs, fmt.Errorf("configuração clima.provedor (CLIMA_PROVEDOR) inválida: %q, use %s", c.Clima.Provedor, strings.Join(provedores, ", ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaComum() error {
+	var errs []error
+	switch {
+	case c.AmbientePublicacao == "":
+		errs = append(errs, requerido("ambiente_publicacao", c.AmbientePublicacao))
+	case !slices.Contains(perfis, strings.ToUpper(c.AmbientePublicacao)):
+		errs = append(errs, fmt.Errorf("configuração ambiente_publicacao (AMBIENTE_PUBLICACAO) inválida: %q, use %s", c.AmbientePublicacao, strings.Join(perfis, ", ")))
+	}
+
+	errs = append(errs,
+		requerido("telemetria.collector_endpoint", c.Telemetria.CollectorEndpoint),
+		validaTLSCliente("telemetria.tls", c.Telemetria.TLS),
+	)
+
+	if c.Telemetria.Amostragem < 0 || c.Telemetria.Amostragem > 1 {
+		errs = append(errs, fmt.Errorf("configuração telemetria.amostragem (TELEMETRIA_AMOSTRAGEM) inválida: %v, use um valor entre 0 e 1", c.Telemetria.Amostragem))
+	}
+	if _, err := c.Log.NivelSlog(); err != nil {
+		errs = append(errs, fmt.Errorf("configuração log.nivel (LOG_NIVEL) inválida: %q, use debug, info, warn ou error", c.Log.Nivel))
+	}
+	if _, ok := i18n.ParseIdioma(c.Idioma.Padrao); !ok {
+		errs = append(errs, fmt.Errorf("configuração idioma.padrao (IDIOMA_PADRAO) inválida: %q, use pt-BR, en ou es", c.Idioma.Padrao))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaAutenticacao() error {
+	if !c.Autenticacao.Habilitada {
+		return nil
+	}
+
+	errs := []error{
+		requerido("autenticacao.chaves", c.Autenticacao.Chaves),
+		validaLimite("autenticacao.limite", c.Autenticacao.Limite),
+	}
+	if _, err := c.Autenticacao.ClientesPorChave(); err != nil {
+		errs = append(errs, err)
+	}
+	for cliente := range c.Autenticacao.Clientes {
+		errs = append(errs, validaLimite("autenticacao.clientes."+cliente, c.Autenticacao.LimiteDo(cliente)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaCota() error {
+	cota := c.Clima.Cota
+	var errs []error
+	if cota.RequisicoesPorSegundo < 0 {
+		errs = append(errs, fmt.Errorf("configuração clima.cota.requisicoes_por_segundo (CLIMA_COTA_REQUISICOES_POR_SEGUNDO) inválida: %v", cota.RequisicoesPorSegundo))
+	}
+	if cota.Rajada < 1 {
+		errs = append(errs, fmt.Errorf("configuração clima.cota.rajada (CLIMA_COTA_RAJADA) inválida: %d", cota.Rajada))
+	}
+	if cota.OrcamentoMensal < 0 {
+		errs = append(errs, fmt.Errorf("configuração clima.cota.orcamento_mensal (CLIMA_COTA_ORCAMENTO_MENSAL) inválida: %d", cota.OrcamentoMensal))
+	}
+	if !slices.Contains(politicas, cota.Politica) {
+		errs = append(errs, fmt.Errorf("configuração clima.cota.politica (CLIMA_COTA_POLITICA) inválida: %q, use %s", cota.Politica, strings.Join(politicas, ", ")))
+	}
+	errs = append(errs, validaDuracao("clima.cota.espera_maxima", cota.EsperaMaxima, true))
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaMonitoramento() error {
+	var errs []error
+	for _, cep := range c.Monitoramento.ListaCeps() {
+		if _, err := domain.NewCep(cep); err != nil {
+			errs = append(errs, fmt.Errorf("configuração monitoramento.ceps (MONITORAMENTO_CEPS) inválida: %q não é um CEP", cep))
+		}
+	}
+	if c.Monitoramento.Intervalo < intervaloMonitoramentoMinimo {
+		errs = append(errs, fmt.Errorf("configuração monitoramento.intervalo (MONITORAMENTO_INTERVALO) inválida: %s, use no mínimo %s", c.Monitoramento.Intervalo, intervaloMonitoramentoMinimo))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaAlertas() error {
+	errs := []error{
+		validaDuracao("alertas.espera_inicial", c.Alertas.EsperaInicial, false),
+		validaDuracao("alertas.timeout", c.Alertas.Timeout, false),
+	}
+	if c.Alertas.Intervalo < intervaloMonitoramentoMinimo {
+		errs = append(errs, fmt.Errorf("configuração alertas.intervalo (ALERTAS_INTERVALO) inválida: %s, use no mínimo %s", c.Alertas.Intervalo, intervaloMonitoramentoMinimo))
+	}
+	if c.Alertas.Tentativas < 1 {
+		errs = append(errs, fmt.Errorf("configuração alertas.tentativas (ALERTAS_TENTATIVAS) inválida: %d", c.Alertas.Tentativas))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaGrupos() error {
+	errs := []error{validaDuracao("grupos.cache", c.Grupos.Cache, true)}
+	if c.Grupos.MaxCeps < 1 {
+		errs = append(errs, fmt.Errorf("configuração grupos.max_ceps (GRUPOS_MAX_CEPS) inválida: %d", c.Grupos.MaxCeps))
+	}
+	if c.Grupos.Concorrencia < 1 {
+		errs = append(errs, fmt.Errorf("configuração grupos.concorrencia (GRUPOS_CONCORRENCIA) inválida: %d", c.Grupos.Concorrencia))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validaLimite(chave string, limite LimiteConfig) error {
+	var errs []error
+	if limite.RequisicoesPorSegundo <= 0 {
+		errs = append(errs, fmt.Errorf("configuração %s.requisicoes_por_segundo inválida: %v", chave, limite.RequisicoesPorSegundo))
+	}
+	if limite.Rajada < 1 {
+		errs = append(errs, fmt.Errorf("configuração %s.rajada inválida: %d", chave, limite.Rajada))
+	}
+	if limite.CotaDiaria < 0 {
+		errs = append(errs, fmt.Errorf("configuração %s.cota_diaria inválida: %d", chave, limite.CotaDiaria))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *configApp) validaTemperatura() error {
+	var errs []error
+	if _, err := domain.ParseModoArredondamento(c.Temperatura.Arredondamento); c.Temperatura.Arredondamento != "" && err != nil {
+		errs = append(errs, fmt.Errorf("configuração temperatura.arredondamento (TEMPERATURA_ARREDONDAMENTO) inválida: %q", c.Temperatura.Arredondamento))
+	}
+	if _, err := domain.ParsePrecisao(c.Temperatura.Precisao); c.Temperatura.Precisao != "" && err != nil {
+		errs = append(errs, fmt.Errorf("configuração temperatura.precisao (TEMPERATURA_PRECISAO) inválida: %q", c.Temperatura.Precisao))
+	}
+
+	return errors.Join(errs...)
+}
+
+func requerido(chave, valor string) error {
+	if valor == "" {
+		return fmt.Errorf("configuração %s (%s) não definida", chave, variavelAmbiente(chave))
+	}
+	return nil
+}
+
+func validaServidor(chave string, servidor ServidorConfig) error {
+	errs := []error{requerido(chave+".nome", servidor.Nome)}
+	if servidor.Porta < 1 || servidor.Porta > 65535 {
+		errs = append(errs, fmt.Errorf("configuração %s.porta (%s_PORTA) inválida: %d", chave, variavelAmbiente(chave), servidor.Porta))
+	}
+	if servidor.TLS.Habilitado {
+		errs = append(errs,
+			requerido(chave+".tls.certificado", servidor.TLS.Certificado),
+			requerido(chave+".tls.chave", servidor.TLS.Chave),
+		)
+	}
+
+	errs = append(errs,
+		validaDuracao(chave+".read_header_timeout", servidor.ReadHeaderTimeout, false),
+		validaDuracao(chave+".read_timeout", servidor.ReadTimeout, true),
+		validaDuracao(chave+".write_timeout", servidor.WriteTimeout, true),
+		validaDuracao(chave+".idle_timeout", servidor.IdleTimeout, true),
+		validaDuracao(chave+".handler_timeout", servidor.HandlerTimeout, true),
+		validaDuracao(chave+".shutdown_timeout", servidor.ShutdownTimeout, false),
+	)
+	if servidor.MaxHeaderBytes < 1 {
+		errs = append(errs, fmt.Errorf("configuração %s.max_header_bytes (%s_MAX_HEADER_BYTES) inválida: %d", chave, variavelAmbiente(chave), servidor.MaxHeaderBytes))
+	}
+
+	timeouts, err := servidor.TimeoutsPorRota()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("configuração %s.timeouts_rotas (%s_TIMEOUTS_ROTAS) inválida: %w", chave, variavelAmbiente(chave), err))
+		timeouts = map[string]time.Duration{}
+	}
+
+	// O timeout do handler precisa terminar antes do write_timeout, senão a resposta de timeout se perde.
+	if servidor.WriteTimeout > 0 {
+		timeouts[""] = servidor.HandlerTimeout
+		for _, rota := range slices.Sorted(maps.Keys(timeouts)) {
+			if timeouts[rota] >= servidor.WriteTimeout {
+				errs = append(errs, fmt.Errorf("configuração %s deve ser menor que %s.write_timeout (%s_WRITE_TIMEOUT): %s", nomeTimeout(chave, rota), chave, variavelAmbiente(chave), timeouts[rota]))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func nomeTimeout(chave, rota string) string {
+	if rota == "" {
+		return fmt.Sprintf("%s.handler_timeout (%s_HANDLER_TIMEOUT)", chave, variavelAmbiente(chave))
+	}
+	return fmt.Sprintf("%s.timeouts_rotas (%s_TIMEOUTS_ROTAS) da rota %q", chave, variavelAmbiente(chave), rota)
+}
+
+func validaTLSCliente(chave string, tls TLSClienteConfig) error {
+	if (tls.Certificado == "") != (tls.Chave == "") {
+		return fmt.Errorf("configuração %s.certificado e %s.chave (%s_CERTIFICADO e %s_CHAVE) devem ser definidas juntas", chave, chave, variavelAmbiente(chave), variavelAmbiente(chave))
+	}
+	return nil
+}
+
+func validaDuracao(chave string, valor time.Duration, permiteZero bool) error {
+	if valor < 0 || (valor == 0 && !permiteZero) {
+		return fmt.Errorf("configuração %s (%s) inválida: %s", chave, variavelAmbiente(chave), valor)
+	}
+	return nil
+}
+
+func validaUpstream(prefixo string, upstream UpstreamConfig) error {
+	chave := strings.ToLower(prefixo)
+	var errs []error
+
+	baseURL, err := url.Parse(upstream.BaseURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		errs = append(errs, fmt.Errorf("configuração %s.base_url (%s_BASE_URL) inválida: %q", chave, prefixo, upstream.BaseURL))
+	}
+
+	if upstream.Proxy != "" {
+		if proxy, err := url.Parse(upstream.Proxy); err != nil || proxy.Host == "" {
+			errs = append(errs, fmt.Errorf("configuração %s.proxy (%s_PROXY) inválida", chave, prefixo))
+		}
+	}
+
+	errs = append(errs,
+		validaDuracao(chave+".connect_timeout", upstream.ConnectTimeout, false),
+		validaDuracao(chave+".read_timeout", upstream.ReadTimeout, false),
+		validaDuracao(chave+".timeout", upstream.Timeout, false),
+	)
+
+	if upstream.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("configuração %s.max_idle_conns (%s_MAX_IDLE_CONNS) inválida: %d", chave, prefixo, upstream.MaxIdleConns))
+	}
+	errs = append(errs, validaTLSCliente(chave+".tls", upstream.TLS))
+	if upstream.H2C && baseURL != nil && baseURL.Scheme != "http" {
+		errs = append(errs, fmt.Errorf("configuração %s.h2c (%s_H2C) exige um %s.base_url http://", chave, prefixo, chave))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redigida devolve uma cópia da configuração sem segredos, própria para logs e para o --print-config.
+func (c *configApp) Redigida() configApp {
+	copia := *c
+	if copia.WeatherApiKey != "" {
+		copia.WeatherApiKey = valorRedigido
+	}
+	copia.Autenticacao.Chaves = redigeChavesClientes(copia.Autenticacao.Chaves)
+
+	copia.ViaCep.Proxy = redigeURL(copia.ViaCep.Proxy)
+	copia.WeatherApi.Proxy = redigeURL(copia.WeatherApi.Proxy)
+	copia.OpenMeteo.Proxy = redigeURL(copia.OpenMeteo.Proxy)
+	copia.OpenMeteoGeocoding.Proxy = redigeURL(copia.OpenMeteoGeocoding.Proxy)
+	copia.ServicoB.Proxy = redigeURL(copia.ServicoB.Proxy)
+
+	return copia
+}
+
+// redigeChavesClientes mantém os nomes dos clientes e esconde as chaves: cliente-a:********.
+func redigeChavesClientes(valor string) string {
+	entradas := strings.FieldsFunc(valor, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	for i, entrada := range entradas {
+		cliente, _, _ := strings.Cut(entrada, ":")
+		entradas[i] = cliente + ":" + valorRedigido
+	}
+
+	return strings.Join(entradas, ",")
+}
+
+func redigeURL(valor string) string {
+	endereco, err := url.Parse(valor)
+	if err != nil || endereco.User == nil {
+		return valor
+	}
+
+	return endereco.Redacted()
+}
+
+// Imprime escreve a configuração efetiva, com os segredos redigidos, em YAML.
+func (c *configApp) Imprime(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(c.Redigida()); err != nil {
+		return err
+	}
+
+	return encoder.Close()
 }
 
 func (c *configApp) GetAmbientePublicacao() string {
 	return c.AmbientePublicacao
 }
 
-func (c *configApp) GetWeatherApiKey() string {
-	return c.WeatherApiKey
+// GetWeatherApiKeys retorna as chaves da WeatherAPI, que podem ser várias separadas por vírgula ou
+// por linha (no arquivo de WEATHER_API_KEY_FILE), na ordem usada pelo failover.
+func (c *configApp) GetWeatherApiKeys() []string {
+	return strings.FieldsFunc(c.WeatherApiKey, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetTemperaturaArredondamento retorna o modo de arredondamento padrão (none, half-even ou ceil).
+func (c *configApp) GetTemperaturaArredondamento() string {
+	return c.Temperatura.Arredondamento
+}
+
+// GetTemperaturaPrecisao retorna a quantidade padrão de casas decimais.
+func (c *configApp) GetTemperaturaPrecisao() string {
+	return c.Temperatura.Precisao
+}
+
+func (c *configApp) GetServidorA() ServidorConfig {
+	return c.ServidorA
+}
+
+func (c *configApp) GetServidorB() ServidorConfig {
+	return c.ServidorB
+}
+
+func (c *configApp) GetLog() LogConfig {
+	return c.Log
+}
+
+// GetIdioma devolve o idioma padrão já normalizado, como pt-BR para pt-br.
+func (c *configApp) GetIdioma() i18n.Idioma {
+	if idioma, ok := i18n.ParseIdioma(c.Idioma.Padrao); ok {
+		return idioma
+	}
+	return i18n.Ingles
+}
+
+func (c *configApp) GetClima() ClimaConfig {
+	return c.Clima
+}
+
+func (c *configApp) GetOpenMeteo() UpstreamConfig {
+	return c.OpenMeteo
+}
+
+func (c *configApp) GetOpenMeteoGeocoding() UpstreamConfig {
+	return c.OpenMeteoGeocoding
+}
+
+func (c *configApp) GetAutenticacao() AutenticacaoConfig {
+	return c.Autenticacao
+}
+
+func (c *configApp) GetHistorico() HistoricoConfig {
+	return c.Historico
+}
+
+func (c *configApp) GetMonitoramento() MonitoramentoConfig {
+	return c.Monitoramento
+}
+
+func (c *configApp) GetAlertas() AlertasConfig {
+	return c.Alertas
+}
+
+func (c *configApp) GetGrupos() GruposConfig {
+	return c.Grupos
+}
+
+func (c *configApp) GetTelemetria() TelemetriaConfig {
+	return c.Telemetria
+}
+
+func (c *configApp) GetCache() CacheConfig {
+	return c.Cache
+}
+
+func (c *configApp) GetViaCep() UpstreamConfig {
+	return c.ViaCep
+}
+
+func (c *configApp) GetWeatherApi() UpstreamConfig {
+	return c.WeatherApi
+}
+
+func (c *configApp) GetServicoB() UpstreamConfig {
+	return c.ServicoB
 }
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Mudanca descreve a alteração de uma chave da configuração, com os valores já redigidos.
+type Mudanca struct {
+	Chave    string
+	Anterior string
+	Atual    string
+}
+
+func (m Mudanca) String() string {
+	return fmt.Sprintf("%s: %s -> %s", m.Chave, m.Anterior, m.Atual)
+}
+
+// Recarga é entregue aos ouvintes de Observa a cada alteração nos arquivos de configuração.
+// Quando Erro está preenchido a atualização foi rejeitada e Atual continua sendo a configuração anterior.
+type Recarga struct {
+	Anterior  *configApp
+	Atual     *configApp
+	Aplicadas []Mudanca
+	Ignoradas []Mudanca
+	Erro      error
+}
+
+var recargaMu sync.Mutex
+
+// Observa acompanha os arquivos de configuração existentes em path e recarrega, sem reiniciar o serviço,
+// o subconjunto seguro da configuração: nível de log, amostragem, provedor de clima, chaves da WeatherAPI,
+// autenticação e limites dos clientes, cache, opções de temperatura e timeouts/conexões dos upstreams. As demais alterações são reportadas em
+// Ignoradas. Os arquivos de segredo (<CHAVE>_FILE) também são observados. Retorna os arquivos observados.
+func Observa(path string, servico Servico, ouvintes ...func(Recarga)) []string {
+	arquivos := append(arquivosObservaveis(path, Get().AmbientePublicacao), Get().arquivosSegredo...)
+
+	for _, arquivo := range arquivos {
+		observador := viper.New()
+		observador.SetConfigFile(arquivo)
+		if !slices.Contains(viper.SupportedExts, strings.TrimPrefix(filepath.Ext(arquivo), ".")) {
+			// Arquivos de segredo não têm extensão; o viper só precisa de um tipo para aceitar observá-los.
+			observador.SetConfigType("env")
+		}
+		observador.OnConfigChange(func(fsnotify.Event) {
+			recarga := Recarrega(path, servico)
+			if recarga.Erro == nil && len(recarga.Aplicadas) == 0 && len(recarga.Ignoradas) == 0 {
+				return
+			}
+			for _, ouvinte := range ouvintes {
+				ouvinte(recarga)
+			}
+		})
+		observador.WatchConfig()
+	}
+
+	return arquivos
+}
+
+func arquivosObservaveis(path, ambiente string) []string {
+	nomes := []string{}
+	for _, extensao := range extensoesConfig {
+		nomes = append(nomes, "config."+extensao)
+		if ambiente != "" {
+			nomes = append(nomes, "config."+strings.ToLower(ambiente)+"."+extensao)
+		}
+	}
+	nomes = append(nomes, ".env")
+
+	arquivos := []string{}
+	for _, nome := range nomes {
+		arquivo := filepath.Join(path, nome)
+		if _, err := os.Stat(arquivo); err == nil {
+			arquivos = append(arquivos, arquivo)
+		}
+	}
+
+	return arquivos
+}
+
+// Recarrega relê a configuração de path e aplica somente as chaves recarregáveis. Uma configuração
+// inválida é rejeitada por inteiro e a anterior é mantida.
+func Recarrega(path string, servico Servico) Recarga {
+	recargaMu.Lock()
+	defer recargaMu.Unlock()
+
+	anterior := Get()
+	recarga := Recarga{Anterior: anterior, Atual: anterior}
+
+	lida, err := carrega(path)
+	if err != nil {
+		recarga.Erro = err
+		return recarga
+	}
+	if err := lida.Valida(servico); err != nil {
+		recarga.Erro = err
+		return recarga
+	}
+
+	aplicada := preservaNaoRecarregaveis(anterior, lida)
+	recarga.Aplicadas = diferencas(anterior, aplicada)
+	recarga.Ignoradas = diferencas(aplicada, lida)
+
+	if len(recarga.Aplicadas) > 0 {
+		config.Store(aplicada)
+		recarga.Atual = aplicada
+	}
+
+	return recarga
+}
+
+// preservaNaoRecarregaveis mantém da configuração anterior tudo o que exige reinício: identidade e
+// portas dos servidores, collector, arquivos do histórico, dos alertas e dos grupos, a concorrência dos
+// grupos, endereços dos upstreams e caminhos dos certificados. O conteúdo dos certificados é relido por conta própria quando os arquivos
+// mudam.
+func preservaNaoRecarregaveis(anterior, lida *configApp) *configApp {
+	aplicada := *lida
+
+	aplicada.AmbientePublicacao = anterior.AmbientePublicacao
+	aplicada.ServidorA = anterior.ServidorA
+	aplicada.ServidorB = anterior.ServidorB
+	aplicada.Telemetria.CollectorEndpoint = anterior.Telemetria.CollectorEndpoint
+	aplicada.Telemetria.TLS = anterior.Telemetria.TLS
+	aplicada.Historico = anterior.Historico
+	aplicada.Alertas.Arquivo = anterior.Alertas.Arquivo
+	aplicada.Grupos.Arquivo = anterior.Grupos.Arquivo
+	aplicada.Grupos.Concorrencia = anterior.Grupos.Concorrencia
+
+	for _, upstream := range []struct{ aplicado, anterior *UpstreamConfig }{
+		{&aplicada.ViaCep, &anterior.ViaCep},
+		{&aplicada.WeatherApi, &anterior.WeatherApi},
+		{&aplicada.OpenMeteo, &anterior.OpenMeteo},
+		{&aplicada.OpenMeteoGeocoding, &anterior.OpenMeteoGeocoding},
+		{&aplicada.ServicoB, &anterior.ServicoB},
+	} {
+		upstream.aplicado.BaseURL = upstream.anterior.BaseURL
+		upstream.aplicado.Proxy = upstream.anterior.Proxy
+		upstream.aplicado.TLS = upstream.anterior.TLS
+		upstream.aplicado.H2C = upstream.anterior.H2C
+	}
+
+	return &aplicada
+}
+
+func diferencas(anterior, atual *configApp) []Mudanca {
+	valoresAnteriores := map[string]string{}
+	valoresAtuais := map[string]string{}
+	achata("", reflect.ValueOf(anterior.Redigida()), valoresAnteriores)
+	achata("", reflect.ValueOf(atual.Redigida()), valoresAtuais)
+
+	mudancas := []Mudanca{}
+	for chave, valor := range valoresAtuais {
+		if valoresAnteriores[chave] != valor {
+			mudancas = append(mudancas, Mudanca{Chave: chave, Anterior: valoresAnteriores[chave], Atual: valor})
+		}
+	}
+
+	// Segredos redigidos não aparecem como diferença pelo valor; a troca é sinalizada mesmo assim.
+	for chave, valores := range map[string][2]string{
+		"weather_api_key":     {anterior.WeatherApiKey, atual.WeatherApiKey},
+		"autenticacao.chaves": {anterior.Autenticacao.Chaves, atual.Autenticacao.Chaves},
+	} {
+		if valores[0] != valores[1] && valoresAnteriores[chave] == valoresAtuais[chave] {
+			mudancas = append(mudancas, Mudanca{Chave: chave, Anterior: valoresAnteriores[chave], Atual: valoresAtuais[chave]})
+		}
+	}
+
+	sort.Slice(mudancas, func(i, j int) bool { return mudancas[i].Chave < mudancas[j].Chave })
+	return mudancas
+}
+
+// achata percorre a configuração pelas tags mapstructure, gerando chaves como servico_b.timeout.
+func achata(prefixo string, valor reflect.Value, saida map[string]string) {
+	if valor.Kind() != reflect.Struct || valor.Type().PkgPath() != reflect.TypeOf(configApp{}).PkgPath() {
+		saida[prefixo] = fmt.Sprint(valor.Interface())
+		return
+	}
+
+	for i := 0; i < valor.NumField(); i++ {
+		if !valor.Type().Field(i).IsExported() {
+			continue
+		}
+
+		chave := valor.Type().Field(i).Tag.Get("mapstructure")
+		if prefixo != "" {
+			chave = prefixo + "." + chave
+		}
+		achata(chave, valor.Field(i), saida)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"path/filepath"
+	"time"
+)
+
+func (s *ConfigTestSuite) TestRecarregaAplicaSomenteChavesSeguras() {
+	// Arrange
+	dir := s.T().TempDir()
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.escreve(dir, "config.yaml", `
+servidor_a:
+  porta: 3000
+servico_b:
+  timeout: 10s
+`)
+	s.Require().NoError(LoadConfig(dir))
+	s.escreve(dir, "config.yaml", `
+servidor_a:
+  porta: 4000
+log:
+  nivel: debug
+servico_b:
+  base_url: http://outro-service-b:3001/
+  timeout: 2s
+`)
+
+	// Act
+	recarga := Recarrega(dir, ServicoA)
+
+	// Assert
+	s.Require().NoError(recarga.Erro)
+	s.Equal([]Mudanca{
+		{Chave: "log.nivel", Anterior: "info", Atual: "debug"},
+		{Chave: "servico_b.timeout", Anterior: "10s", Atual: "2s"},
+	}, recarga.Aplicadas)
+	s.Equal([]Mudanca{
+		{Chave: "servico_b.base_url", Anterior: "http://service-b:3001/", Atual: "http://outro-service-b:3001/"},
+		{Chave: "servidor_a.porta", Anterior: "3000", Atual: "4000"},
+	}, recarga.Ignoradas)
+	s.Equal(2*time.Second, Get().GetServicoB().Timeout)
+	s.Equal("http://service-b:3001/", Get().GetServicoB().BaseURL)
+	s.Equal(3000, Get().GetServidorA().Porta)
+}
+
+func (s *ConfigTestSuite) TestRecarregaRejeitaConfiguracaoInvalida() {
+	// Arrange
+	dir := s.T().TempDir()
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.escreve(dir, "config.yaml", "telemetria:\n  amostragem: 0.5\n")
+	s.Require().NoError(LoadConfig(dir))
+	anterior := Get()
+	s.escreve(dir, "config.yaml", "telemetria:\n  amostragem: 2\nservico_b:\n  timeout: 1s\n")
+
+	// Act
+	recarga := Recarrega(dir, ServicoA)
+
+	// Assert
+	s.ErrorContains(recarga.Erro, "TELEMETRIA_AMOSTRAGEM")
+	s.Empty(recarga.Aplicadas)
+	s.Same(anterior, Get())
+	s.Equal(0.5, Get().GetTelemetria().Amostragem)
+}
+
+func (s *ConfigTestSuite) TestObservaRecarregaQuandoArquivoMuda() {
+	// Arrange
+	dir := s.T().TempDir()
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.escreve(dir, "config.yaml", "clima:\n  provedor: weatherapi\n")
+	s.Require().NoError(LoadConfig(dir))
+	recargas := make(chan Recarga, 10)
+	s.Require().Len(Observa(dir, ServicoA, func(r Recarga) { recargas <- r }), 1)
+
+	// Act
+	s.escreve(dir, "config.yaml", "clima:\n  provedor: open-meteo\n")
+
+	// Assert
+	select {
+	case recarga := <-recargas:
+		s.Require().NoError(recarga.Erro)
+		s.Equal([]Mudanca{{Chave: "clima.provedor", Anterior: "weatherapi", Atual: "open-meteo"}}, recarga.Aplicadas)
+		s.Equal(ProvedorOpenMeteo, Get().GetClima().Provedor)
+	case <-time.After(5 * time.Second):
+		s.Fail("a alteração do arquivo não foi recarregada")
+	}
+}
+
+func (s *ConfigTestSuite) TestRecarregaTrocaChavesDoArquivoDeSegredo() {
+	// Arrange
+	dir := s.T().TempDir()
+	segredo := filepath.Join(dir, "weather_api_key")
+	s.escreve(dir, "weather_api_key", "chave-antiga")
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.T().Setenv("WEATHER_API_KEY", "")
+	s.T().Setenv("WEATHER_API_KEY_FILE", segredo)
+	s.Require().NoError(LoadConfig(dir))
+	s.escreve(dir, "weather_api_key", "chave-nova,chave-reserva")
+
+	// Act
+	recarga := Recarrega(dir, ServicoB)
+
+	// Assert
+	s.Require().NoError(recarga.Erro)
+	s.Equal([]Mudanca{{Chave: "weather_api_key", Anterior: valorRedigido, Atual: valorRedigido}}, recarga.Aplicadas)
+	s.Equal([]string{"chave-nova", "chave-reserva"}, Get().GetWeatherApiKeys())
+}
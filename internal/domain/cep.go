@@ -1,22 +1,90 @@
 package domain
 
 import (
+	"sort"
+	"strconv"
+
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
 )
 
+// ClassificacaoCep é o tipo de destino do CEP, dado pelo sufixo (os três últimos dígitos).
+type ClassificacaoCep string
+
+const (
+	CepLogradouro ClassificacaoCep = "logradouro"
+	// CepGeralMunicipio termina em -000. Nas cidades com CEP por logradouro, um -000 também pode ser de um
+	// logradouro, como 01001-000 na Praça da Sé.
+	CepGeralMunicipio         ClassificacaoCep = "geral de município"
+	CepGrandeUsuario          ClassificacaoCep = "grande usuário"
+	CepCaixaPostalComunitaria ClassificacaoCep = "caixa postal comunitária"
+	// CepUnidadeCorreios reúne os sufixos das unidades operacionais e dos CEPs promocionais dos Correios.
+	CepUnidadeCorreios ClassificacaoCep = "unidade dos correios"
+)
+
+// faixaCep é uma faixa de CEPs de uma UF, pelos cinco primeiros dígitos (o radical).
+type faixaCep struct {
+	inicio, fim int
+	uf          string
+}
+
+// faixasCep são as faixas oficiais dos Correios, em ordem. Os radicais fora delas, como 00000 a 00999 e
+// 78900 a 78999, não são de nenhuma UF.
+var faixasCep = []faixaCep{
+	{1000, 19999, "SP"},
+	{20000, 28999, "RJ"},
+	{29000, 29999, "ES"},
+	{30000, 39999, "MG"},
+	{40000, 48999, "BA"},
+	{49000, 49999, "SE"},
+	{50000, 56999, "PE"},
+	{57000, 57999, "AL"},
+	{58000, 58999, "PB"},
+	{59000, 59999, "RN"},
+	{60000, 63999, "CE"},
+	{64000, 64999, "PI"},
+	{65000, 65999, "MA"},
+	{66000, 68899, "PA"},
+	{68900, 68999, "AP"},
+	{69000, 69299, "AM"},
+	{69300, 69399, "RR"},
+	{69400, 69899, "AM"},
+	{69900, 69999, "AC"},
+	{70000, 72799, "DF"},
+	{72800, 72999, "GO"},
+	{73000, 73699, "DF"},
+	{73700, 76799, "GO"},
+	{76800, 76999, "RO"},
+	{77000, 77999, "TO"},
+	{78000, 78899, "MT"},
+	{79000, 79999, "MS"},
+	{80000, 87999, "PR"},
+	{88000, 89999, "SC"},
+	{90000, 99999, "RS"},
+}
+
 type Cep struct {
 	codigo string
+	uf     string
 }
 
+// NewCep aceita o CEP com oito dígitos, como 05791-120 ou 05.791-120, e exige que ele esteja na faixa de
+// alguma UF.
 func NewCep(codigo string) (*Cep, error) {
+	if !helpers.ValidateZipCode(codigo) {
+		return nil, erros.ErrInvalidZipCode
+	}
+
 	cep := &Cep{
 		codigo: helpers.NormalizeZipCode(codigo),
 	}
 
-	if !helpers.ValidateZipCode(cep.codigo) {
+	radical, _ := strconv.Atoi(cep.codigo[:5])
+	i := sort.Search(len(faixasCep), func(i int) bool { return faixasCep[i].fim >= radical })
+	if i == len(faixasCep) || faixasCep[i].inicio > radical {
 		return nil, erros.ErrInvalidZipCode
 	}
+	cep.uf = faixasCep[i].uf
 
 	return cep, nil
 }
@@ -24,3 +92,33 @@ func NewCep(codigo string) (*Cep, error) {
 func (c *Cep) Codigo() string {
 	return c.codigo
 }
+
+// Formatado devolve o CEP com hífen, como 05791-120.
+func (c *Cep) Formatado() string {
+	return c.codigo[:5] + "-" + c.codigo[5:]
+}
+
+func (c *Cep) UF() string {
+	return c.uf
+}
+
+// Regiao devolve a região geográfica da UF do CEP, como Sudeste.
+func (c *Cep) Regiao() string {
+	return estados[c.uf].regiao
+}
+
+func (c *Cep) Classificacao() ClassificacaoCep {
+	sufixo, _ := strconv.Atoi(c.codigo[5:])
+	switch {
+	case sufixo == 0:
+		return CepGeralMunicipio
+	case sufixo <= 899:
+		return CepLogradouro
+	case sufixo <= 959:
+		return CepGrandeUsuario
+	case sufixo >= 970 && sufixo <= 989, sufixo == 999:
+		return CepCaixaPostalComunitaria
+	default:
+		return CepUnidadeCorreios
+	}
+}
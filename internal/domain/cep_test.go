@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type CepTestSuite struct {
+	suite.Suite
+}
+
+func TestCepSuite(t *testing.T) {
+	suite.Run(t, new(CepTestSuite))
+}
+
+func (s *CepTestSuite) TestNewCepComFaixasDasUfs() {
+	cenarios := []struct {
+		codigo string
+		uf     string
+		regiao string
+	}{
+		{"01001-000", "SP", "Sudeste"},
+		{"20040002", "RJ", "Sudeste"},
+		{"69301-000", "RR", "Norte"},
+		{"69400000", "AM", "Norte"},
+		{"73000-000", "DF", "Centro-Oeste"},
+		{"73700000", "GO", "Centro-Oeste"},
+		{"40.020-000", "BA", "Nordeste"},
+		{"99999999", "RS", "Sul"},
+	}
+
+	for _, cenario := range cenarios {
+		// Act
+		cep, err := NewCep(cenario.codigo)
+
+		// Assert
+		s.Require().NoError(err, cenario.codigo)
+		s.Equal(cenario.uf, cep.UF(), cenario.codigo)
+		s.Equal(cenario.regiao, cep.Regiao(), cenario.codigo)
+	}
+}
+
+func (s *CepTestSuite) TestNewCepRecusaFaixasInexistentesEFormatosAmbiguos() {
+	for _, codigo := range []string{"00000000", "00999-999", "78900000", "abc0579-1120xyz", "0579-1120", "05791 120"} {
+		// Act
+		_, err := NewCep(codigo)
+
+		// Assert
+		s.ErrorIs(err, erros.ErrInvalidZipCode, codigo)
+	}
+}
+
+func (s *CepTestSuite) TestFormatado() {
+	// Arrange
+	cep, _ := NewCep("05791120")
+
+	// Act
+	formatado := cep.Formatado()
+
+	// Assert
+	s.Equal("05791-120", formatado)
+	s.Equal("05791120", cep.Codigo())
+}
+
+func (s *CepTestSuite) TestClassificacao() {
+	cenarios := map[string]ClassificacaoCep{
+		"13290-000": CepGeralMunicipio,
+		"05791-120": CepLogradouro,
+		"01311-920": CepGrandeUsuario,
+		"13010-972": CepCaixaPostalComunitaria,
+		"13010-999": CepCaixaPostalComunitaria,
+		"01032-960": CepUnidadeCorreios,
+		"01032-995": CepUnidadeCorreios,
+	}
+
+	for codigo, esperada := range cenarios {
+		// Arrange
+		cep, err := NewCep(codigo)
+		s.Require().NoError(err)
+
+		// Act
+		classificacao := cep.Classificacao()
+
+		// Assert
+		s.Equal(esperada, classificacao, codigo)
+	}
+}
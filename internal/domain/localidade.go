@@ -1,19 +1,77 @@
 package domain
 
-import "github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// estado reúne o nome, a região geográfica e o código IBGE de uma UF.
+type estado struct {
+	nome   string
+	regiao string
+	ibge   string
+}
+
+var estados = map[string]estado{
+	"RO": {"Rondônia", "Norte", "11"}, "AC": {"Acre", "Norte", "12"}, "AM": {"Amazonas", "Norte", "13"},
+	"RR": {"Roraima", "Norte", "14"}, "PA": {"Pará", "Norte", "15"}, "AP": {"Amapá", "Norte", "16"},
+	"TO": {"Tocantins", "Norte", "17"},
+	"MA": {"Maranhão", "Nordeste", "21"}, "PI": {"Piauí", "Nordeste", "22"}, "CE": {"Ceará", "Nordeste", "23"},
+	"RN": {"Rio Grande do Norte", "Nordeste", "24"}, "PB": {"Paraíba", "Nordeste", "25"},
+	"PE": {"Pernambuco", "Nordeste", "26"}, "AL": {"Alagoas", "Nordeste", "27"}, "SE": {"Sergipe", "Nordeste", "28"},
+	"BA": {"Bahia", "Nordeste", "29"},
+	"MG": {"Minas Gerais", "Sudeste", "31"}, "ES": {"Espírito Santo", "Sudeste", "32"},
+	"RJ": {"Rio de Janeiro", "Sudeste", "33"}, "SP": {"São Paulo", "Sudeste", "35"},
+	"PR": {"Paraná", "Sul", "41"}, "SC": {"Santa Catarina", "Sul", "42"}, "RS": {"Rio Grande do Sul", "Sul", "43"},
+	"MS": {"Mato Grosso do Sul", "Centro-Oeste", "50"}, "MT": {"Mato Grosso", "Centro-Oeste", "51"},
+	"GO": {"Goiás", "Centro-Oeste", "52"}, "DF": {"Distrito Federal", "Centro-Oeste", "53"},
+}
+
+// PaisBrasil é o país das localidades, como a WeatherAPI o escreve.
+const PaisBrasil = "Brazil"
+
+// nomesPais são as grafias do país aceitas nas respostas dos provedores, que respondem no idioma da consulta.
+var nomesPais = map[string]bool{"brazil": true, "brasil": true}
+
+// outrosNomesEstados são as grafias em inglês e espanhol dos estados cujo nome não coincide com o português
+// sem acentos.
+var outrosNomesEstados = map[string]string{
+	"federal district":     "distrito federal",
+	"rio grande del norte": "rio grande do norte",
+	"rio grande del sur":   "rio grande do sul",
+	"mato grosso del sur":  "mato grosso do sul",
+}
+
+// conectivos ficam em minúsculas no meio dos nomes, como em "Santana de Parnaíba".
+var conectivos = map[string]bool{"da": true, "das": true, "de": true, "do": true, "dos": true, "e": true}
 
 type Localidade struct {
 	name string
+	uf   string
+	ibge string
+	pais string
 }
 
-func NewLocalidade(name string) (*Localidade, error) {
+// NewLocalidade exige o nome da cidade e uma UF válida, que pode vir em minúsculas.
+func NewLocalidade(name, uf string) (*Localidade, error) {
 	localidade := &Localidade{
-		name: name,
+		name: strings.Join(strings.Fields(name), " "),
+		uf:   strings.ToUpper(strings.TrimSpace(uf)),
+		pais: PaisBrasil,
 	}
 
-	if !localidade.valida() {
+	if len(localidade.name) == 0 {
 		return nil, erros.ErrCityIsRequired
 	}
+	if _, ok := estados[localidade.uf]; !ok {
+		return nil, erros.ErrInvalidState
+	}
 
 	return localidade, nil
 }
@@ -22,6 +80,89 @@ func (l *Localidade) Name() string {
 	return l.name
 }
 
-func (l Localidade) valida() bool {
-	return len(l.name) > 0
+func (l *Localidade) Uf() string {
+	return l.uf
+}
+
+// Estado devolve o nome da UF, como Paraíba.
+func (l *Localidade) Estado() string {
+	return estados[l.uf].nome
+}
+
+// Ibge devolve o código IBGE do município, vazio quando não informado.
+func (l *Localidade) Ibge() string {
+	return l.ibge
+}
+
+func (l *Localidade) Pais() string {
+	return l.pais
+}
+
+// ComIbge devolve uma cópia com o código IBGE do município, que tem sete dígitos e começa pelo código da UF.
+func (l *Localidade) ComIbge(codigo string) (*Localidade, error) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) != 7 || strings.Trim(codigo, "0123456789") != "" || !strings.HasPrefix(codigo, estados[l.uf].ibge) {
+		return nil, fmt.Errorf("%w: %q em %s", erros.ErrInvalidIbgeCode, codigo, l.uf)
+	}
+
+	copia := *l
+	copia.ibge = codigo
+	return &copia, nil
+}
+
+// Corresponde verifica se a região e o país de um provedor de clima são os da localidade, sem diferenciar
+// acentos e maiúsculas. Um campo vazio não é verificado, já que nem todo provedor o informa.
+func (l *Localidade) Corresponde(regiao, pais string) bool {
+	if regiao != "" && ChaveEstado(regiao) != ChaveEstado(l.Estado()) {
+		return false
+	}
+	if pais != "" && !nomesPais[ChaveCidade(pais)] {
+		return false
+	}
+	return true
+}
+
+// NormalizaNomeCidade coloca cada palavra com a inicial maiúscula, mantendo os acentos e os conectivos em
+// minúsculas: "SANTANA DE PARNAÍBA" vira "Santana de Parnaíba" e "santa bárbara d'oeste", "Santa Bárbara
+// d'Oeste".
+func NormalizaNomeCidade(nome string) string {
+	palavras := strings.Fields(strings.ToLower(nome))
+	for i, palavra := range palavras {
+		if i > 0 && conectivos[palavra] {
+			continue
+		}
+		if prefixo, resto, ok := strings.Cut(palavra, "'"); ok {
+			palavras[i] = prefixo + "'" + capitaliza(resto)
+			continue
+		}
+		palavras[i] = capitaliza(palavra)
+	}
+	return strings.Join(palavras, " ")
+}
+
+// ChaveCidade remove acentos, caixa e espaços repetidos para comparar nomes de cidades: "São  Paulo" e
+// "sao paulo" têm a mesma chave.
+func ChaveCidade(nome string) string {
+	semAcentos, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), nome)
+	if err != nil {
+		semAcentos = nome
+	}
+	return strings.Join(strings.Fields(strings.ToLower(semAcentos)), " ")
+}
+
+// ChaveEstado é a ChaveCidade do nome do estado em português, também para as grafias em inglês e espanhol:
+// "Federal District" e "Distrito Federal" têm a mesma chave.
+func ChaveEstado(nome string) string {
+	chave := ChaveCidade(nome)
+	if portugues, ok := outrosNomesEstados[chave]; ok {
+		return portugues
+	}
+	return chave
+}
+
+func capitaliza(palavra string) string {
+	for i, r := range palavra {
+		return string(unicode.ToUpper(r)) + palavra[i+len(string(r)):]
+	}
+	return palavra
 }
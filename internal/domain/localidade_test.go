@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalidadeTestSuite struct {
+	suite.Suite
+}
+
+func TestLocalidadeSuite(t *testing.T) {
+	suite.Run(t, new(LocalidadeTestSuite))
+}
+
+func (s *LocalidadeTestSuite) TestNewLocalidade() {
+	// Act
+	localidade, err := NewLocalidade("  Santa   Rita ", "pb")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("Santa Rita", localidade.Name())
+	s.Equal("PB", localidade.Uf())
+	s.Equal("Paraíba", localidade.Estado())
+	s.Equal(PaisBrasil, localidade.Pais())
+	s.Empty(localidade.Ibge())
+}
+
+func (s *LocalidadeTestSuite) TestNewLocalidadeRecusaUfInexistente() {
+	// Act
+	_, err := NewLocalidade("Santa Rita", "XX")
+
+	// Assert
+	s.ErrorIs(err, erros.ErrInvalidState)
+}
+
+func (s *LocalidadeTestSuite) TestComIbge() {
+	// Arrange
+	localidade, _ := NewLocalidade("Santa Rita", "PB")
+
+	// Act
+	comIbge, err := localidade.ComIbge("2513703")
+	_, errOutraUf := localidade.ComIbge("3550308")
+	_, errFormato := localidade.ComIbge("25137")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("2513703", comIbge.Ibge())
+	s.Empty(localidade.Ibge())
+	s.ErrorIs(errOutraUf, erros.ErrInvalidIbgeCode)
+	s.ErrorIs(errFormato, erros.ErrInvalidIbgeCode)
+}
+
+func (s *LocalidadeTestSuite) TestCorresponde() {
+	// Arrange
+	localidade, _ := NewLocalidade("Santa Rita", "PB")
+
+	// Act & Assert
+	s.True(localidade.Corresponde("Paraiba", "Brazil"))
+	s.True(localidade.Corresponde("PARAÍBA", "Brasil"))
+	s.True(localidade.Corresponde("", ""))
+	s.False(localidade.Corresponde("Maranhao", "Brazil"))
+	s.False(localidade.Corresponde("Paraiba", "Portugal"))
+}
+
+func (s *LocalidadeTestSuite) TestCorrespondeAoEstadoEmOutroIdioma() {
+	// Arrange
+	brasilia, _ := NewLocalidade("Brasília", "DF")
+	portoAlegre, _ := NewLocalidade("Porto Alegre", "RS")
+
+	// Act & Assert
+	s.True(brasilia.Corresponde("Federal District", "Brazil"))
+	s.True(portoAlegre.Corresponde("Río Grande del Sur", "Brasil"))
+	s.False(portoAlegre.Corresponde("Rio Grande del Norte", "Brasil"))
+}
+
+func (s *LocalidadeTestSuite) TestNormalizaNomeCidade() {
+	s.Equal("Santana de Parnaíba", NormalizaNomeCidade("SANTANA DE PARNAÍBA"))
+	s.Equal("Santa Bárbara d'Oeste", NormalizaNomeCidade("santa  bárbara d'oeste"))
+	s.Equal("sao paulo", ChaveCidade(" São  Paulo "))
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
+)
+
+type Unidade string
+
+const (
+	Celsius    Unidade = "C"
+	Fahrenheit Unidade = "F"
+	Kelvin     Unidade = "K"
+	Rankine    Unidade = "R"
+)
+
+// UnidadesPadrao são as unidades retornadas quando o cliente não seleciona nenhuma.
+var UnidadesPadrao = []Unidade{Celsius, Fahrenheit, Kelvin}
+
+func (u Unidade) Nome() string {
+	switch u {
+	case Celsius:
+		return "celsius"
+	case Fahrenheit:
+		return "fahrenheit"
+	case Kelvin:
+		return "kelvin"
+	case Rankine:
+		return "rankine"
+	}
+	return ""
+}
+
+func (u Unidade) Simbolo() string {
+	switch u {
+	case Celsius:
+		return "°C"
+	case Fahrenheit:
+		return "°F"
+	case Kelvin:
+		return "K"
+	case Rankine:
+		return "°R"
+	}
+	return ""
+}
+
+// ParseUnidades interpreta uma lista separada por vírgulas, como "C,F,K,R".
+func ParseUnidades(valor string) ([]Unidade, error) {
+	unidades := make([]Unidade, 0, 4)
+	for _, item := range strings.Split(valor, ",") {
+		unidade := Unidade(strings.ToUpper(strings.TrimSpace(item)))
+		if unidade.Nome() == "" {
+			return nil, fmt.Errorf("%w: %q", erros.ErrInvalidTemperatureUnit, item)
+		}
+		if !contemUnidade(unidades, unidade) {
+			unidades = append(unidades, unidade)
+		}
+	}
+	return unidades, nil
+}
+
+func contemUnidade(unidades []Unidade, unidade Unidade) bool {
+	for _, u := range unidades {
+		if u == unidade {
+			return true
+		}
+	}
+	return false
+}
+
+type ModoArredondamento string
+
+const (
+	SemArredondamento      ModoArredondamento = "none"
+	ArredondamentoHalfEven ModoArredondamento = "half-even"
+	// ArredondamentoCeil reproduz o comportamento legado: Fahrenheit, Kelvin e Rankine são
+	// arredondados para cima e Celsius, que o legado não arredondava, segue half-even.
+	ArredondamentoCeil ModoArredondamento = "ceil"
+)
+
+func ParseModoArredondamento(valor string) (ModoArredondamento, error) {
+	modo := ModoArredondamento(strings.ToLower(strings.TrimSpace(valor)))
+	switch modo {
+	case SemArredondamento, ArredondamentoHalfEven, ArredondamentoCeil:
+		return modo, nil
+	}
+	return "", fmt.Errorf("%w: %q", erros.ErrInvalidRoundingMode, valor)
+}
+
+// PrecisaoMaxima limita as casas decimais aceitas.
+const PrecisaoMaxima = 6
+
+func ParsePrecisao(valor string) (int, error) {
+	precisao, err := strconv.Atoi(strings.TrimSpace(valor))
+	if err != nil || precisao < 0 || precisao > PrecisaoMaxima {
+		return 0, fmt.Errorf("%w: %q", erros.ErrInvalidPrecision, valor)
+	}
+	return precisao, nil
+}
+
+func (m ModoArredondamento) Arredonda(valor float64, precisao int) float64 {
+	fator := math.Pow10(precisao)
+	switch m {
+	case ArredondamentoHalfEven:
+		return math.RoundToEven(valor*fator) / fator
+	case ArredondamentoCeil:
+		return math.Ceil(valor*fator) / fator
+	}
+	return valor
+}
+
+// para devolve o modo aplicado à unidade; no ceil legado, Celsius não é arredondado para cima.
+func (m ModoArredondamento) para(unidade Unidade) ModoArredondamento {
+	if m == ArredondamentoCeil && unidade == Celsius {
+		return ArredondamentoHalfEven
+	}
+	return m
+}
+
+func (m ModoArredondamento) Formata(valor float64, precisao int) string {
+	if m == SemArredondamento {
+		return strconv.FormatFloat(valor, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(m.Arredonda(valor, precisao), 'f', precisao, 64)
+}
+
+// OpcoesTemperatura define quais unidades retornar e como arredondar os valores.
+// Campos não informados são preenchidos com ComPadrao.
+type OpcoesTemperatura struct {
+	Unidades       []Unidade
+	Arredondamento ModoArredondamento
+	Precisao       *int
+}
+
+var precisaoPadrao = 1
+
+// OpcoesPadrao mantém o formato original da resposta: C, F e K com uma casa decimal.
+var OpcoesPadrao = OpcoesTemperatura{
+	Unidades:       UnidadesPadrao,
+	Arredondamento: ArredondamentoHalfEven,
+	Precisao:       &precisaoPadrao,
+}
+
+func (o OpcoesTemperatura) ComPadrao(padrao OpcoesTemperatura) OpcoesTemperatura {
+	if len(o.Unidades) == 0 {
+		o.Unidades = padrao.Unidades
+	}
+	if o.Arredondamento == "" {
+		o.Arredondamento = padrao.Arredondamento
+	}
+	if o.Precisao == nil {
+		o.Precisao = padrao.Precisao
+	}
+	return o
+}
+
+func (o OpcoesTemperatura) Seleciona(unidade Unidade) bool {
+	return contemUnidade(o.Unidades, unidade)
+}
+
+// Query retorna apenas as opções informadas, para repassá-las a outro serviço.
+func (o OpcoesTemperatura) Query() url.Values {
+	query := url.Values{}
+	if len(o.Unidades) > 0 {
+		unidades := make([]string, 0, len(o.Unidades))
+		for _, unidade := range o.Unidades {
+			unidades = append(unidades, string(unidade))
+		}
+		query.Set("unidades", strings.Join(unidades, ","))
+	}
+	if o.Arredondamento != "" {
+		query.Set("arredondamento", string(o.Arredondamento))
+	}
+	if o.Precisao != nil {
+		query.Set("precisao", strconv.Itoa(*o.Precisao))
+	}
+	return query
+}
+
+type Temperatura struct {
+	celsius float64
+}
+
+func NewTemperatura(celsius float64) Temperatura {
+	return Temperatura{celsius: celsius}
+}
+
+func (t Temperatura) Celsius() float64 {
+	return t.celsius
+}
+
+func (t Temperatura) Em(unidade Unidade) float64 {
+	switch unidade {
+	case Fahrenheit:
+		return helpers.CelsiusToFahrenheit(t.celsius)
+	case Kelvin:
+		return helpers.CelsiusToKelvin(t.celsius)
+	case Rankine:
+		return helpers.CelsiusToRankine(t.celsius)
+	}
+	return t.celsius
+}
+
+// Valor converte para a unidade e aplica o arredondamento das opções.
+func (t Temperatura) Valor(unidade Unidade, opcoes OpcoesTemperatura) float64 {
+	return opcoes.Arredondamento.para(unidade).Arredonda(t.Em(unidade), *opcoes.Precisao)
+}
+
+func (t Temperatura) Formata(unidade Unidade, opcoes OpcoesTemperatura) string {
+	return opcoes.Arredondamento.para(unidade).Formata(t.Em(unidade), *opcoes.Precisao)
+}
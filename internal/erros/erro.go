@@ -0,0 +1,176 @@
+package erros
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+var ErrUpstreamUnavailable = errors.New("upstream service unavailable")
+var ErrUpstreamTimeout = errors.New("upstream service timeout")
+var ErrInvalidUpstreamResponse = errors.New("invalid upstream response")
+
+// Tipo classifica um erro pelo que o cliente pode fazer com ele. Também é um error, para que
+// errors.Is(err, TipoTimeout) encontre um Erro desse tipo.
+type Tipo string
+
+const (
+	TipoValidacao            Tipo = "validation"
+	TipoNaoEncontrado        Tipo = "not_found"
+	TipoUpstreamIndisponivel Tipo = "upstream_unavailable"
+	TipoUpstreamCota         Tipo = "upstream_quota"
+	TipoTimeout              Tipo = "timeout"
+	TipoInterno              Tipo = "internal"
+)
+
+func (t Tipo) Error() string {
+	return string(t)
+}
+
+// Retentavel indica se vale repetir a requisição: só as falhas passageiras dos upstreams e os timeouts.
+func (t Tipo) Retentavel() bool {
+	return t == TipoUpstreamIndisponivel || t == TipoTimeout
+}
+
+// Erro acrescenta à causa o tipo, o upstream que falhou e se vale tentar de novo. A mensagem é a da causa,
+// e errors.Is e errors.As alcançam a causa: errors.Is(err, ErrCityNotFound) continua valendo.
+type Erro struct {
+	Tipo       Tipo
+	Upstream   string
+	Retentavel bool
+	Causa      error
+}
+
+func NewErro(tipo Tipo, causa error) *Erro {
+	return &Erro{Tipo: tipo, Retentavel: tipo.Retentavel(), Causa: causa}
+}
+
+// NewErroUpstream identifica o upstream pelo nome da configuração, como config.UpstreamViaCep.
+func NewErroUpstream(upstream string, tipo Tipo, causa error) *Erro {
+	erro := NewErro(tipo, causa)
+	erro.Upstream = upstream
+	return erro
+}
+
+func (e *Erro) Error() string {
+	return e.Causa.Error()
+}
+
+func (e *Erro) Unwrap() error {
+	return e.Causa
+}
+
+func (e *Erro) Is(alvo error) bool {
+	tipo, ok := alvo.(Tipo)
+	return ok && tipo == e.Tipo
+}
+
+// tratamentos traz o status HTTP, o código gRPC e a mensagem pública de cada tipo. Sem mensagem pública, o
+// cliente recebe o próprio erro; com ela, os detalhes ficam só no span e no log.
+var tratamentos = map[Tipo]struct {
+	status  int
+	grpc    codes.Code
+	publico error
+}{
+	TipoValidacao:            {http.StatusBadRequest, codes.InvalidArgument, nil},
+	TipoNaoEncontrado:        {http.StatusNotFound, codes.NotFound, nil},
+	TipoUpstreamIndisponivel: {http.StatusBadGateway, codes.Unavailable, ErrUpstreamUnavailable},
+	TipoUpstreamCota:         {http.StatusServiceUnavailable, codes.ResourceExhausted, nil},
+	TipoTimeout:              {http.StatusGatewayTimeout, codes.DeadlineExceeded, ErrUpstreamTimeout},
+	TipoInterno:              {http.StatusInternalServerError, codes.Internal, ErrInternal},
+}
+
+// conhecidos liga cada erro conhecido ao seu tipo. O status só é informado quando difere do padrão do tipo,
+// como o 422 dos CEPs e localidades mal formados e o 406 dos formatos de resposta não suportados.
+var conhecidos = []struct {
+	erro   error
+	tipo   Tipo
+	status int
+}{
+	{ErrInvalidZipCode, TipoValidacao, http.StatusUnprocessableEntity},
+	{ErrCityIsRequired, TipoValidacao, http.StatusUnprocessableEntity},
+	{ErrInvalidState, TipoValidacao, http.StatusUnprocessableEntity},
+	{ErrInvalidIbgeCode, TipoValidacao, http.StatusUnprocessableEntity},
+	{ErrNotAcceptable, TipoValidacao, http.StatusNotAcceptable},
+	{ErrInvalidRequestBody, TipoValidacao, 0},
+	{ErrInvalidAddressSearch, TipoValidacao, 0},
+	{ErrInvalidTemperatureUnit, TipoValidacao, 0},
+	{ErrInvalidPrecision, TipoValidacao, 0},
+	{ErrInvalidRoundingMode, TipoValidacao, 0},
+	{ErrInvalidTemperatureOptions, TipoValidacao, 0},
+	{ErrInvalidInclude, TipoValidacao, 0},
+	{ErrInvalidHistoryFilter, TipoValidacao, 0},
+	{ErrInvalidSubscription, TipoValidacao, 0},
+	{ErrInvalidGroup, TipoValidacao, 0},
+	{ErrZipCodeNotFound, TipoNaoEncontrado, 0},
+	{ErrCityNotFound, TipoNaoEncontrado, 0},
+	{ErrLocationMismatch, TipoNaoEncontrado, 0},
+	{ErrSubscriptionNotFound, TipoNaoEncontrado, 0},
+	{ErrGroupNotFound, TipoNaoEncontrado, 0},
+	{ErrWeatherApiQuotaExceeded, TipoUpstreamCota, 0},
+	{ErrUpstreamUnavailable, TipoUpstreamIndisponivel, 0},
+	{ErrInvalidUpstreamResponse, TipoUpstreamIndisponivel, 0},
+	{ErrWebhookRejected, TipoUpstreamIndisponivel, 0},
+	{ErrUpstreamTimeout, TipoTimeout, 0},
+}
+
+// TipoDoStatus é o inverso de Classifica: devolve o tipo que responde com o status, para reconstruir o erro
+// de um serviço que já o classificou. O 422 dos erros conhecidos também é de validação.
+func TipoDoStatus(status int) (Tipo, bool) {
+	for tipo, tratamento := range tratamentos {
+		if tratamento.status == status {
+			return tipo, true
+		}
+	}
+	for _, conhecido := range conhecidos {
+		if conhecido.status == status {
+			return conhecido.tipo, true
+		}
+	}
+	return "", false
+}
+
+// Classificacao diz como responder a um erro.
+type Classificacao struct {
+	Tipo       Tipo
+	Upstream   string
+	Retentavel bool
+	Status     int
+	Grpc       codes.Code
+	// Publico é o erro escrito na resposta.
+	Publico error
+}
+
+// Classifica usa o tipo do Erro mais externo de err, ou o tipo do erro conhecido que ele envolve. Um erro
+// sem nenhum dos dois é interno.
+func Classifica(err error) Classificacao {
+	classificacao := Classificacao{Tipo: TipoInterno}
+	for _, conhecido := range conhecidos {
+		if errors.Is(err, conhecido.erro) {
+			classificacao.Tipo, classificacao.Status = conhecido.tipo, conhecido.status
+			break
+		}
+	}
+	classificacao.Retentavel = classificacao.Tipo.Retentavel()
+
+	var erro *Erro
+	if errors.As(err, &erro) {
+		if erro.Tipo != classificacao.Tipo {
+			classificacao.Tipo, classificacao.Status = erro.Tipo, 0
+		}
+		classificacao.Upstream, classificacao.Retentavel = erro.Upstream, erro.Retentavel
+	}
+
+	tratamento := tratamentos[classificacao.Tipo]
+	if classificacao.Status == 0 {
+		classificacao.Status = tratamento.status
+	}
+	classificacao.Grpc = tratamento.grpc
+	classificacao.Publico = err
+	if tratamento.publico != nil {
+		classificacao.Publico = tratamento.publico
+	}
+
+	return classificacao
+}
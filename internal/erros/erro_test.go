@@ -0,0 +1,115 @@
+package erros
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+)
+
+type ErroTestSuite struct {
+	suite.Suite
+}
+
+func TestErroSuite(t *testing.T) {
+	suite.Run(t, new(ErroTestSuite))
+}
+
+func (s *ErroTestSuite) TestClassificaErrosConhecidos() {
+	cenarios := []struct {
+		err    error
+		tipo   Tipo
+		status int
+		grpc   codes.Code
+	}{
+		{fmt.Errorf("%w: %q", ErrInvalidPrecision, "x"), TipoValidacao, http.StatusBadRequest, codes.InvalidArgument},
+		{ErrInvalidZipCode, TipoValidacao, http.StatusUnprocessableEntity, codes.InvalidArgument},
+		{fmt.Errorf("%w: yaml", ErrNotAcceptable), TipoValidacao, http.StatusNotAcceptable, codes.InvalidArgument},
+		{ErrCityNotFound, TipoNaoEncontrado, http.StatusNotFound, codes.NotFound},
+		{ErrWeatherApiQuotaExceeded, TipoUpstreamCota, http.StatusServiceUnavailable, codes.ResourceExhausted},
+		{ErrUpstreamTimeout, TipoTimeout, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{errors.New("falha qualquer"), TipoInterno, http.StatusInternalServerError, codes.Internal},
+	}
+
+	for _, cenario := range cenarios {
+		// Act
+		classificacao := Classifica(cenario.err)
+
+		// Assert
+		s.Equal(cenario.tipo, classificacao.Tipo, cenario.err.Error())
+		s.Equal(cenario.status, classificacao.Status, cenario.err.Error())
+		s.Equal(cenario.grpc, classificacao.Grpc, cenario.err.Error())
+	}
+}
+
+func (s *ErroTestSuite) TestTipoDoStatusInverteClassifica() {
+	for _, tipo := range []Tipo{TipoValidacao, TipoNaoEncontrado, TipoUpstreamIndisponivel, TipoUpstreamCota, TipoTimeout, TipoInterno} {
+		// Act
+		doStatus, ok := TipoDoStatus(Classifica(NewErro(tipo, errors.New("falha"))).Status)
+
+		// Assert
+		s.True(ok, string(tipo))
+		s.Equal(tipo, doStatus)
+	}
+	validacao, _ := TipoDoStatus(http.StatusUnprocessableEntity)
+	_, desconhecido := TipoDoStatus(http.StatusTeapot)
+	s.Equal(TipoValidacao, validacao)
+	s.False(desconhecido)
+}
+
+func (s *ErroTestSuite) TestClassificaUsaOTipoDoErro() {
+	// Arrange
+	causa := fmt.Errorf("%w: status 500", ErrUpstreamUnavailable)
+	err := fmt.Errorf("consultando clima: %w", NewErroUpstream("WEATHERAPI", TipoUpstreamIndisponivel, causa))
+
+	// Act
+	classificacao := Classifica(err)
+
+	// Assert
+	s.Equal(TipoUpstreamIndisponivel, classificacao.Tipo)
+	s.Equal(http.StatusBadGateway, classificacao.Status)
+	s.Equal("WEATHERAPI", classificacao.Upstream)
+	s.True(classificacao.Retentavel)
+	s.Equal(ErrUpstreamUnavailable, classificacao.Publico)
+}
+
+func (s *ErroTestSuite) TestTipoDoErroPrevaleceSobreOErroConhecido() {
+	// Arrange
+	err := NewErroUpstream("VIACEP", TipoNaoEncontrado, ErrUpstreamUnavailable)
+
+	// Act
+	classificacao := Classifica(err)
+
+	// Assert
+	s.Equal(TipoNaoEncontrado, classificacao.Tipo)
+	s.Equal(http.StatusNotFound, classificacao.Status)
+	s.False(classificacao.Retentavel)
+}
+
+func (s *ErroTestSuite) TestErroMantemCompatibilidadeComErrorsIsEAs() {
+	// Arrange
+	err := fmt.Errorf("buscando CEP: %w", NewErroUpstream("VIACEP", TipoNaoEncontrado, ErrZipCodeNotFound))
+
+	// Act
+	var erro *Erro
+	encontrado := errors.As(err, &erro)
+
+	// Assert
+	s.True(encontrado)
+	s.Equal("VIACEP", erro.Upstream)
+	s.ErrorIs(err, ErrZipCodeNotFound)
+	s.ErrorIs(err, TipoNaoEncontrado)
+	s.NotErrorIs(err, TipoInterno)
+	s.Equal("buscando CEP: can not find zipcode", err.Error())
+}
+
+func (s *ErroTestSuite) TestErroInternoNaoExpoeOsDetalhes() {
+	// Act
+	classificacao := Classifica(errors.New("senha do banco: 1234"))
+
+	// Assert
+	s.Equal(ErrInternal, classificacao.Publico)
+}
@@ -8,3 +8,26 @@ var ErrInvalidZipCode = errors.New("invalid zipcode")
 var ErrZipCodeNotFound = errors.New("can not find zipcode")
 var ErrCityIsRequired = errors.New("city is required")
 var ErrCityNotFound = errors.New("can not find city")
+var ErrInvalidState = errors.New("invalid state")
+var ErrInvalidAddressSearch = errors.New("invalid address search")
+var ErrInvalidIbgeCode = errors.New("invalid ibge code")
+var ErrLocationMismatch = errors.New("weather location does not match the city")
+var ErrInvalidTemperatureUnit = errors.New("invalid temperature unit")
+var ErrInvalidPrecision = errors.New("invalid precision")
+var ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+var ErrInvalidTemperatureOptions = errors.New("invalid temperature options")
+var ErrInvalidInclude = errors.New("invalid include option")
+var ErrWeatherApiQuotaExceeded = errors.New("weather api keys unavailable or quota exceeded")
+var ErrInvalidApiKey = errors.New("missing or invalid api key")
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+var ErrDailyQuotaExceeded = errors.New("daily quota exceeded")
+var ErrRequestTimeout = errors.New("request timeout")
+var ErrInvalidHistoryFilter = errors.New("invalid history filter")
+var ErrInvalidSubscription = errors.New("invalid subscription")
+var ErrSubscriptionNotFound = errors.New("can not find subscription")
+var ErrWebhookRejected = errors.New("webhook rejected by the receiver")
+var ErrInvalidGroup = errors.New("invalid location group")
+var ErrGroupNotFound = errors.New("can not find location group")
+var ErrInvalidRequestBody = errors.New("invalid request body")
+var ErrNotAcceptable = errors.New("response format not supported")
+var ErrInternal = errors.New("Internal Server Error")
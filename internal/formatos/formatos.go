@@ -0,0 +1,240 @@
+package formatos
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+)
+
+// ErrFormatoNaoSuportado é o erros.ErrNotAcceptable, respondido com 406.
+var ErrFormatoNaoSuportado = erros.ErrNotAcceptable
+
+// Encoder serializa a resposta de um handler em um formato específico.
+type Encoder interface {
+	Encode(w io.Writer, v any) error
+}
+
+// CSVMarshaler é implementado pelos tipos que podem ser exportados como CSV.
+// A primeira linha retornada é o cabeçalho.
+type CSVMarshaler interface {
+	MarshalCSV() ([][]string, error)
+}
+
+// ProtoMarshaler é implementado pelos tipos que possuem representação protobuf (api/temperaturas.proto).
+type ProtoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+type formato struct {
+	nome       string
+	mediaTypes []string
+	encoder    Encoder
+}
+
+type Registry struct {
+	formatos []formato
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewRegistryPadrao retorna o registro com JSON (padrão), XML, CSV e protobuf.
+func NewRegistryPadrao() *Registry {
+	return NewRegistry().
+		Registra("json", JSONEncoder{}, "application/json").
+		Registra("xml", XMLEncoder{}, "application/xml", "text/xml").
+		Registra("csv", CSVEncoder{}, "text/csv").
+		Registra("protobuf", ProtobufEncoder{}, "application/x-protobuf", "application/protobuf")
+}
+
+// Registra adiciona um formato ao registro. O primeiro formato registrado é usado quando o
+// cliente não informa preferência. O primeiro media type é o usado no Content-Type da resposta.
+func (r *Registry) Registra(nome string, encoder Encoder, mediaTypes ...string) *Registry {
+	r.formatos = append(r.formatos, formato{nome: nome, mediaTypes: mediaTypes, encoder: encoder})
+	return r
+}
+
+// Alias associa media types adicionais a um formato já registrado.
+func (r *Registry) Alias(nome string, mediaTypes ...string) *Registry {
+	for i := range r.formatos {
+		if r.formatos[i].nome == nome {
+			r.formatos[i].mediaTypes = append(r.formatos[i].mediaTypes, mediaTypes...)
+		}
+	}
+	return r
+}
+
+// Negocia escolhe o encoder pelo parâmetro ?formato= ou, na ausência dele, pelo header Accept
+// respeitando os valores de qualidade. Retorna o encoder e o media type da resposta.
+func (r *Registry) Negocia(req *http.Request) (Encoder, string, error) {
+	if len(r.formatos) == 0 {
+		return nil, "", ErrFormatoNaoSuportado
+	}
+
+	if nome := req.URL.Query().Get("formato"); nome != "" {
+		for _, f := range r.formatos {
+			if strings.EqualFold(f.nome, nome) {
+				return f.encoder, f.mediaTypes[0], nil
+			}
+		}
+		return nil, "", fmt.Errorf("%w: %s", ErrFormatoNaoSuportado, nome)
+	}
+
+	accept := strings.TrimSpace(req.Header.Get("Accept"))
+	if accept == "" {
+		return r.formatos[0].encoder, r.formatos[0].mediaTypes[0], nil
+	}
+
+	for _, preferencia := range parseAccept(accept) {
+		for _, f := range r.formatos {
+			for _, mediaType := range f.mediaTypes {
+				if preferencia.aceita(mediaType) {
+					return f.encoder, mediaType, nil
+				}
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", ErrFormatoNaoSuportado, accept)
+}
+
+type preferencia struct {
+	mediaType string
+	q         float64
+}
+
+// especificidade ordena, entre preferências de mesma qualidade, o media type exato antes dos curingas.
+func (p preferencia) especificidade() int {
+	switch {
+	case p.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(p.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (p preferencia) aceita(mediaType string) bool {
+	switch p.especificidade() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(p.mediaType, "*"))
+	default:
+		return strings.EqualFold(p.mediaType, mediaType)
+	}
+}
+
+func parseAccept(accept string) []preferencia {
+	preferencias := make([]preferencia, 0)
+	for _, item := range strings.Split(accept, ",") {
+		partes := strings.Split(item, ";")
+		p := preferencia{mediaType: strings.ToLower(strings.TrimSpace(partes[0])), q: 1}
+		for _, parametro := range partes[1:] {
+			chave, valor, _ := strings.Cut(strings.TrimSpace(parametro), "=")
+			if strings.TrimSpace(chave) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(valor), 64); err == nil {
+					p.q = q
+				}
+			}
+		}
+		if p.mediaType == "" || p.q <= 0 {
+			continue
+		}
+		preferencias = append(preferencias, p)
+	}
+
+	sort.SliceStable(preferencias, func(i, j int) bool {
+		if preferencias[i].q != preferencias[j].q {
+			return preferencias[i].q > preferencias[j].q
+		}
+		return preferencias[i].especificidade() > preferencias[j].especificidade()
+	})
+
+	return preferencias
+}
+
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+type XMLEncoder struct{}
+
+func (XMLEncoder) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// CSVEncoder aceita um CSVMarshaler ou uma lista deles; no caso de uma lista, o cabeçalho é escrito uma única vez.
+type CSVEncoder struct{}
+
+func (CSVEncoder) Encode(w io.Writer, v any) error {
+	registros, err := registrosCSV(v)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(registros); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func registrosCSV(v any) ([][]string, error) {
+	if marshaler, ok := v.(CSVMarshaler); ok {
+		return marshaler.MarshalCSV()
+	}
+
+	valor := reflect.ValueOf(v)
+	if valor.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%w: %T não pode ser exportado como csv", ErrFormatoNaoSuportado, v)
+	}
+
+	registros := make([][]string, 0, valor.Len()+1)
+	for i := 0; i < valor.Len(); i++ {
+		item, err := registrosCSV(valor.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if len(registros) > 0 && len(item) > 0 {
+			item = item[1:]
+		}
+		registros = append(registros, item...)
+	}
+
+	return registros, nil
+}
+
+type ProtobufEncoder struct{}
+
+func (ProtobufEncoder) Encode(w io.Writer, v any) error {
+	marshaler, ok := v.(ProtoMarshaler)
+	if !ok {
+		return fmt.Errorf("%w: %T não pode ser exportado como protobuf", ErrFormatoNaoSuportado, v)
+	}
+
+	body, err := marshaler.MarshalProto()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,89 @@
+package formatos
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatosTestSuite struct {
+	suite.Suite
+	registry *Registry
+}
+
+type leituraFake struct {
+	cidade string
+	valor  string
+}
+
+func (l leituraFake) MarshalCSV() ([][]string, error) {
+	return [][]string{{"city", "temp_C"}, {l.cidade, l.valor}}, nil
+}
+
+func TestFormatosSuite(t *testing.T) {
+	suite.Run(t, new(FormatosTestSuite))
+}
+
+func (s *FormatosTestSuite) SetupTest() {
+	s.registry = NewRegistryPadrao()
+}
+
+func (s *FormatosTestSuite) TestNegocia() {
+	cenarios := []struct {
+		nome                string
+		accept              string
+		formato             string
+		expectedContentType string
+		expectedErr         error
+	}{
+		{"sem preferencia", "", "", "application/json", nil},
+		{"json", "application/json", "", "application/json", nil},
+		{"xml", "text/xml", "", "text/xml", nil},
+		{"valores de qualidade", "application/json;q=0.4, text/csv;q=0.9, application/xml;q=0.5", "", "text/csv", nil},
+		{"exato antes do curinga", "*/*, application/x-protobuf", "", "application/x-protobuf", nil},
+		{"curinga de tipo", "text/*", "", "text/xml", nil},
+		{"curinga geral", "*/*", "", "application/json", nil},
+		{"qualidade zero exclui", "application/json;q=0, text/csv", "", "text/csv", nil},
+		{"parametro formato tem precedencia", "application/json", "csv", "text/csv", nil},
+		{"formato desconhecido", "", "yaml", "", ErrFormatoNaoSuportado},
+		{"nenhum formato aceito", "text/html, image/png", "", "", ErrFormatoNaoSuportado},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/temperaturas?formato="+cenario.formato, nil)
+			req.Header.Set("Accept", cenario.accept)
+
+			// Act
+			_, contentType, err := s.registry.Negocia(req)
+
+			// Assert
+			s.ErrorIs(err, cenario.expectedErr)
+			s.Equal(cenario.expectedContentType, contentType)
+		})
+	}
+}
+
+func (s *FormatosTestSuite) TestCSVEncoderComLista() {
+	// Arrange
+	leituras := []leituraFake{{"São Paulo", "28.5"}, {"Campinas", "26.0"}}
+	var buffer bytes.Buffer
+
+	// Act
+	err := CSVEncoder{}.Encode(&buffer, leituras)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("city,temp_C\nSão Paulo,28.5\nCampinas,26.0\n", buffer.String())
+}
+
+func (s *FormatosTestSuite) TestEncoderComTipoNaoSuportado() {
+	var buffer bytes.Buffer
+
+	s.ErrorIs(CSVEncoder{}.Encode(&buffer, struct{}{}), ErrFormatoNaoSuportado)
+	s.ErrorIs(ProtobufEncoder{}.Encode(&buffer, struct{}{}), ErrFormatoNaoSuportado)
+}
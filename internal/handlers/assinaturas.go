@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tamanhoMaximoAssinatura = 16 << 10
+	tamanhoMinimoSegredo    = 16
+)
+
+type AssinaturaInput struct {
+	Cep              string   `json:"cep"`
+	Condition        string   `json:"condition"`
+	ThresholdCelsius *float64 `json:"threshold_celsius"`
+	CallbackURL      string   `json:"callback_url"`
+	Secret           string   `json:"secret,omitempty"`
+}
+
+type AssinaturaOutput struct {
+	ID               string    `json:"id"`
+	Cep              string    `json:"cep"`
+	Condition        string    `json:"condition"`
+	ThresholdCelsius float64   `json:"threshold_celsius"`
+	CallbackURL      string    `json:"callback_url"`
+	Secret           string    `json:"secret,omitempty"`
+	Triggered        bool      `json:"triggered"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type FalhaEntregaOutput struct {
+	ID       int64           `json:"id"`
+	EventID  string          `json:"event_id"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// CriaAssinaturaHandler atende POST /assinaturas. Sem secret no corpo, um segredo é gerado; ele só
+// aparece nesta resposta.
+func CriaAssinaturaHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "CriaAssinaturaHandler")
+		defer span.End()
+
+		assinatura, err := leAssinatura(w, r)
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		assinatura.ID = alertas.NovoID(8)
+		assinatura.Cliente = clienteDaRequisicao(ctx)
+		assinatura.CriadaEm = time.Now().UTC()
+		if assinatura.Segredo == "" {
+			assinatura.Segredo = alertas.NovoID(32)
+		}
+
+		if err := repositorio.Salva(ctx, assinatura); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+
+		w.Header().Set("Location", "/assinaturas/"+assinatura.ID)
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusCreated, assinaturaOutput(assinatura, true)); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+		}
+	}
+}
+
+func ListaAssinaturasHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "ListaAssinaturasHandler")
+		defer span.End()
+
+		assinaturas, err := repositorio.Lista(ctx, clienteDaRequisicao(ctx))
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+
+		resposta := make([]AssinaturaOutput, 0, len(assinaturas))
+		for _, assinatura := range assinaturas {
+			resposta = append(resposta, assinaturaOutput(assinatura, false))
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, resposta); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+		}
+	}
+}
+
+func BuscaAssinaturaHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "BuscaAssinaturaHandler")
+		defer span.End()
+
+		assinatura, err := repositorio.Busca(ctx, clienteDaRequisicao(ctx), r.PathValue("id"))
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, assinaturaOutput(assinatura, false)); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+		}
+	}
+}
+
+// AtualizaAssinaturaHandler atende PUT /assinaturas/{id}, substituindo a regra e o destino. Sem secret,
+// o segredo atual é mantido. Mudar o CEP ou a regra rearma o alerta.
+func AtualizaAssinaturaHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "AtualizaAssinaturaHandler")
+		defer span.End()
+
+		atual, err := repositorio.Busca(ctx, clienteDaRequisicao(ctx), r.PathValue("id"))
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		assinatura, err := leAssinatura(w, r)
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+
+		assinatura.ID = atual.ID
+		assinatura.Cliente = atual.Cliente
+		assinatura.CriadaEm = atual.CriadaEm
+		if assinatura.Segredo == "" {
+			assinatura.Segredo = atual.Segredo
+		}
+		mesmaRegra := assinatura.Cep == atual.Cep && assinatura.Condicao == atual.Condicao && assinatura.Limite == atual.Limite
+		assinatura.Disparada = atual.Disparada && mesmaRegra
+
+		if err := repositorio.Salva(ctx, assinatura); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, assinaturaOutput(assinatura, false)); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+		}
+	}
+}
+
+func RemoveAssinaturaHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "RemoveAssinaturaHandler")
+		defer span.End()
+
+		if err := repositorio.Remove(ctx, clienteDaRequisicao(ctx), r.PathValue("id")); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListaFalhasAssinaturaHandler atende GET /assinaturas/{id}/falhas, com as entregas que esgotaram as
+// tentativas ou foram recusadas, na ordem em que falharam.
+func ListaFalhasAssinaturaHandler(tracer trace.Tracer, repositorio alertas.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "ListaFalhasAssinaturaHandler")
+		defer span.End()
+
+		assinatura, err := repositorio.Busca(ctx, clienteDaRequisicao(ctx), r.PathValue("id"))
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+		falhas, err := repositorio.ListaFalhas(ctx, assinatura.ID)
+		if err != nil {
+			respondeErroAssinatura(w, r, span, err)
+			return
+		}
+
+		resposta := make([]FalhaEntregaOutput, 0, len(falhas))
+		for _, falha := range falhas {
+			resposta = append(resposta, FalhaEntregaOutput{
+				ID:       falha.ID,
+				EventID:  falha.EventoID,
+				Payload:  falha.Corpo,
+				Attempts: falha.Tentativas,
+				Error:    falha.Erro,
+				FailedAt: falha.Em,
+			})
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, resposta); err != nil {
+			respondeErroAssinatura(w, r, span, err)
+		}
+	}
+}
+
+func leAssinatura(w http.ResponseWriter, r *http.Request) (alertas.Assinatura, error) {
+	input := AssinaturaInput{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, tamanhoMaximoAssinatura))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return alertas.Assinatura{}, fmt.Errorf("%w: %v", erros.ErrInvalidSubscription, err)
+	}
+
+	cep, err := domain.NewCep(input.Cep)
+	if err != nil {
+		return alertas.Assinatura{}, err
+	}
+
+	condicao := alertas.Condicao(input.Condition)
+	if !condicao.Valida() {
+		return alertas.Assinatura{}, fmt.Errorf("%w: condition %q, use above ou below", erros.ErrInvalidSubscription, input.Condition)
+	}
+	if input.ThresholdCelsius == nil || math.IsNaN(*input.ThresholdCelsius) || math.IsInf(*input.ThresholdCelsius, 0) {
+		return alertas.Assinatura{}, fmt.Errorf("%w: threshold_celsius é obrigatório", erros.ErrInvalidSubscription)
+	}
+
+	destino, err := url.Parse(input.CallbackURL)
+	if err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Host == "" {
+		return alertas.Assinatura{}, fmt.Errorf("%w: callback_url %q, use uma URL http ou https absoluta", erros.ErrInvalidSubscription, input.CallbackURL)
+	}
+	if err := alertas.ValidaDestino(r.Context(), destino); err != nil {
+		return alertas.Assinatura{}, fmt.Errorf("%w: callback_url %q: %v", erros.ErrInvalidSubscription, input.CallbackURL, err)
+	}
+	if input.Secret != "" && len(input.Secret) < tamanhoMinimoSegredo {
+		return alertas.Assinatura{}, fmt.Errorf("%w: secret precisa de ao menos %d caracteres", erros.ErrInvalidSubscription, tamanhoMinimoSegredo)
+	}
+
+	return alertas.Assinatura{
+		Cep:      cep.Codigo(),
+		Condicao: condicao,
+		Limite:   *input.ThresholdCelsius,
+		URL:      destino.String(),
+		Segredo:  input.Secret,
+	}, nil
+}
+
+// assinaturaOutput só inclui o segredo na resposta da criação.
+func assinaturaOutput(assinatura alertas.Assinatura, comSegredo bool) AssinaturaOutput {
+	output := AssinaturaOutput{
+		ID:               assinatura.ID,
+		Cep:              assinatura.Cep,
+		Condition:        string(assinatura.Condicao),
+		ThresholdCelsius: assinatura.Limite,
+		CallbackURL:      assinatura.URL,
+		Triggered:        assinatura.Disparada,
+		CreatedAt:        assinatura.CriadaEm,
+	}
+	if comSegredo {
+		output.Secret = assinatura.Segredo
+	}
+	return output
+}
+
+func respondeErroAssinatura(w http.ResponseWriter, r *http.Request, span trace.Span, err error) {
+	respondeErro(w, r, span, err, "handling alert subscription")
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+)
+
+// calculaETag identifica a representação pela leitura (LastUpdatedEpoch) e pelas opções que alteram o corpo,
+// inclusive o idioma da condição do tempo.
+func calculaETag(dadosInput usecases.DadosCepInput, versao versaoApi, contentType string, idioma i18n.Idioma, observedAt time.Time) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%s",
+		versao,
+		contentType,
+		dadosInput.Opcoes.Query().Encode(),
+		idioma,
+	)))
+
+	return fmt.Sprintf(`"%s-%d-%s"`, helpers.NormalizeZipCode(dadosInput.Cep), observedAt.Unix(), hex.EncodeToString(hash[:6]))
+}
+
+func defineCabecalhosCache(w http.ResponseWriter, etag string, observedAt time.Time) {
+	http.Header.Set(w.Header(), "ETag", etag)
+	http.Header.Set(w.Header(), "Last-Modified", observedAt.UTC().Format(http.TimeFormat))
+	// max_age é o tempo que clientes e CDNs podem reutilizar a resposta sem revalidar. Com autenticação, só o
+	// próprio cliente pode guardá-la: um cache compartilhado a entregaria a quem não enviou chave.
+	maxAge := config.Get().GetCache().MaxAge
+	diretiva := "public"
+	if config.Get().GetAutenticacao().Habilitada {
+		diretiva = "private"
+	}
+	http.Header.Set(w.Header(), "Cache-Control", fmt.Sprintf("%s, max-age=%d", diretiva, int(maxAge.Seconds())))
+}
+
+func naoModificado(r *http.Request, etag string) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidato := range strings.Split(ifNoneMatch, ",") {
+		candidato = strings.TrimPrefix(strings.TrimSpace(candidato), "W/")
+		if candidato == "*" || candidato == etag {
+			return true
+		}
+	}
+
+	return false
+}
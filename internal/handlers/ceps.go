@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnderecoHandler atende GET /ceps/{cep} com o endereço normalizado do CEP, consultado no ViaCEP sem passar
+// pelo provedor de clima.
+func EnderecoHandler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "EnderecoHandler")
+		defer span.End()
+
+		cep := r.PathValue("cep")
+		endereco, err := NovoTemperaturasService(tracer)().Endereco(ctx, cep)
+		if err != nil {
+			respondeErroEndereco(w, r, span, cep, err)
+			return
+		}
+
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, endereco); err != nil {
+			respondeErroEndereco(w, r, span, cep, err)
+			return
+		}
+
+		span.SetStatus(codes.Ok, "Endereço consultado com sucesso")
+	}
+}
+
+func respondeErroEndereco(w http.ResponseWriter, r *http.Request, span trace.Span, cep string, err error) {
+	respondeErro(w, r, span, err, "fetching address for CEP "+cep)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BuscaCidadeHandler atende GET /cidades?uf=&nome=&logradouro=, com os CEPs que a ViaCEP encontra para os
+// logradouros da cidade. O nome é comparado sem acentos e sem caixa.
+func BuscaCidadeHandler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "BuscaCidadeHandler")
+		defer span.End()
+
+		query := r.URL.Query()
+		localidade, err := domain.NewLocalidade(query.Get("nome"), query.Get("uf"))
+		if err != nil {
+			respondeErroCidade(w, r, span, err)
+			return
+		}
+
+		useCase := usecases.NewBuscaCidadeUseCase(clients.NewViaCepClient(tracer, config.Get().GetViaCep()))
+		cidade, err := useCase.Execute(ctx, localidade, query.Get("logradouro"))
+		if err != nil {
+			respondeErroCidade(w, r, span, err)
+			return
+		}
+
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, cidade); err != nil {
+			respondeErroCidade(w, r, span, err)
+			return
+		}
+
+		span.SetStatus(codes.Ok, "Cidade localizada com sucesso")
+	}
+}
+
+func respondeErroCidade(w http.ResponseWriter, r *http.Request, span trace.Span, err error) {
+	respondeErro(w, r, span, err, "searching city")
+}
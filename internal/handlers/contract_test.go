@@ -0,0 +1,897 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/api"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/grupos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type ContractTestSuite struct {
+	suite.Suite
+	router    routers.Router
+	upstreams []*httptest.Server
+}
+
+func TestContractSuite(t *testing.T) {
+	suite.Run(t, new(ContractTestSuite))
+}
+
+func (s *ContractTestSuite) SetupSuite() {
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	s.Require().NoError(err)
+	s.Require().NoError(doc.Validate(context.Background()))
+
+	s.router, err = legacy.NewRouter(doc)
+	s.Require().NoError(err)
+
+	openapi3filter.RegisterBodyDecoder(MediaTypeTemperaturasV2, openapi3filter.JSONBodyDecoder)
+
+	viaCep := s.novoUpstream(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Count(r.URL.Path, "/") == 6 {
+			// Pesquisa de endereços: /ws/{UF}/{cidade}/{logradouro}/json/
+			if !strings.Contains(r.URL.Path, "/campinas/") {
+				io.WriteString(w, `[]`)
+				return
+			}
+			io.WriteString(w, `[
+				{"cep": "13010-000", "logradouro": "Rua Barão de Jaguara", "bairro": "Centro", "localidade": "Campinas", "uf": "SP"},
+				{"cep": "13270-000", "logradouro": "Rua Campinas", "bairro": "Centro", "localidade": "Valinhos", "uf": "SP"}
+			]`)
+			return
+		}
+		if strings.Contains(r.URL.Path, "99999999") {
+			io.WriteString(w, `{"erro": "true"}`)
+			return
+		}
+		if strings.Contains(r.URL.Path, "88888888") {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if strings.Contains(r.URL.Path, "77777777") {
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, `<html><body>Manutenção programada</body></html>`)
+			return
+		}
+		io.WriteString(w, `{"cep": "01001-000", "logradouro": "Praça da Sé", "complemento": "lado ímpar", "bairro": "Sé", "localidade": "São Paulo", "uf": "SP", "ibge": "3550308"}`)
+	})
+	weatherApi := s.novoUpstream(func(w http.ResponseWriter, r *http.Request) {
+		condicao := "Partly cloudy"
+		if r.URL.Query().Get("lang") == "pt" {
+			condicao = "Parcialmente nublado"
+		}
+		fmt.Fprintf(w, `{"location": {"name": "Sao Paulo"}, "current": {"temp_c": 28.5, "last_updated_epoch": 1749124800, "condition": {"text": %q}}}`, condicao)
+	})
+	servicoB := s.novoUpstream(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "99999999") {
+			i18n.Error(w, r, erros.ErrZipCodeNotFound, http.StatusNotFound)
+			return
+		}
+		if strings.Contains(r.URL.Path, "88888888") {
+			i18n.Error(w, r, erros.ErrUpstreamUnavailable, http.StatusBadGateway)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/v2/") {
+			io.WriteString(w, `{"city": "São Paulo", "temperatures": [{"value": 28.5, "unit": "celsius", "symbol": "°C"}], "observed_at": "2025-06-05T12:00:00Z"}`)
+			return
+		}
+		w.Header().Set("Last-Modified", "Thu, 05 Jun 2025 12:00:00 GMT")
+		io.WriteString(w, `{"city": "São Paulo", "temp_C": "28.5", "temp_F": "83.3", "temp_K": "301.6"}`)
+	})
+
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.T().Setenv("WEATHER_API_KEY", "chave-de-teste")
+	s.T().Setenv("VIACEP_BASE_URL", viaCep.URL+"/ws/")
+	s.T().Setenv("WEATHERAPI_BASE_URL", weatherApi.URL+"/v1/")
+	s.T().Setenv("SERVICO_B_BASE_URL", servicoB.URL)
+	s.Require().NoError(config.LoadConfig("."))
+}
+
+func (s *ContractTestSuite) novoUpstream(handler http.HandlerFunc) *httptest.Server {
+	// Como os upstreams reais, os stubs respondem JSON; http.Error troca o tipo nas respostas de erro.
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	s.upstreams = append(s.upstreams, upstream)
+	return upstream
+}
+
+func (s *ContractTestSuite) TearDownSuite() {
+	openapi3filter.UnregisterBodyDecoder(MediaTypeTemperaturasV2)
+	for _, upstream := range s.upstreams {
+		upstream.Close()
+	}
+}
+
+func (s *ContractTestSuite) validaContrato(handler http.HandlerFunc, req *http.Request, expectedStatus int) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	s.Equal(expectedStatus, recorder.Code)
+
+	route, pathParams, err := s.router.FindRoute(req)
+	s.Require().NoError(err)
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		// A chave de API é verificada pelo middleware do servidor, que não participa destes testes.
+		Options: &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	if expectedStatus != http.StatusBadRequest {
+		s.NoError(openapi3filter.ValidateRequest(context.Background(), requestInput))
+	}
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 recorder.Code,
+		Header:                 recorder.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(recorder.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	s.NoError(openapi3filter.ValidateResponse(context.Background(), responseInput))
+}
+
+func (s *ContractTestSuite) TestCapturaTemperaturasHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		rota           string
+		accept         string
+		body           string
+		expectedStatus int
+	}{
+		{"cep valido", "/temperaturas", "application/json", `{"cep": "01001000"}`, http.StatusOK},
+		{"cep invalido", "/temperaturas", "application/json", `{"cep": "12345"}`, http.StatusUnprocessableEntity},
+		{"cep inexistente", "/temperaturas", "application/json", `{"cep": "99999999"}`, http.StatusNotFound},
+		{"cep valido v2 pelo header Accept", "/temperaturas", MediaTypeTemperaturasV2, `{"cep": "01001000"}`, http.StatusOK},
+		{"cep valido v2", "/v2/temperaturas", "application/json", `{"cep": "01001000"}`, http.StatusOK},
+		{"cep inexistente v2", "/v2/temperaturas", "application/json", `{"cep": "99999999"}`, http.StatusNotFound},
+		{"servico b indisponivel", "/temperaturas", "application/json", `{"cep": "88888888"}`, http.StatusBadGateway},
+		{"formato nao suportado", "/temperaturas", "text/html", `{"cep": "01001000"}`, http.StatusNotAcceptable},
+		{"formato csv", "/temperaturas", "text/csv", `{"cep": "01001000"}`, http.StatusOK},
+		{"formato protobuf v2", "/v2/temperaturas", "application/x-protobuf", `{"cep": "01001000"}`, http.StatusOK},
+		{"unidade invalida", "/temperaturas?unidades=C,X", "application/json", `{"cep": "01001000"}`, http.StatusBadRequest},
+		{"unidades selecionadas", "/temperaturas?unidades=C,R&precisao=2", "application/json", `{"cep": "01001000"}`, http.StatusOK},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:3000"+cenario.rota, strings.NewReader(cenario.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", cenario.accept)
+
+			handler := CapturaTemperaturasHandler(tracer)
+			if strings.HasPrefix(cenario.rota, "/v2/") {
+				handler = CapturaTemperaturasV2Handler(tracer)
+			}
+
+			s.validaContrato(handler, req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestProcessaTemperaturasHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		prefixo        string
+		accept         string
+		cep            string
+		expectedStatus int
+	}{
+		{"cep valido", "", "application/json", "01001000", http.StatusOK},
+		{"cep invalido", "", "application/json", "0100100a", http.StatusUnprocessableEntity},
+		{"cep inexistente", "", "application/json", "99999999", http.StatusNotFound},
+		{"viacep indisponivel", "", "application/json", "88888888", http.StatusBadGateway},
+		{"cep valido v2 pelo header Accept", "", MediaTypeTemperaturasV2, "01001000", http.StatusOK},
+		{"cep valido v2", "/v2", "application/json", "01001000", http.StatusOK},
+		{"cep invalido v2", "/v2", "application/json", "0100100a", http.StatusUnprocessableEntity},
+		{"formato nao suportado", "", "image/png", "01001000", http.StatusNotAcceptable},
+		{"formato csv v2", "/v2", "text/csv;q=0.8, application/json;q=0.5", "01001000", http.StatusOK},
+		{"precisao invalida", "", "application/json", "01001000?precisao=9", http.StatusBadRequest},
+		{"unidades e arredondamento", "/v2", "application/json", "01001000?unidades=K,R&arredondamento=ceil&precisao=0", http.StatusOK},
+		{"com endereco", "", "application/json", "01001000?incluir=endereco", http.StatusOK},
+		{"com endereco v2", "/v2", "application/json", "01001000?incluir=endereco", http.StatusOK},
+		{"incluir invalido", "", "application/json", "01001000?incluir=clima", http.StatusBadRequest},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			cep, query, _ := strings.Cut(cenario.cep, "?")
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001"+cenario.prefixo+"/cidades/"+cep+"/temperaturas?"+query, nil)
+			req.Header.Set("Accept", cenario.accept)
+			req.SetPathValue("cep", cep)
+
+			gravador := historico.NewGravador(historico.NewRepositorioMemoria(), 10)
+			handler := ProcessaTemperaturasHandler(tracer, gravador)
+			if cenario.prefixo == "/v2" {
+				handler = ProcessaTemperaturasV2Handler(tracer, gravador)
+			}
+
+			s.validaContrato(handler, req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestProcessaTemperaturasIncluiEnderecoSoQuandoPedido() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	handler := ProcessaTemperaturasV2Handler(tracer, historico.NewGravador(historico.NewRepositorioMemoria(), 10))
+
+	for query, esperado := range map[string]*usecases.Endereco{
+		"":                  nil,
+		"?incluir=endereco": {Cep: "01001000", Street: "Praça da Sé", Complement: "lado ímpar", Neighborhood: "Sé", City: "São Paulo", State: "SP", Ibge: "3550308"},
+	} {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/v2/cidades/01001000/temperaturas"+query, nil)
+		req.SetPathValue("cep", "01001000")
+		recorder := httptest.NewRecorder()
+
+		// Act
+		handler(recorder, req)
+
+		// Assert
+		s.Require().Equal(http.StatusOK, recorder.Code)
+		resposta := usecases.DadosTemperaturasV2{}
+		s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &resposta))
+		s.Equal(esperado, resposta.Address, query)
+	}
+}
+
+func (s *ContractTestSuite) TestProcessaTemperaturasNoIdiomaDaRequisicao() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	handler := ProcessaTemperaturasV2Handler(tracer, historico.NewGravador(historico.NewRepositorioMemoria(), 10))
+
+	cenarios := []struct {
+		idioma         i18n.Idioma
+		cep            string
+		expectedStatus int
+		esperado       string
+	}{
+		{i18n.PortuguesBrasil, "01001000", http.StatusOK, "Parcialmente nublado"},
+		{i18n.Ingles, "01001000", http.StatusOK, "Partly cloudy"},
+		{i18n.Espanhol, "0100100a", http.StatusUnprocessableEntity, "código postal inválido\n"},
+	}
+
+	for _, cenario := range cenarios {
+		// Arrange
+		req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/v2/cidades/"+cenario.cep+"/temperaturas", nil)
+		req = req.WithContext(i18n.NovoContexto(req.Context(), cenario.idioma))
+		req.SetPathValue("cep", cenario.cep)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		handler(recorder, req)
+
+		// Assert
+		s.Require().Equal(cenario.expectedStatus, recorder.Code, cenario.idioma)
+		if cenario.expectedStatus != http.StatusOK {
+			s.Equal(cenario.esperado, recorder.Body.String())
+			continue
+		}
+		resposta := usecases.DadosTemperaturasV2{}
+		s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &resposta))
+		s.Equal(cenario.esperado, resposta.Condition, cenario.idioma)
+	}
+}
+
+func (s *ContractTestSuite) TestEnderecoHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		cep            string
+		expectedStatus int
+	}{
+		{"cep valido", "01001000", http.StatusOK},
+		{"cep com hifen", "01001-000", http.StatusOK},
+		{"cep invalido", "0100100a", http.StatusUnprocessableEntity},
+		{"cep inexistente", "99999999", http.StatusNotFound},
+		{"viacep indisponivel", "88888888", http.StatusBadGateway},
+		{"viacep responde html", "77777777", http.StatusBadGateway},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/ceps/"+cenario.cep, nil)
+			req.SetPathValue("cep", cenario.cep)
+
+			s.validaContrato(EnderecoHandler(tracer), req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestBuscaCidadeHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		query          string
+		expectedStatus int
+	}{
+		{"cidade valida", "uf=sp&nome=CAMPINAS", http.StatusOK},
+		{"com logradouro", "uf=SP&nome=campinas&logradouro=barao", http.StatusOK},
+		{"uf invalida", "uf=XX&nome=campinas", http.StatusUnprocessableEntity},
+		{"cidade inexistente", "uf=SP&nome=atlantida", http.StatusNotFound},
+		{"logradouro curto", "uf=SP&nome=campinas&logradouro=ab", http.StatusBadRequest},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/cidades?"+cenario.query, nil)
+
+			s.validaContrato(BuscaCidadeHandler(tracer), req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestBuscaCidadeDescartaOutrasCidades() {
+	// Arrange
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/cidades?uf=SP&nome=campinas", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	BuscaCidadeHandler(tracer)(recorder, req)
+
+	// Assert
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	resposta := usecases.CidadeOutput{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &resposta))
+	s.Equal("Campinas", resposta.City)
+	s.Require().Len(resposta.Addresses, 1)
+	s.Equal("13010000", resposta.Addresses[0].Cep)
+}
+
+func (s *ContractTestSuite) TestProcessaTemperaturasCidadeHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		prefixo        string
+		uf             string
+		cidade         string
+		expectedStatus int
+	}{
+		{"cidade valida", "", "sp", "são paulo", http.StatusOK},
+		{"cidade valida v2", "/v2", "SP", "Sao Paulo", http.StatusOK},
+		{"uf invalida", "", "XX", "São Paulo", http.StatusUnprocessableEntity},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001"+cenario.prefixo+"/cidades/"+cenario.uf+"/"+url.PathEscape(cenario.cidade)+"/temperaturas", nil)
+			req.Header.Set("Accept", "application/json")
+			req.SetPathValue("uf", cenario.uf)
+			req.SetPathValue("cidade", cenario.cidade)
+
+			gravador := historico.NewGravador(historico.NewRepositorioMemoria(), 10)
+			handler := ProcessaTemperaturasCidadeHandler(tracer, gravador)
+			if cenario.prefixo == "/v2" {
+				handler = ProcessaTemperaturasCidadeV2Handler(tracer, gravador)
+			}
+
+			s.validaContrato(handler, req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestHistoricoHandler() {
+	// Arrange
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	repositorio := historico.NewRepositorioMemoria()
+	gravador := historico.NewGravador(repositorio, 10)
+	for _, cep := range []string{"01001000", "99999999", "01001-000"} {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/cidades/"+cep+"/temperaturas", nil)
+		req.SetPathValue("cep", cep)
+		ProcessaTemperaturasHandler(tracer, gravador)(httptest.NewRecorder(), req)
+	}
+	s.Require().NoError(gravador.Encerra(context.Background()))
+
+	cenarios := []struct {
+		nome           string
+		query          string
+		expectedStatus int
+		total          int
+	}{
+		{"sem filtro", "", http.StatusOK, 2},
+		{"por cep e periodo", "?cep=01001-000&de=2025-01-01&ate=2999-12-31&unidades=K", http.StatusOK, 2},
+		{"periodo sem consultas", "?ate=2025-01-01", http.StatusOK, 0},
+		{"segunda pagina", "?pagina=2&tamanho=1", http.StatusOK, 2},
+		{"cep invalido", "?cep=123", http.StatusUnprocessableEntity, 0},
+		{"periodo invertido", "?de=2025-06-10&ate=2025-06-01", http.StatusBadRequest, 0},
+		{"tamanho acima do limite", "?tamanho=1000", http.StatusBadRequest, 0},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/historico"+cenario.query, nil)
+			recorder := httptest.NewRecorder()
+			HistoricoHandler(tracer, repositorio)(recorder, req)
+
+			s.validaContrato(HistoricoHandler(tracer, repositorio), req, cenario.expectedStatus)
+			if cenario.expectedStatus == http.StatusOK {
+				pagina := PaginaHistorico{}
+				s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &pagina))
+				s.Equal(cenario.total, pagina.Total)
+			}
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestEstatisticasHandler() {
+	// Arrange
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	repositorio := historico.NewRepositorioMemoria()
+	// Todas no início da hora atual, para caírem no mesmo balde por hora e por dia.
+	inicioDaHora := time.Now().UTC().Truncate(time.Hour)
+	for _, celsius := range []float64{18, 20, 25} {
+		s.Require().NoError(repositorio.Salva(context.Background(), historico.Consulta{
+			Cep:          "01001000",
+			Cidade:       "São Paulo",
+			Uf:           "SP",
+			Celsius:      celsius,
+			Provedor:     "weatherapi",
+			ConsultadaEm: inicioDaHora,
+		}))
+	}
+
+	cenarios := []struct {
+		nome           string
+		query          string
+		expectedStatus int
+		baldes         int
+	}{
+		{"por cep e dia", "?cep=01001000&intervalo=dia&percentis=50,90&unidades=C,K", http.StatusOK, 1},
+		{"por cidade e uf", "?cidade=s%C3%A3o%20paulo&uf=SP", http.StatusOK, 1},
+		{"cidade sem consultas", "?cidade=Recife&uf=PE", http.StatusOK, 0},
+		{"cidade de outro estado", "?cidade=S%C3%A3o%20Paulo&uf=RJ", http.StatusOK, 0},
+		{"cidade sem uf", "?cidade=S%C3%A3o%20Paulo", http.StatusBadRequest, 0},
+		{"sem cep nem cidade", "?intervalo=dia", http.StatusBadRequest, 0},
+		{"intervalo invalido", "?cep=01001000&intervalo=semana", http.StatusBadRequest, 0},
+		{"percentil invalido", "?cep=01001000&percentis=101", http.StatusBadRequest, 0},
+		{"periodo acima do limite", "?cep=01001000&de=2025-01-01&ate=2025-03-01", http.StatusBadRequest, 0},
+		{"cep invalido", "?cep=123", http.StatusUnprocessableEntity, 0},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/historico/estatisticas"+cenario.query, nil)
+			recorder := httptest.NewRecorder()
+			EstatisticasHandler(tracer, repositorio)(recorder, req)
+
+			s.validaContrato(EstatisticasHandler(tracer, repositorio), req, cenario.expectedStatus)
+			if cenario.expectedStatus == http.StatusOK {
+				serie := SerieEstatisticas{}
+				s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &serie))
+				s.Len(serie.Buckets, cenario.baldes)
+			}
+		})
+	}
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/historico/estatisticas?cep=01001000&intervalo=dia&percentis=50&unidades=C", nil)
+	recorder := httptest.NewRecorder()
+	EstatisticasHandler(tracer, repositorio)(recorder, req)
+
+	// Assert
+	serie := SerieEstatisticas{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &serie))
+	s.Require().Len(serie.Buckets, 1)
+	s.Equal(3, serie.Buckets[0].Count)
+	s.Equal("São Paulo", serie.City)
+	s.Equal(EstatisticasTemperatura{
+		Unit:        "celsius",
+		Symbol:      "°C",
+		Min:         18,
+		Max:         25,
+		Avg:         21,
+		Percentiles: map[string]float64{"p50": 20},
+	}, serie.Buckets[0].Temperatures[0])
+}
+
+func (s *ContractTestSuite) TestAssinaturasHandlers() {
+	// Arrange
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	repositorio := alertas.NewRepositorioMemoria()
+	s.Require().NoError(repositorio.Salva(context.Background(), alertas.Assinatura{
+		ID:       "a1",
+		Cep:      "01001000",
+		Condicao: alertas.CondicaoAcima,
+		Limite:   35,
+		URL:      "https://alertas.example.com/webhook",
+		Segredo:  "segredo-com-16-caracteres",
+		CriadaEm: time.Date(2025, 6, 5, 12, 0, 0, 0, time.UTC),
+	}))
+	s.Require().NoError(repositorio.RegistraFalha(context.Background(), alertas.Falha{
+		AssinaturaID: "a1",
+		EventoID:     "e1",
+		Corpo:        []byte(`{"id":"e1","subscription_id":"a1","cep":"01001000","city":"São Paulo","condition":"above","threshold_celsius":35,"temperature_celsius":36.2,"observed_at":"2025-06-05T12:00:00Z","triggered_at":"2025-06-05T12:03:00Z"}`),
+		Tentativas:   5,
+		Erro:         "webhook respondeu 503",
+		Em:           time.Date(2025, 6, 5, 12, 3, 31, 0, time.UTC),
+	}))
+
+	corpo := `{"cep": "20040-002", "condition": "below", "threshold_celsius": 5, "callback_url": "https://alertas.example.com/frio"}`
+	cenarios := []struct {
+		nome           string
+		metodo         string
+		rota           string
+		id             string
+		body           string
+		handler        func(trace.Tracer, alertas.Repositorio) func(http.ResponseWriter, *http.Request)
+		expectedStatus int
+	}{
+		{"cria", http.MethodPost, "/assinaturas", "", corpo, CriaAssinaturaHandler, http.StatusCreated},
+		{"cria sem limite", http.MethodPost, "/assinaturas", "", `{"cep": "01001000", "condition": "above", "callback_url": "https://alertas.example.com/webhook"}`, CriaAssinaturaHandler, http.StatusBadRequest},
+		{"cria com campo desconhecido", http.MethodPost, "/assinaturas", "", `{"cep": "01001000", "limite": 35}`, CriaAssinaturaHandler, http.StatusBadRequest},
+		{"cria com callback relativo", http.MethodPost, "/assinaturas", "", `{"cep": "01001000", "condition": "above", "threshold_celsius": 35, "callback_url": "/webhook"}`, CriaAssinaturaHandler, http.StatusBadRequest},
+		{"cria com callback nos metadados da nuvem", http.MethodPost, "/assinaturas", "", `{"cep": "01001000", "condition": "above", "threshold_celsius": 35, "callback_url": "http://169.254.169.254/latest/meta-data/"}`, CriaAssinaturaHandler, http.StatusBadRequest},
+		{"cria com callback em loopback", http.MethodPost, "/assinaturas", "", `{"cep": "01001000", "condition": "above", "threshold_celsius": 35, "callback_url": "http://127.0.0.1:3001/assinaturas"}`, CriaAssinaturaHandler, http.StatusBadRequest},
+		{"cria com cep invalido", http.MethodPost, "/assinaturas", "", `{"cep": "123", "condition": "above", "threshold_celsius": 35, "callback_url": "https://alertas.example.com/webhook"}`, CriaAssinaturaHandler, http.StatusUnprocessableEntity},
+		{"lista", http.MethodGet, "/assinaturas", "", "", ListaAssinaturasHandler, http.StatusOK},
+		{"busca", http.MethodGet, "/assinaturas/a1", "a1", "", BuscaAssinaturaHandler, http.StatusOK},
+		{"busca inexistente", http.MethodGet, "/assinaturas/zz", "zz", "", BuscaAssinaturaHandler, http.StatusNotFound},
+		{"atualiza", http.MethodPut, "/assinaturas/a1", "a1", `{"cep": "01001000", "condition": "above", "threshold_celsius": 38, "callback_url": "https://alertas.example.com/webhook"}`, AtualizaAssinaturaHandler, http.StatusOK},
+		{"atualiza inexistente", http.MethodPut, "/assinaturas/zz", "zz", corpo, AtualizaAssinaturaHandler, http.StatusNotFound},
+		{"lista falhas", http.MethodGet, "/assinaturas/a1/falhas", "a1", "", ListaFalhasAssinaturaHandler, http.StatusOK},
+		{"lista falhas inexistente", http.MethodGet, "/assinaturas/zz/falhas", "zz", "", ListaFalhasAssinaturaHandler, http.StatusNotFound},
+		{"remove inexistente", http.MethodDelete, "/assinaturas/zz", "zz", "", RemoveAssinaturaHandler, http.StatusNotFound},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			var body io.Reader
+			if cenario.body != "" {
+				body = strings.NewReader(cenario.body)
+			}
+			req := httptest.NewRequest(cenario.metodo, "http://localhost:3001"+cenario.rota, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("id", cenario.id)
+
+			s.validaContrato(cenario.handler(tracer, repositorio), req, cenario.expectedStatus)
+		})
+	}
+
+	// Act
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:3001/assinaturas", strings.NewReader(corpo))
+	recorder := httptest.NewRecorder()
+	CriaAssinaturaHandler(tracer, repositorio)(recorder, req)
+	criada := AssinaturaOutput{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &criada))
+
+	remove := httptest.NewRequest(http.MethodDelete, "http://localhost:3001/assinaturas/"+criada.ID, nil)
+	remove.SetPathValue("id", criada.ID)
+	s.validaContrato(RemoveAssinaturaHandler(tracer, repositorio), remove, http.StatusNoContent)
+
+	// Assert
+	s.Equal("/assinaturas/"+criada.ID, recorder.Header().Get("Location"))
+	s.Equal("20040002", criada.Cep)
+	s.Len(criada.Secret, 64, "o segredo gerado só aparece na criação")
+	busca := httptest.NewRequest(http.MethodGet, "http://localhost:3001/assinaturas/a1", nil)
+	busca.SetPathValue("id", "a1")
+	recorder = httptest.NewRecorder()
+	BuscaAssinaturaHandler(tracer, repositorio)(recorder, busca)
+	atualizada := AssinaturaOutput{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &atualizada))
+	s.Equal(38.0, atualizada.ThresholdCelsius)
+	s.Empty(atualizada.Secret)
+	armazenada, err := repositorio.Busca(context.Background(), "", "a1")
+	s.Require().NoError(err)
+	s.Equal("segredo-com-16-caracteres", armazenada.Segredo, "sem secret, a atualização mantém o segredo")
+}
+
+func (s *ContractTestSuite) TestGruposHandlers() {
+	// Arrange
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	repositorio := grupos.NewRepositorioMemoria()
+	leituras := usecases.NewLeiturasCompartilhadas(2)
+	s.Require().NoError(repositorio.Salva(context.Background(), grupos.Grupo{
+		Nome:         "armazens-sp",
+		Ceps:         []string{"01001000", "99999999"},
+		CriadoEm:     time.Date(2025, 6, 5, 12, 0, 0, 0, time.UTC),
+		AtualizadoEm: time.Date(2025, 6, 5, 12, 0, 0, 0, time.UTC),
+	}))
+
+	cenarios := []struct {
+		nome           string
+		metodo         string
+		rota           string
+		grupo          string
+		body           string
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{"cria", http.MethodPut, "/grupos/lojas-rj", "lojas-rj", `{"ceps": ["20040-002", "20040002"]}`, SalvaGrupoHandler(tracer, repositorio), http.StatusCreated},
+		{"substitui", http.MethodPut, "/grupos/lojas-rj", "lojas-rj", `{"ceps": ["20040002", "01001000"]}`, SalvaGrupoHandler(tracer, repositorio), http.StatusOK},
+		{"cria sem ceps", http.MethodPut, "/grupos/vazio", "vazio", `{"ceps": []}`, SalvaGrupoHandler(tracer, repositorio), http.StatusBadRequest},
+		{"cria com nome invalido", http.MethodPut, "/grupos/Lojas", "Lojas", `{"ceps": ["01001000"]}`, SalvaGrupoHandler(tracer, repositorio), http.StatusBadRequest},
+		{"cria com cep invalido", http.MethodPut, "/grupos/lojas", "lojas", `{"ceps": ["123"]}`, SalvaGrupoHandler(tracer, repositorio), http.StatusUnprocessableEntity},
+		{"lista", http.MethodGet, "/grupos", "", "", ListaGruposHandler(tracer, repositorio), http.StatusOK},
+		{"busca", http.MethodGet, "/grupos/armazens-sp", "armazens-sp", "", BuscaGrupoHandler(tracer, repositorio), http.StatusOK},
+		{"busca inexistente", http.MethodGet, "/grupos/nenhum", "nenhum", "", BuscaGrupoHandler(tracer, repositorio), http.StatusNotFound},
+		{"temperaturas", http.MethodGet, "/grupos/armazens-sp/temperaturas?unidades=C,F", "armazens-sp", "", TemperaturasGrupoHandler(tracer, repositorio, leituras), http.StatusOK},
+		{"temperaturas com opcoes invalidas", http.MethodGet, "/grupos/armazens-sp/temperaturas?unidades=X", "armazens-sp", "", TemperaturasGrupoHandler(tracer, repositorio, leituras), http.StatusBadRequest},
+		{"temperaturas de grupo inexistente", http.MethodGet, "/grupos/nenhum/temperaturas", "nenhum", "", TemperaturasGrupoHandler(tracer, repositorio, leituras), http.StatusNotFound},
+		{"remove", http.MethodDelete, "/grupos/lojas-rj", "lojas-rj", "", RemoveGrupoHandler(tracer, repositorio), http.StatusNoContent},
+		{"remove inexistente", http.MethodDelete, "/grupos/lojas-rj", "lojas-rj", "", RemoveGrupoHandler(tracer, repositorio), http.StatusNotFound},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			var body io.Reader
+			if cenario.body != "" {
+				body = strings.NewReader(cenario.body)
+			}
+			req := httptest.NewRequest(cenario.metodo, "http://localhost:3000"+cenario.rota, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("nome", cenario.grupo)
+
+			s.validaContrato(cenario.handler, req, cenario.expectedStatus)
+		})
+	}
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/grupos/armazens-sp/temperaturas?unidades=C", nil)
+	req.SetPathValue("nome", "armazens-sp")
+	recorder := httptest.NewRecorder()
+	TemperaturasGrupoHandler(tracer, repositorio, leituras)(recorder, req)
+
+	// Assert
+	resposta := usecases.TemperaturasGrupoOutput{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &resposta))
+	s.Equal("armazens-sp", resposta.Group)
+	s.Require().Len(resposta.Readings, 2)
+	s.Equal("01001000", resposta.Readings[0].Cep)
+	s.Equal("São Paulo", resposta.Readings[0].City)
+	s.Equal([]usecases.TemperaturaV2{{Value: 28.5, Unit: "celsius", Symbol: "°C"}}, resposta.Readings[0].Temperatures)
+	s.Equal("99999999", resposta.Readings[1].Cep)
+	s.Equal("can not find zipcode", resposta.Readings[1].Error)
+}
+
+func (s *ContractTestSuite) TestTemperaturasGrupoNegociaOFormato() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+	repositorio := grupos.NewRepositorioMemoria()
+	leituras := usecases.NewLeiturasCompartilhadas(2)
+	s.Require().NoError(repositorio.Salva(context.Background(), grupos.Grupo{Nome: "armazens-sp", Ceps: []string{"01001000", "99999999"}}))
+
+	cenarios := []struct {
+		nome                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{"json", "application/json", http.StatusOK, "application/json"},
+		{"xml", "application/xml", http.StatusOK, "application/xml"},
+		{"csv", "text/csv", http.StatusOK, "text/csv"},
+		{"formato nao suportado", "image/png", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/grupos/armazens-sp/temperaturas?unidades=C,F", nil)
+			req.Header.Set("Accept", cenario.accept)
+			req.SetPathValue("nome", "armazens-sp")
+			recorder := httptest.NewRecorder()
+
+			// Act
+			TemperaturasGrupoHandler(tracer, repositorio, leituras)(recorder, req)
+
+			// Assert
+			s.Equal(cenario.expectedStatus, recorder.Code)
+			s.Equal(cenario.expectedContentType, recorder.Header().Get("Content-Type"))
+		})
+	}
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/grupos/armazens-sp/temperaturas?unidades=C", nil)
+	req.Header.Set("Accept", "text/csv")
+	req.SetPathValue("nome", "armazens-sp")
+	recorder := httptest.NewRecorder()
+	TemperaturasGrupoHandler(tracer, repositorio, leituras)(recorder, req)
+
+	// Assert
+	linhas := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	s.Equal([]string{
+		"cep,city,value,unit,symbol,observed_at,error",
+		"01001000,São Paulo,28.5,celsius,°C,2025-06-05T12:00:00Z,",
+		"99999999,,,,,,can not find zipcode",
+	}, linhas)
+}
+
+func (s *ContractTestSuite) TestConsultaTemperaturasHandler() {
+	tracer := noop.NewTracerProvider().Tracer("contract")
+
+	cenarios := []struct {
+		nome           string
+		rota           string
+		cep            string
+		expectedStatus int
+	}{
+		{"cep no path", "/temperaturas/01001000", "01001000", http.StatusOK},
+		{"cep na query", "/temperaturas?cep=01001000", "", http.StatusOK},
+		{"cep no path v2", "/v2/temperaturas/01001000", "01001000", http.StatusOK},
+		{"cep na query v2", "/v2/temperaturas?cep=01001000&unidades=K", "", http.StatusOK},
+		{"cep invalido", "/temperaturas/12345", "12345", http.StatusUnprocessableEntity},
+		{"cep inexistente", "/temperaturas?cep=99999999", "", http.StatusNotFound},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+cenario.rota, nil)
+			req.Header.Set("Accept", "application/json")
+			req.SetPathValue("cep", cenario.cep)
+
+			handler := ConsultaTemperaturasHandler(tracer)
+			if strings.HasPrefix(cenario.rota, "/v2/") {
+				handler = ConsultaTemperaturasV2Handler(tracer)
+			}
+
+			s.validaContrato(handler, req, cenario.expectedStatus)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestConsultaTemperaturasComIfNoneMatch() {
+	// Arrange
+	handler := ConsultaTemperaturasHandler(noop.NewTracerProvider().Tracer("contract"))
+	novaRequisicao := func(formato string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/temperaturas/01001000?formato="+formato, nil)
+		req.SetPathValue("cep", "01001000")
+		return req
+	}
+
+	primeira := httptest.NewRecorder()
+	handler(primeira, novaRequisicao("json"))
+	etag := primeira.Header().Get("ETag")
+
+	// Act
+	req := novaRequisicao("json")
+	req.Header.Set("If-None-Match", etag)
+	s.validaContrato(handler, req, http.StatusNotModified)
+
+	outroFormato := novaRequisicao("xml")
+	outroFormato.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	handler(recorder, outroFormato)
+
+	// Assert
+	s.Equal(http.StatusOK, primeira.Code)
+	s.NotEmpty(etag)
+	s.Equal("public, max-age=300", primeira.Header().Get("Cache-Control"))
+	s.Equal("Thu, 05 Jun 2025 12:00:00 GMT", primeira.Header().Get("Last-Modified"))
+	s.Equal(http.StatusOK, recorder.Code)
+	s.NotEqual(etag, recorder.Header().Get("ETag"))
+}
+
+func (s *ContractTestSuite) TestErrosDosHandlersSaoClassificados() {
+	cenarios := []struct {
+		nome   string
+		req    func() *http.Request
+		status int
+	}{
+		{"formato nao suportado", func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/temperaturas", strings.NewReader(`{"cep": "01001000"}`))
+			req.Header.Set("Accept", "text/html")
+			return req
+		}, http.StatusNotAcceptable},
+		{"falha ao ler o corpo", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/temperaturas", iotest.ErrReader(errors.New("conexão interrompida")))
+		}, http.StatusBadRequest},
+		{"unidade invalida", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/temperaturas?unidades=X", strings.NewReader(`{"cep": "01001000"}`))
+		}, http.StatusBadRequest},
+		{"cep invalido", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/temperaturas", strings.NewReader(`{"cep": "123"}`))
+		}, http.StatusUnprocessableEntity},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			spans := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("contract")
+			recorder := httptest.NewRecorder()
+
+			// Act
+			CapturaTemperaturasHandler(tracer)(recorder, cenario.req())
+
+			// Assert
+			s.Equal(cenario.status, recorder.Code)
+			s.Require().Len(spans.Ended(), 1)
+			span := spans.Ended()[0]
+			atributos := map[string]string{}
+			for _, atributo := range span.Attributes() {
+				atributos[string(atributo.Key)] = atributo.Value.Emit()
+			}
+			s.Equal(string(erros.TipoValidacao), atributos["error.type"])
+			s.Equal("false", atributos["error.retryable"])
+			s.Equal(codes.Error, span.Status().Code)
+		})
+	}
+}
+
+func (s *ContractTestSuite) TestRespostaAutenticadaNaoVaiParaCacheCompartilhado() {
+	// Arrange
+	s.T().Cleanup(func() { s.Require().NoError(config.LoadConfig(".")) })
+	s.T().Setenv("AUTENTICACAO_HABILITADA", "true")
+	s.T().Setenv("AUTENTICACAO_CHAVES", "cliente-a:chave-a")
+	s.Require().NoError(config.LoadConfig("."))
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/temperaturas/01001000", nil)
+	req.SetPathValue("cep", "01001000")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	ConsultaTemperaturasHandler(noop.NewTracerProvider().Tracer("contract"))(recorder, req)
+
+	// Assert
+	s.Equal(http.StatusOK, recorder.Code)
+	s.Equal("private, max-age=300", recorder.Header().Get("Cache-Control"))
+}
+
+func (s *ContractTestSuite) TestNegociaVersaoPeloHeaderAccept() {
+	cenarios := []struct {
+		accept         string
+		expectedVersao versaoApi
+	}{
+		{"", versaoV1},
+		{"application/json", versaoV1},
+		{MediaTypeTemperaturasV2, versaoV2},
+		{"text/html, " + MediaTypeTemperaturasV2 + ";q=0.9", versaoV2},
+	}
+
+	for _, cenario := range cenarios {
+		req := httptest.NewRequest(http.MethodGet, "/temperaturas", nil)
+		req.Header.Set("Accept", cenario.accept)
+
+		s.Equal(cenario.expectedVersao, negociaVersao(req), cenario.accept)
+	}
+}
+
+func (s *ContractTestSuite) TestOpenAPIHandler() {
+	recorder := httptest.NewRecorder()
+	OpenAPIHandler()(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	s.Equal(http.StatusOK, recorder.Code)
+	s.Equal("application/json", recorder.Header().Get("Content-Type"))
+	s.JSONEq(string(api.OpenAPI), recorder.Body.String())
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// respondeErro responde com o status de erros.Classifica e marca o span com o tipo do erro. Erros de
+// validação e de recurso inexistente são do cliente; os demais também vão para o log com a operação.
+func respondeErro(w http.ResponseWriter, r *http.Request, span trace.Span, err error, operacao string) {
+	classificacao := erros.Classifica(err)
+
+	span.SetAttributes(
+		semconv.ErrorTypeKey.String(string(classificacao.Tipo)),
+		attribute.Bool("error.retryable", classificacao.Retentavel),
+	)
+	if classificacao.Upstream != "" {
+		span.SetAttributes(attribute.String("error.upstream", classificacao.Upstream))
+	}
+
+	switch classificacao.Tipo {
+	case erros.TipoValidacao, erros.TipoNaoEncontrado:
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		otel.RecordSpanError(span, err)
+		log.Printf("Error %s: %v", operacao, err)
+	}
+
+	i18n.Error(w, r, classificacao.Publico, classificacao.Status)
+}
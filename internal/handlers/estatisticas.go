@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	percentisPadrao  = "50,90,95"
+	percentisMaximos = 10
+)
+
+// periodoEstatisticas é o período padrão e o máximo de cada intervalo, o que limita a série a algumas
+// centenas de baldes.
+var periodoEstatisticas = map[historico.Intervalo]struct{ padrao, maximo time.Duration }{
+	historico.IntervaloHora: {padrao: 24 * time.Hour, maximo: 31 * 24 * time.Hour},
+	historico.IntervaloDia:  {padrao: 30 * 24 * time.Hour, maximo: 366 * 24 * time.Hour},
+}
+
+type EstatisticasTemperatura struct {
+	Unit        string             `json:"unit"`
+	Symbol      string             `json:"symbol"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Avg         float64            `json:"avg"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+type BaldeEstatisticas struct {
+	Start        time.Time                 `json:"start"`
+	End          time.Time                 `json:"end"`
+	Count        int                       `json:"count"`
+	Temperatures []EstatisticasTemperatura `json:"temperatures"`
+}
+
+type SerieEstatisticas struct {
+	Cep      string              `json:"cep,omitempty"`
+	City     string              `json:"city,omitempty"`
+	State    string              `json:"state,omitempty"`
+	Interval string              `json:"interval"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Buckets  []BaldeEstatisticas `json:"buckets"`
+}
+
+// EstatisticasHandler atende GET /historico/estatisticas?cep=|cidade=&uf=&de=&ate=&intervalo=&percentis=,
+// com mínima, máxima, média e percentis das leituras do histórico por hora ou por dia (UTC). Sem período,
+// vale o último dia por hora ou os últimos 30 dias por dia. As temperaturas seguem os parâmetros unidades,
+// precisao e arredondamento.
+func EstatisticasHandler(tracer trace.Tracer, repositorio historico.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "EstatisticasHandler")
+		defer span.End()
+
+		filtro, intervalo, percentis, err := parseEstatisticas(r, time.Now().UTC())
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing history filter")
+			return
+		}
+
+		opcoes, err := parseOpcoesTemperatura(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing temperature options")
+			return
+		}
+
+		cfg := config.Get()
+		padrao, err := opcoesTemperaturaPadrao(cfg.GetTemperaturaArredondamento(), cfg.GetTemperaturaPrecisao())
+		if err != nil {
+			respondeErro(w, r, span, err, "reading rounding configuration")
+			return
+		}
+		opcoes = opcoes.ComPadrao(padrao)
+
+		consultas, err := repositorio.Lista(ctx, filtro)
+		if err != nil {
+			respondeErro(w, r, span, err, "reading history")
+			return
+		}
+
+		resposta := SerieEstatisticas{
+			Cep:      filtro.Cep,
+			City:     filtro.Cidade,
+			State:    strings.ToUpper(filtro.Uf),
+			Interval: string(intervalo),
+			From:     filtro.De,
+			To:       filtro.Ate,
+			Buckets:  []BaldeEstatisticas{},
+		}
+		if len(consultas) > 0 {
+			resposta.City, resposta.State = consultas[0].Cidade, consultas[0].Uf
+		}
+		for _, estatistica := range historico.Agrega(consultas, intervalo, percentis) {
+			resposta.Buckets = append(resposta.Buckets, baldeEstatisticas(estatistica, percentis, opcoes))
+		}
+
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, resposta); err != nil {
+			respondeErro(w, r, span, err, "encoding statistics")
+		}
+	}
+}
+
+// baldeEstatisticas converte as estatísticas, calculadas em Celsius, para cada unidade pedida. As
+// conversões são lineares, então converter a média ou um percentil equivale a calculá-los na unidade.
+func baldeEstatisticas(estatistica historico.Estatistica, percentis []float64, opcoes domain.OpcoesTemperatura) BaldeEstatisticas {
+	balde := BaldeEstatisticas{
+		Start:        estatistica.Inicio,
+		End:          estatistica.Fim,
+		Count:        estatistica.Leituras,
+		Temperatures: make([]EstatisticasTemperatura, 0, len(opcoes.Unidades)),
+	}
+
+	for _, unidade := range opcoes.Unidades {
+		valor := func(celsius float64) float64 {
+			return domain.NewTemperatura(celsius).Valor(unidade, opcoes)
+		}
+		temperatura := EstatisticasTemperatura{
+			Unit:        unidade.Nome(),
+			Symbol:      unidade.Simbolo(),
+			Min:         valor(estatistica.Minima),
+			Max:         valor(estatistica.Maxima),
+			Avg:         valor(estatistica.Media),
+			Percentiles: make(map[string]float64, len(percentis)),
+		}
+		for i, p := range percentis {
+			temperatura.Percentiles["p"+strconv.FormatFloat(p, 'f', -1, 64)] = valor(estatistica.Percentis[i])
+		}
+		balde.Temperatures = append(balde.Temperatures, temperatura)
+	}
+
+	return balde
+}
+
+func parseEstatisticas(r *http.Request, agora time.Time) (historico.Filtro, historico.Intervalo, []float64, error) {
+	query := r.URL.Query()
+	filtro, err := parseFiltroConsultas(query)
+	if err != nil {
+		return filtro, "", nil, err
+	}
+	if filtro.Cep == "" && filtro.Cidade == "" {
+		return filtro, "", nil, fmt.Errorf("%w: informe cep ou cidade", erros.ErrInvalidHistoryFilter)
+	}
+	// Há cidades com o mesmo nome em estados diferentes, e a série não pode misturar as leituras delas.
+	if filtro.Cep == "" && filtro.Uf == "" {
+		return filtro, "", nil, fmt.Errorf("%w: informe a uf da cidade", erros.ErrInvalidHistoryFilter)
+	}
+
+	intervalo := historico.Intervalo(query.Get("intervalo"))
+	if intervalo == "" {
+		intervalo = historico.IntervaloHora
+	}
+	periodo, ok := periodoEstatisticas[intervalo]
+	if !ok {
+		return filtro, "", nil, fmt.Errorf("%w: intervalo %q, use hora ou dia", erros.ErrInvalidHistoryFilter, intervalo)
+	}
+
+	switch {
+	case filtro.De.IsZero() && filtro.Ate.IsZero():
+		filtro.Ate = agora
+		filtro.De = agora.Add(-periodo.padrao)
+	case filtro.De.IsZero():
+		filtro.De = filtro.Ate.Add(-periodo.padrao)
+	case filtro.Ate.IsZero():
+		filtro.Ate = agora
+	}
+	if filtro.Ate.Sub(filtro.De) > periodo.maximo {
+		return filtro, "", nil, fmt.Errorf("%w: período maior que %d dias para o intervalo %s", erros.ErrInvalidHistoryFilter, int(periodo.maximo.Hours()/24), intervalo)
+	}
+
+	percentis, err := parsePercentis(cmp.Or(query.Get("percentis"), percentisPadrao))
+	if err != nil {
+		return filtro, "", nil, err
+	}
+
+	return filtro, intervalo, percentis, nil
+}
+
+// parsePercentis interpreta uma lista separada por vírgulas, como "50,90,99.9", com valores de 0 a 100.
+func parsePercentis(valor string) ([]float64, error) {
+	partes := strings.Split(valor, ",")
+	if len(partes) > percentisMaximos {
+		return nil, fmt.Errorf("%w: use no máximo %d percentis", erros.ErrInvalidHistoryFilter, percentisMaximos)
+	}
+
+	percentis := make([]float64, 0, len(partes))
+	for _, parte := range partes {
+		p, err := strconv.ParseFloat(strings.TrimSpace(parte), 64)
+		if err != nil || !(p >= 0 && p <= 100) {
+			return nil, fmt.Errorf("%w: percentil %q, use de 0 a 100", erros.ErrInvalidHistoryFilter, parte)
+		}
+		percentis = append(percentis, p)
+	}
+
+	return percentis, nil
+}
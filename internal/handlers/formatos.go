@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var formatosResposta = formatos.NewRegistryPadrao().Alias("json", MediaTypeTemperaturasV2)
+
+func negociaFormato(w http.ResponseWriter, r *http.Request, span trace.Span) (formatos.Encoder, string, bool) {
+	http.Header.Add(w.Header(), "Vary", "Accept")
+
+	encoder, contentType, err := formatosResposta.Negocia(r)
+	if err != nil {
+		respondeErro(w, r, span, err, "negotiating response format")
+		return nil, "", false
+	}
+
+	return encoder, contentType, true
+}
+
+// escreveResposta serializa a resposta antes de escrever o status, permitindo responder 500 em caso de falha.
+func escreveResposta(w http.ResponseWriter, encoder formatos.Encoder, contentType string, status int, dados any) error {
+	var buffer bytes.Buffer
+	if err := encoder.Encode(&buffer, dados); err != nil {
+		return err
+	}
+
+	http.Header.Set(w.Header(), "Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err := buffer.WriteTo(w)
+	return err
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/grupos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/cliente"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tamanhoMaximoGrupo = 64 << 10
+
+// nomeGrupo aceita letras minúsculas, dígitos, hífen e sublinhado, como em "armazens-sp".
+var nomeGrupo = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type GrupoInput struct {
+	Ceps []string `json:"ceps"`
+}
+
+type GrupoOutput struct {
+	Name      string    `json:"name"`
+	Ceps      []string  `json:"ceps"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SalvaGrupoHandler atende PUT /grupos/{nome}, criando o grupo do cliente (201) ou substituindo seus CEPs
+// (200).
+func SalvaGrupoHandler(tracer trace.Tracer, repositorio grupos.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "SalvaGrupoHandler")
+		defer span.End()
+
+		nome, err := leNomeGrupo(r)
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+		ceps, err := leCepsGrupo(w, r)
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+
+		agora := time.Now().UTC()
+		grupo := grupos.Grupo{Cliente: clienteDaRequisicao(ctx), Nome: nome, Ceps: ceps, CriadoEm: agora, AtualizadoEm: agora}
+		status := http.StatusCreated
+		atual, err := repositorio.Busca(ctx, grupo.Cliente, nome)
+		switch {
+		case err == nil:
+			grupo.CriadoEm = atual.CriadoEm
+			status = http.StatusOK
+		case !errors.Is(err, erros.ErrGroupNotFound):
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+
+		if err := repositorio.Salva(ctx, grupo); err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+
+		if status == http.StatusCreated {
+			w.Header().Set("Location", "/grupos/"+nome)
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", status, grupoOutput(grupo)); err != nil {
+			respondeErroGrupo(w, r, span, err)
+		}
+	}
+}
+
+func ListaGruposHandler(tracer trace.Tracer, repositorio grupos.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "ListaGruposHandler")
+		defer span.End()
+
+		lista, err := repositorio.Lista(ctx, clienteDaRequisicao(ctx))
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+
+		resposta := make([]GrupoOutput, 0, len(lista))
+		for _, grupo := range lista {
+			resposta = append(resposta, grupoOutput(grupo))
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, resposta); err != nil {
+			respondeErroGrupo(w, r, span, err)
+		}
+	}
+}
+
+func BuscaGrupoHandler(tracer trace.Tracer, repositorio grupos.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "BuscaGrupoHandler")
+		defer span.End()
+
+		grupo, err := repositorio.Busca(ctx, clienteDaRequisicao(ctx), r.PathValue("nome"))
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, grupoOutput(grupo)); err != nil {
+			respondeErroGrupo(w, r, span, err)
+		}
+	}
+}
+
+func RemoveGrupoHandler(tracer trace.Tracer, repositorio grupos.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "RemoveGrupoHandler")
+		defer span.End()
+
+		if err := repositorio.Remove(ctx, clienteDaRequisicao(ctx), r.PathValue("nome")); err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// TemperaturasGrupoHandler atende GET /grupos/{nome}/temperaturas com a leitura atual de cada CEP do
+// grupo. As leituras são compartilhadas entre todos os grupos e clientes, então um CEP presente em vários
+// grupos é consultado no Serviço B uma vez a cada grupos.cache.
+func TemperaturasGrupoHandler(tracer trace.Tracer, repositorio grupos.Repositorio, leituras *usecases.LeiturasCompartilhadas) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "TemperaturasGrupoHandler")
+		defer span.End()
+
+		opcoes, err := parseOpcoesTemperatura(r)
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+
+		encoder, contentType, ok := negociaFormato(w, r, span)
+		if !ok {
+			return
+		}
+
+		grupo, err := repositorio.Busca(ctx, clienteDaRequisicao(ctx), r.PathValue("nome"))
+		if err != nil {
+			respondeErroGrupo(w, r, span, err)
+			return
+		}
+		span.SetAttributes(attribute.String("grupo.nome", grupo.Nome), attribute.Int("grupo.ceps", len(grupo.Ceps)))
+
+		cfg := config.Get()
+		service := usecases.NewProcessaTemperaturasService(clients.NewCalculaTemperaturasClient(tracer, cfg.GetServicoB()))
+		resposta := usecases.NewConsultaGrupoUseCase(service, leituras, cfg.GetGrupos().Cache).Execute(ctx, grupo.Nome, grupo.Ceps, opcoes)
+
+		falhas := 0
+		for _, leitura := range resposta.Readings {
+			if leitura.Error != "" {
+				falhas++
+			}
+		}
+		span.SetAttributes(attribute.Int("grupo.falhas", falhas))
+
+		// Em CSV, as leituras são exportadas como lista, com uma linha por temperatura ou por CEP com erro.
+		var dados any = resposta
+		if _, csv := encoder.(formatos.CSVEncoder); csv {
+			dados = resposta.Readings
+		}
+		if err := escreveResposta(w, encoder, contentType, http.StatusOK, dados); err != nil {
+			respondeErroGrupo(w, r, span, err)
+		}
+	}
+}
+
+// clienteDaRequisicao separa os grupos e as assinaturas por cliente da API. Sem autenticação, todos
+// compartilham o cliente vazio, mesmo que a requisição traga um cliente no baggage.
+func clienteDaRequisicao(ctx context.Context) string {
+	if !config.Get().GetAutenticacao().Habilitada {
+		return ""
+	}
+	return cliente.DoContexto(ctx)
+}
+
+func leNomeGrupo(r *http.Request) (string, error) {
+	nome := r.PathValue("nome")
+	if !nomeGrupo.MatchString(nome) {
+		return "", fmt.Errorf("%w: nome %q, use até 64 letras minúsculas, dígitos, - ou _", erros.ErrInvalidGroup, nome)
+	}
+	return nome, nil
+}
+
+// leCepsGrupo normaliza os CEPs e remove os repetidos, mantendo a ordem em que foram informados.
+func leCepsGrupo(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	input := GrupoInput{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, tamanhoMaximoGrupo))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("%w: %v", erros.ErrInvalidGroup, err)
+	}
+
+	ceps := []string{}
+	for _, valor := range input.Ceps {
+		cep, err := domain.NewCep(valor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, valor)
+		}
+		if !slices.Contains(ceps, cep.Codigo()) {
+			ceps = append(ceps, cep.Codigo())
+		}
+	}
+
+	maximo := config.Get().GetGrupos().MaxCeps
+	if len(ceps) == 0 || len(ceps) > maximo {
+		return nil, fmt.Errorf("%w: informe de 1 a %d CEPs", erros.ErrInvalidGroup, maximo)
+	}
+	return ceps, nil
+}
+
+func grupoOutput(grupo grupos.Grupo) GrupoOutput {
+	return GrupoOutput{
+		Name:      grupo.Nome,
+		Ceps:      grupo.Ceps,
+		CreatedAt: grupo.CriadoEm,
+		UpdatedAt: grupo.AtualizadoEm,
+	}
+}
+
+func respondeErroGrupo(w http.ResponseWriter, r *http.Request, span trace.Span, err error) {
+	respondeErro(w, r, span, err, "handling location group")
+}
@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
-	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/service"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
@@ -18,54 +23,100 @@ import (
 )
 
 func CapturaTemperaturasHandler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return capturaTemperaturas(tracer, negociaVersao, leCepDoBody)
+}
+
+func CapturaTemperaturasV2Handler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return capturaTemperaturas(tracer, fixaVersaoV2, leCepDoBody)
+}
+
+// ConsultaTemperaturasHandler atende GET /temperaturas/{cep} e GET /temperaturas?cep=, com suporte a ETag.
+func ConsultaTemperaturasHandler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return capturaTemperaturas(tracer, negociaVersao, leCepDaUrl)
+}
+
+func ConsultaTemperaturasV2Handler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+	return capturaTemperaturas(tracer, fixaVersaoV2, leCepDaUrl)
+}
+
+type leitorCep func(r *http.Request) (usecases.DadosCepInput, error)
+
+func leCepDoBody(r *http.Request) (usecases.DadosCepInput, error) {
+	dadosInput := usecases.DadosCepInput{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return dadosInput, fmt.Errorf("%w: %w", erros.ErrInvalidRequestBody, err)
+	}
+
+	json.Unmarshal(body, &dadosInput)
+
+	return dadosInput, nil
+}
+
+func leCepDaUrl(r *http.Request) (usecases.DadosCepInput, error) {
+	cep := r.PathValue("cep")
+	if cep == "" {
+		cep = r.URL.Query().Get("cep")
+	}
+
+	return usecases.DadosCepInput{Cep: cep}, nil
+}
+
+func capturaTemperaturas(tracer trace.Tracer, negociador negociadorVersao, leitor leitorCep) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, span := otel.StartSpan(r.Context(), tracer, "CapturaTemperaturasHandler")
+		ctx, span := otel.StartSpan(r.Context(), tracer, "CapturaTemperaturasHandler")
+		defer span.End()
 		otel.AddSpanEvent(span, "Recebendo requisição de CEP", nil)
 
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		encoder, contentType, ok := negociaFormato(w, r, span)
+		if !ok {
 			return
 		}
 
-		dadosInput := usecases.DadosCepInput{}
+		dadosInput, err := leitor(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "reading CEP")
+			return
+		}
 
-		json.Unmarshal(body, &dadosInput)
+		dadosInput.Opcoes, err = parseOpcoesTemperatura(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing temperature options")
+			return
+		}
 
-		if !helpers.ValidateZipCode(dadosInput.Cep) {
-			span.SetStatus(codes.Error, erros.ErrInvalidZipCode.Error())
-			http.Error(w, erros.ErrInvalidZipCode.Error(), http.StatusUnprocessableEntity)
+		if _, err := domain.NewCep(dadosInput.Cep); err != nil {
+			respondeErro(w, r, span, erros.ErrInvalidZipCode, "validating CEP")
 			return
 		}
 
 		otel.AddSpanEvent(span, "Cep validado, encaminhando para o Serviço B", map[string]interface{}{"cep": dadosInput.Cep})
-		calculaTemperaturasClient := clients.NewCalculaTemperaturasClient(tracer)
+		calculaTemperaturasClient := clients.NewCalculaTemperaturasClient(tracer, config.Get().GetServicoB())
 		service := usecases.NewProcessaTemperaturasService(calculaTemperaturasClient)
 
-		dados, err := service.Execute(dadosInput)
+		versao := negociador(r)
+
+		dados, observedAt, err := executaProcessaTemperaturas(ctx, service, versao, dadosInput)
 		if err != nil {
-			if errors.Is(err, erros.ErrInvalidZipCode) {
-				span.SetStatus(codes.Error, err.Error())
-				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
-				return
-			}
+			respondeErro(w, r, span, err, "capturing temperatures for CEP "+dadosInput.Cep)
+			return
+		}
+
+		if r.Method == http.MethodGet && !observedAt.IsZero() {
+			etag := calculaETag(dadosInput, versao, contentType, i18n.DoContexto(ctx), observedAt)
+			defineCabecalhosCache(w, etag, observedAt)
 
-			if errors.Is(err, erros.ErrZipCodeNotFound) {
-				span.SetStatus(codes.Error, err.Error())
-				http.Error(w, err.Error(), http.StatusNotFound)
+			if naoModificado(r, etag) {
+				otel.AddSpanEvent(span, "Leitura não modificada", map[string]interface{}{"etag": etag})
+				span.SetStatus(codes.Ok, "Requisição processada com sucesso")
+				w.WriteHeader(http.StatusNotModified)
 				return
 			}
-
-			otel.RecordSpanError(span, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
 		}
 
-		http.Header.Set(w.Header(), "Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(dados); err != nil {
-			otel.RecordSpanError(span, err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			log.Printf("Error encoding response for CEP %s: %v", dadosInput.Cep, err)
+		if err := escreveResposta(w, encoder, contentType, http.StatusOK, dados); err != nil {
+			respondeErro(w, r, span, err, "encoding response for CEP "+dadosInput.Cep)
 			return
 		}
 
@@ -73,49 +124,163 @@ func CapturaTemperaturasHandler(tracer trace.Tracer) func(w http.ResponseWriter,
 	}
 }
 
-func ProcessaTemperaturasHandler(tracer trace.Tracer) func(w http.ResponseWriter, r *http.Request) {
+func executaProcessaTemperaturas(ctx context.Context, service *usecases.ProcessaTemperaturasService, versao versaoApi, dadosInput usecases.DadosCepInput) (any, time.Time, error) {
+	if versao == versaoV2 {
+		dados, err := service.ExecuteV2(ctx, dadosInput)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return dados, dados.ObservedAt, nil
+	}
+
+	dados, err := service.Execute(ctx, dadosInput)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return dados, dados.ObservedAt, nil
+}
+
+// novoWeatherClient cria o client do provedor configurado em clima.provedor, que pode mudar em uma recarga.
+func novoWeatherClient(tracer trace.Tracer) clients.WeatherClient {
+	cfg := config.Get()
+	if cfg.GetClima().Provedor == config.ProvedorOpenMeteo {
+		return clients.NewOpenMeteoClient(tracer, cfg.GetOpenMeteo(), cfg.GetOpenMeteoGeocoding())
+	}
+
+	weatherApi := clients.NewWeatherApiClient(tracer, cfg.GetWeatherApi(), cfg.GetWeatherApiKeys(), cfg.GetClima())
+	if cfg.GetClima().Cota.Politica == config.PoliticaAlternativo {
+		return clients.NewWeatherClientComAlternativo(tracer, weatherApi, clients.NewOpenMeteoClient(tracer, cfg.GetOpenMeteo(), cfg.GetOpenMeteoGeocoding()))
+	}
+
+	return weatherApi
+}
+
+// NovoTemperaturasService retorna uma fábrica do serviço do Serviço B com os clients da configuração
+// atual, usada a cada requisição e a cada rodada do amostrador.
+func NovoTemperaturasService(tracer trace.Tracer) func() *service.TemperaturasService {
+	return func() *service.TemperaturasService {
+		cfg := config.Get()
+		cepUseCase := usecases.NewConsultaCepUseCase(clients.NewViaCepClient(tracer, cfg.GetViaCep()))
+		calculaTemperaturasUseCase := usecases.NewCalculaTemperaturasUseCase(novoWeatherClient(tracer))
+
+		return service.NewTemperaturasService(cepUseCase, calculaTemperaturasUseCase)
+	}
+}
+
+// ProcessaTemperaturasHandler entrega cada consulta atendida ao registrador do histórico, que a grava fora
+// da requisição.
+func ProcessaTemperaturasHandler(tracer trace.Tracer, registrador historico.Registrador) func(w http.ResponseWriter, r *http.Request) {
+	return processaTemperaturas(tracer, negociaVersao, registrador, temperaturasDoCep)
+}
+
+func ProcessaTemperaturasV2Handler(tracer trace.Tracer, registrador historico.Registrador) func(w http.ResponseWriter, r *http.Request) {
+	return processaTemperaturas(tracer, fixaVersaoV2, registrador, temperaturasDoCep)
+}
+
+// ProcessaTemperaturasCidadeHandler atende GET /cidades/{uf}/{cidade}/temperaturas, sem a consulta do CEP.
+// As leituras entram no histórico sem CEP.
+func ProcessaTemperaturasCidadeHandler(tracer trace.Tracer, registrador historico.Registrador) func(w http.ResponseWriter, r *http.Request) {
+	return processaTemperaturas(tracer, negociaVersao, registrador, temperaturasDaCidade)
+}
+
+func ProcessaTemperaturasCidadeV2Handler(tracer trace.Tracer, registrador historico.Registrador) func(w http.ResponseWriter, r *http.Request) {
+	return processaTemperaturas(tracer, fixaVersaoV2, registrador, temperaturasDaCidade)
+}
+
+// consultaTemperaturas obtém as temperaturas do local da requisição e diz como ele aparece nos logs.
+type consultaTemperaturas func(ctx context.Context, servico *service.TemperaturasService, r *http.Request) (*usecases.DadosTemperaturas, string, error)
+
+func temperaturasDoCep(ctx context.Context, servico *service.TemperaturasService, r *http.Request) (*usecases.DadosTemperaturas, string, error) {
+	cep := r.PathValue("cep")
+	dados, err := servico.Processa(ctx, cep)
+	return dados, "CEP " + cep, err
+}
+
+func temperaturasDaCidade(ctx context.Context, servico *service.TemperaturasService, r *http.Request) (*usecases.DadosTemperaturas, string, error) {
+	uf, cidade := r.PathValue("uf"), r.PathValue("cidade")
+	dados, err := servico.ProcessaCidade(ctx, uf, cidade)
+	return dados, "city " + cidade + "/" + uf, err
+}
+
+func processaTemperaturas(tracer trace.Tracer, negociador negociadorVersao, registrador historico.Registrador, consulta consultaTemperaturas) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, span := otel.StartSpan(r.Context(), tracer, "ProcessaTemperaturasHandler")
+		ctx, span := otel.StartSpan(r.Context(), tracer, "ProcessaTemperaturasHandler")
+		defer span.End()
 
 		otel.AddSpanEvent(span, "Recebendo requisição de processamendo do clima", nil)
 
-		cepPathValue := r.PathValue("cep")
-		viaCepClient := clients.NewViaCepClient(tracer)
-		cepUseCase := usecases.NewConsultaCepUseCase(viaCepClient)
+		encoder, contentType, ok := negociaFormato(w, r, span)
+		if !ok {
+			return
+		}
+
+		opcoes, err := parseOpcoesTemperatura(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing temperature options")
+			return
+		}
 
-		weatherApiClient := clients.NewWeatherApiClient(tracer)
-		calculaTemperaturasUseCase := usecases.NewCalculaTemperaturasUseCase(weatherApiClient)
+		incluiEndereco, err := parseIncluir(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing include option")
+			return
+		}
 
-		temperaturasService := service.NewTemperaturasService(cepUseCase, calculaTemperaturasUseCase)
-		dadosTemperaturas, err := temperaturasService.Processa(cepPathValue)
+		cfg := config.Get()
+
+		padrao, err := opcoesTemperaturaPadrao(cfg.GetTemperaturaArredondamento(), cfg.GetTemperaturaPrecisao())
 		if err != nil {
-			if errors.Is(err, erros.ErrInvalidZipCode) || errors.Is(err, erros.ErrCityIsRequired) {
-				span.SetStatus(codes.Error, err.Error())
-				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
-				return
-			}
-			if errors.Is(err, erros.ErrZipCodeNotFound) || errors.Is(err, erros.ErrCityNotFound) {
-				span.SetStatus(codes.Error, err.Error())
-				http.Error(w, err.Error(), http.StatusNotFound)
-				return
-			}
+			respondeErro(w, r, span, err, "reading rounding configuration")
+			return
+		}
+		opcoes = opcoes.ComPadrao(padrao)
 
-			span.SetStatus(codes.Error, err.Error())
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			log.Printf("Error processing temperatures for CEP %s: %v", cepPathValue, err)
+		dadosTemperaturas, local, err := consulta(ctx, NovoTemperaturasService(tracer)(), r)
+		if err != nil {
+			respondeErro(w, r, span, err, "processing temperatures for "+local)
 			return
 		}
 
 		span.SetStatus(codes.Ok, "Calculo das temperaturas realizada com sucesso")
 
-		http.Header.Set(w.Header(), "Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(dadosTemperaturas); err != nil {
-			otel.RecordSpanError(span, err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			log.Printf("Error encoding response for CEP %s: %v", cepPathValue, err)
+		versao := negociador(r)
+
+		var endereco *usecases.Endereco
+		if incluiEndereco {
+			endereco = dadosTemperaturas.Address
+		}
+
+		var resposta any
+		if versao == versaoV2 {
+			v2 := dadosTemperaturas.V2(opcoes)
+			v2.Address = endereco
+			resposta = v2
+		} else {
+			v1 := dadosTemperaturas.Formata(opcoes)
+			v1.Address = endereco
+			resposta = v1
+		}
+
+		if !dadosTemperaturas.ObservedAt.IsZero() {
+			http.Header.Set(w.Header(), "Last-Modified", dadosTemperaturas.ObservedAt.UTC().Format(http.TimeFormat))
+		}
+
+		if err := escreveResposta(w, encoder, contentType, http.StatusOK, resposta); err != nil {
+			respondeErro(w, r, span, err, "encoding response for "+local)
 			return
 		}
 
-		log.Printf("Successfully processed temperatures for CEP %s", cepPathValue)
+		registrador.Registra(historico.Consulta{
+			Cep:          dadosTemperaturas.Cep,
+			Cidade:       dadosTemperaturas.City,
+			Uf:           dadosTemperaturas.Uf,
+			Celsius:      dadosTemperaturas.Temperatura.Celsius(),
+			Provedor:     dadosTemperaturas.Provedor,
+			TraceID:      traceID(span),
+			ObservadaEm:  dadosTemperaturas.ObservedAt,
+			ConsultadaEm: time.Now().UTC(),
+		})
+
+		log.Printf("Successfully processed temperatures for %s", local)
 	}
 }
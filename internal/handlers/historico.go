@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/formatos"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tamanhoPaginaPadrao = 20
+	tamanhoPaginaMaximo = 100
+)
+
+type ConsultaHistorico struct {
+	Cep          string                   `json:"cep"`
+	City         string                   `json:"city"`
+	State        string                   `json:"state"`
+	Temperatures []usecases.TemperaturaV2 `json:"temperatures"`
+	Provider     string                   `json:"provider"`
+	TraceID      string                   `json:"trace_id,omitempty"`
+	ObservedAt   time.Time                `json:"observed_at"`
+	RequestedAt  time.Time                `json:"requested_at"`
+}
+
+type PaginaHistorico struct {
+	Items    []ConsultaHistorico `json:"items"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	Total    int                 `json:"total"`
+}
+
+// HistoricoHandler atende GET /historico?cep=&cidade=&uf=&de=&ate=&pagina=&tamanho=, com as consultas mais
+// recentes primeiro. de e ate aceitam uma data (2025-06-10) ou um instante RFC 3339; como data, ate inclui
+// o dia inteiro. As temperaturas seguem os parâmetros unidades, precisao e arredondamento.
+func HistoricoHandler(tracer trace.Tracer, repositorio historico.Repositorio) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := otel.StartSpan(r.Context(), tracer, "HistoricoHandler")
+		defer span.End()
+
+		filtro, err := parseFiltroHistorico(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing history filter")
+			return
+		}
+
+		opcoes, err := parseOpcoesTemperatura(r)
+		if err != nil {
+			respondeErro(w, r, span, err, "parsing temperature options")
+			return
+		}
+
+		cfg := config.Get()
+		padrao, err := opcoesTemperaturaPadrao(cfg.GetTemperaturaArredondamento(), cfg.GetTemperaturaPrecisao())
+		if err != nil {
+			respondeErro(w, r, span, err, "reading rounding configuration")
+			return
+		}
+		opcoes = opcoes.ComPadrao(padrao)
+
+		pagina, err := repositorio.Busca(ctx, filtro)
+		if err != nil {
+			respondeErro(w, r, span, err, "reading history")
+			return
+		}
+
+		resposta := PaginaHistorico{
+			Items:    make([]ConsultaHistorico, 0, len(pagina.Consultas)),
+			Page:     filtro.Pagina,
+			PageSize: filtro.Tamanho,
+			Total:    pagina.Total,
+		}
+		for _, consulta := range pagina.Consultas {
+			dados := &usecases.DadosTemperaturas{Temperatura: domain.NewTemperatura(consulta.Celsius)}
+			resposta.Items = append(resposta.Items, ConsultaHistorico{
+				Cep:          consulta.Cep,
+				City:         consulta.Cidade,
+				State:        consulta.Uf,
+				Temperatures: dados.V2(opcoes).Temperatures,
+				Provider:     consulta.Provedor,
+				TraceID:      consulta.TraceID,
+				ObservedAt:   consulta.ObservadaEm,
+				RequestedAt:  consulta.ConsultadaEm,
+			})
+		}
+
+		if err := escreveResposta(w, formatos.JSONEncoder{}, "application/json", http.StatusOK, resposta); err != nil {
+			respondeErro(w, r, span, err, "encoding history")
+		}
+	}
+}
+
+func parseFiltroHistorico(r *http.Request) (historico.Filtro, error) {
+	query := r.URL.Query()
+	filtro, err := parseFiltroConsultas(query)
+	if err != nil {
+		return filtro, err
+	}
+	filtro.Pagina, filtro.Tamanho = 1, tamanhoPaginaPadrao
+
+	if valor := query.Get("pagina"); valor != "" {
+		if filtro.Pagina, err = strconv.Atoi(valor); err != nil || filtro.Pagina < 1 {
+			return filtro, fmt.Errorf("%w: pagina %q", erros.ErrInvalidHistoryFilter, valor)
+		}
+	}
+	if valor := query.Get("tamanho"); valor != "" {
+		if filtro.Tamanho, err = strconv.Atoi(valor); err != nil || filtro.Tamanho < 1 || filtro.Tamanho > tamanhoPaginaMaximo {
+			return filtro, fmt.Errorf("%w: tamanho %q, use de 1 a %d", erros.ErrInvalidHistoryFilter, valor, tamanhoPaginaMaximo)
+		}
+	}
+
+	return filtro, nil
+}
+
+// parseFiltroConsultas lê os parâmetros cep, cidade, uf, de e ate, comuns ao histórico e às estatísticas.
+func parseFiltroConsultas(query url.Values) (historico.Filtro, error) {
+	filtro := historico.Filtro{
+		Cidade: strings.TrimSpace(query.Get("cidade")),
+		Uf:     strings.TrimSpace(query.Get("uf")),
+	}
+
+	if valor := query.Get("cep"); valor != "" {
+		cep, err := domain.NewCep(valor)
+		if err != nil {
+			return filtro, err
+		}
+		filtro.Cep = cep.Codigo()
+	}
+	if filtro.Uf != "" && len(filtro.Uf) != 2 {
+		return filtro, fmt.Errorf("%w: uf %q", erros.ErrInvalidHistoryFilter, filtro.Uf)
+	}
+
+	var err error
+	if filtro.De, err = parseInstante(query.Get("de"), false); err != nil {
+		return filtro, fmt.Errorf("%w: de %q", erros.ErrInvalidHistoryFilter, query.Get("de"))
+	}
+	if filtro.Ate, err = parseInstante(query.Get("ate"), true); err != nil {
+		return filtro, fmt.Errorf("%w: ate %q", erros.ErrInvalidHistoryFilter, query.Get("ate"))
+	}
+	if !filtro.De.IsZero() && !filtro.Ate.IsZero() && filtro.Ate.Before(filtro.De) {
+		return filtro, fmt.Errorf("%w: ate antes de de", erros.ErrInvalidHistoryFilter)
+	}
+
+	return filtro, nil
+}
+
+// parseInstante aceita uma data ou um instante RFC 3339. Uma data no fim do período vale até o último
+// instante do dia (UTC).
+func parseInstante(valor string, fimDoPeriodo bool) (time.Time, error) {
+	if valor == "" {
+		return time.Time{}, nil
+	}
+	if instante, err := time.Parse(time.RFC3339, valor); err == nil {
+		return instante.UTC(), nil
+	}
+
+	dia, err := time.Parse(time.DateOnly, valor)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if fimDoPeriodo {
+		return dia.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return dia, nil
+}
+
+func traceID(span trace.Span) string {
+	if !span.SpanContext().HasTraceID() {
+		return ""
+	}
+	return span.SpanContext().TraceID().String()
+}
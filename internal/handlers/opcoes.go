@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+)
+
+// incluirEndereco é o valor de ?incluir= que acrescenta o endereço do CEP à resposta das temperaturas.
+const incluirEndereco = "endereco"
+
+// parseOpcoesTemperatura lê os parâmetros unidades, precisao e arredondamento da query string.
+// Parâmetros ausentes ficam vazios para que o padrão seja aplicado por quem calcula as temperaturas.
+func parseOpcoesTemperatura(r *http.Request) (opcoes domain.OpcoesTemperatura, err error) {
+	query := r.URL.Query()
+
+	if valor := query.Get("unidades"); valor != "" {
+		if opcoes.Unidades, err = domain.ParseUnidades(valor); err != nil {
+			return opcoes, err
+		}
+	}
+
+	if valor := query.Get("arredondamento"); valor != "" {
+		if opcoes.Arredondamento, err = domain.ParseModoArredondamento(valor); err != nil {
+			return opcoes, err
+		}
+	}
+
+	if valor := query.Get("precisao"); valor != "" {
+		precisao, err := domain.ParsePrecisao(valor)
+		if err != nil {
+			return opcoes, err
+		}
+		opcoes.Precisao = &precisao
+	}
+
+	return opcoes, nil
+}
+
+// opcoesTemperaturaPadrao aplica ao padrão do domínio o arredondamento e a precisão configurados no Serviço B.
+// Uma configuração inválida é um erro interno, e não de validação da requisição.
+func opcoesTemperaturaPadrao(arredondamento, precisao string) (domain.OpcoesTemperatura, error) {
+	padrao := domain.OpcoesTemperatura{}
+
+	if arredondamento != "" {
+		modo, err := domain.ParseModoArredondamento(arredondamento)
+		if err != nil {
+			return padrao, erros.NewErro(erros.TipoInterno, err)
+		}
+		padrao.Arredondamento = modo
+	}
+
+	if precisao != "" {
+		valor, err := domain.ParsePrecisao(precisao)
+		if err != nil {
+			return padrao, erros.NewErro(erros.TipoInterno, err)
+		}
+		padrao.Precisao = &valor
+	}
+
+	return padrao.ComPadrao(domain.OpcoesPadrao), nil
+}
+
+// parseIncluir lê o parâmetro incluir, uma lista separada por vírgulas dos blocos opcionais da resposta.
+// Por enquanto o único bloco é endereco.
+func parseIncluir(r *http.Request) (endereco bool, err error) {
+	for _, valores := range r.URL.Query()["incluir"] {
+		for valor := range strings.SplitSeq(valores, ",") {
+			switch strings.TrimSpace(valor) {
+			case incluirEndereco:
+				endereco = true
+			default:
+				return false, fmt.Errorf("%w: %q, use %s", erros.ErrInvalidInclude, valor, incluirEndereco)
+			}
+		}
+	}
+	return endereco, nil
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/api"
+)
+
+func OpenAPIHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Header.Set(w.Header(), "Content-Type", "application/json")
+		w.Write(api.OpenAPI)
+	}
+}
+
+func DocsHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Header.Set(w.Header(), "Content-Type", "text/html; charset=utf-8")
+		w.Write(api.Docs)
+	}
+}
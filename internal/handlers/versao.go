@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MediaTypeTemperaturasV2 permite solicitar o modelo v2 nas rotas sem versão através do header Accept.
+const MediaTypeTemperaturasV2 = "application/vnd.temperaturas.v2+json"
+
+type versaoApi int
+
+const (
+	versaoV1 versaoApi = iota + 1
+	versaoV2
+)
+
+type negociadorVersao func(r *http.Request) versaoApi
+
+func negociaVersao(r *http.Request) versaoApi {
+	for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), MediaTypeTemperaturasV2) {
+			return versaoV2
+		}
+	}
+
+	return versaoV1
+}
+
+func fixaVersaoV2(r *http.Request) versaoApi {
+	return versaoV2
+}
@@ -1,14 +1,17 @@
 package helpers
 
 import (
-	"math"
+	"regexp"
 	"strings"
-	"unicode"
 )
 
+// formatoZipCode aceita só os formatos claros de CEP: 05791120, 05791-120 e 05.791-120.
+var formatoZipCode = regexp.MustCompile(`^(\d{8}|\d{5}-\d{3}|\d{2}\.\d{3}-\d{3})$`)
+
+// NormalizeZipCode mantém só os dígitos ASCII, para que o resultado possa ir direto na URL dos upstreams.
 func NormalizeZipCode(zipcode string) string {
 	return strings.Map(func(r rune) rune {
-		if unicode.IsDigit(r) {
+		if r >= '0' && r <= '9' {
 			return r
 		}
 
@@ -16,16 +19,19 @@ func NormalizeZipCode(zipcode string) string {
 	}, zipcode)
 }
 
+// ValidateZipCode verifica o formato, ignorando espaços nas pontas; "abc0579-1120xyz" não é um CEP.
 func ValidateZipCode(zipcode string) bool {
-	normalized := NormalizeZipCode(zipcode)
-	
-	return len(normalized) == 8
+	return formatoZipCode.MatchString(strings.TrimSpace(zipcode))
 }
 
 func CelsiusToFahrenheit(celsius float64) float64 {
-	return math.Ceil((celsius * 9 / 5) + 32)
+	return (celsius * 9 / 5) + 32
 }
 
 func CelsiusToKelvin(celsius float64) float64 {
-	return math.Ceil(celsius + 273.15)
+	return celsius + 273.15
+}
+
+func CelsiusToRankine(celsius float64) float64 {
+	return (celsius + 273.15) * 9 / 5
 }
@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var sementesZipCode = []string{
+	"01001000",
+	"01001-000",
+	"01.001-000",
+	" 05791-120 ",
+	"abc0579-1120xyz",
+	"0100100a",
+	"0579112",
+	"057911200",
+	"05791 120",
+	"٠١٠٠١٠٠٠",
+	"",
+}
+
+func FuzzNormalizeZipCode(f *testing.F) {
+	for _, semente := range sementesZipCode {
+		f.Add(semente)
+	}
+
+	f.Fuzz(func(t *testing.T, zipcode string) {
+		normalizado := NormalizeZipCode(zipcode)
+
+		for _, r := range normalizado {
+			if r < '0' || r > '9' {
+				t.Fatalf("NormalizeZipCode(%q) = %q, com %q fora de 0-9", zipcode, normalizado, r)
+			}
+		}
+		if novamente := NormalizeZipCode(normalizado); novamente != normalizado {
+			t.Fatalf("NormalizeZipCode não é idempotente: %q virou %q", normalizado, novamente)
+		}
+	})
+}
+
+func FuzzValidateZipCode(f *testing.F) {
+	for _, semente := range sementesZipCode {
+		f.Add(semente)
+	}
+
+	f.Fuzz(func(t *testing.T, zipcode string) {
+		if !ValidateZipCode(zipcode) {
+			return
+		}
+
+		normalizado := NormalizeZipCode(zipcode)
+		if len(normalizado) != 8 {
+			t.Fatalf("ValidateZipCode(%q) aceitou um CEP com %d dígitos", zipcode, len(normalizado))
+		}
+		// Além dos dígitos, um CEP válido só tem espaços nas pontas, o ponto e o hífen.
+		if strings.Trim(strings.TrimSpace(zipcode), "0123456789.-") != "" {
+			t.Fatalf("ValidateZipCode(%q) aceitou caracteres além de dígitos, ponto e hífen", zipcode)
+		}
+		if !ValidateZipCode(normalizado) {
+			t.Fatalf("ValidateZipCode recusou %q, a forma normalizada de %q", normalizado, zipcode)
+		}
+	})
+}
+
+type HelpersTestSuite struct {
+	suite.Suite
+}
+
+func TestHelpersSuite(t *testing.T) {
+	suite.Run(t, new(HelpersTestSuite))
+}
+
+func (s *HelpersTestSuite) TestValidateZipCodeAceitaSoFormatosClaros() {
+	cenarios := map[string]bool{
+		"05791120":        true,
+		"05791-120":       true,
+		"05.791-120":      true,
+		" 05791-120\n":    true,
+		"abc0579-1120xyz": false,
+		"0579-1120":       false,
+		"05791 120":       false,
+		"0579112":         false,
+		"٠١٠٠١٠٠٠":        false,
+	}
+
+	for zipcode, esperado := range cenarios {
+		// Act
+		valido := ValidateZipCode(zipcode)
+
+		// Assert
+		s.Equal(esperado, valido, zipcode)
+	}
+}
+
+func (s *HelpersTestSuite) TestNormalizeZipCode() {
+	// Act
+	normalizado := NormalizeZipCode("05.791-120")
+
+	// Assert
+	s.Equal("05791120", normalizado)
+}
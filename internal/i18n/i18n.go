@@ -0,0 +1,219 @@
+// Package i18n escolhe o idioma das respostas pelo Accept-Language e traduz as mensagens de erro pelo
+// código de cada erro do pacote erros.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"golang.org/x/text/language"
+)
+
+// HeaderCodigoErro leva o código do catálogo nas respostas de erro, para que o Serviço A reconstrua o erro do
+// Serviço B sem depender da mensagem traduzida.
+const HeaderCodigoErro = "X-Error-Code"
+
+type Idioma string
+
+const (
+	PortuguesBrasil Idioma = "pt-BR"
+	Ingles          Idioma = "en"
+	Espanhol        Idioma = "es"
+)
+
+// Idiomas são os idiomas do catálogo, na ordem usada pela negociação.
+var Idiomas = []Idioma{PortuguesBrasil, Ingles, Espanhol}
+
+var matcher = language.NewMatcher([]language.Tag{language.BrazilianPortuguese, language.English, language.Spanish})
+
+// ParseIdioma aceita os idiomas do catálogo sem diferenciar maiúsculas, como pt-br.
+func ParseIdioma(valor string) (Idioma, bool) {
+	for _, idioma := range Idiomas {
+		if strings.EqualFold(valor, string(idioma)) {
+			return idioma, true
+		}
+	}
+	return "", false
+}
+
+// Negocia escolhe o idioma do catálogo mais próximo do header Accept-Language, como pt-BR para "pt-PT" ou
+// es para "es-AR;q=0.9". Sem header, com um header inválido ou sem nenhum idioma próximo, fica o padrão.
+func Negocia(acceptLanguage string, padrao Idioma) Idioma {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return padrao
+	}
+
+	_, indice, confianca := matcher.Match(tags...)
+	if confianca == language.No {
+		return padrao
+	}
+	return Idiomas[indice]
+}
+
+// CodigoWeatherApi é o parâmetro lang da WeatherAPI para o idioma. O inglês é o padrão da WeatherAPI e
+// não é enviado.
+func (i Idioma) CodigoWeatherApi() string {
+	switch i {
+	case PortuguesBrasil:
+		return "pt"
+	case Espanhol:
+		return "es"
+	default:
+		return ""
+	}
+}
+
+// CodigoOpenMeteo é o parâmetro language da geocodificação da Open-Meteo, que escreve nele os nomes do
+// estado e do país.
+func (i Idioma) CodigoOpenMeteo() string {
+	switch i {
+	case PortuguesBrasil:
+		return "pt"
+	case Espanhol:
+		return "es"
+	default:
+		return "en"
+	}
+}
+
+type chaveIdioma struct{}
+
+func NovoContexto(ctx context.Context, idioma Idioma) context.Context {
+	return context.WithValue(ctx, chaveIdioma{}, idioma)
+}
+
+// DoContexto devolve o idioma negociado para a requisição, ou inglês fora de uma requisição.
+func DoContexto(ctx context.Context) Idioma {
+	if idioma, ok := ctx.Value(chaveIdioma{}).(Idioma); ok {
+		return idioma
+	}
+	return Ingles
+}
+
+// codigos liga cada erro ao seu código no catálogo.
+var codigos = []struct {
+	erro   error
+	codigo string
+}{
+	{erros.ErrInvalidZipCode, "invalid_zipcode"},
+	{erros.ErrZipCodeNotFound, "zipcode_not_found"},
+	{erros.ErrCityIsRequired, "city_required"},
+	{erros.ErrCityNotFound, "city_not_found"},
+	{erros.ErrInvalidState, "invalid_state"},
+	{erros.ErrInvalidAddressSearch, "invalid_address_search"},
+	{erros.ErrInvalidIbgeCode, "invalid_ibge_code"},
+	{erros.ErrLocationMismatch, "location_mismatch"},
+	{erros.ErrInvalidTemperatureUnit, "invalid_temperature_unit"},
+	{erros.ErrInvalidPrecision, "invalid_precision"},
+	{erros.ErrInvalidRoundingMode, "invalid_rounding_mode"},
+	{erros.ErrInvalidTemperatureOptions, "invalid_temperature_options"},
+	{erros.ErrInvalidInclude, "invalid_include"},
+	{erros.ErrWeatherApiQuotaExceeded, "weather_quota_exceeded"},
+	{erros.ErrUpstreamUnavailable, "upstream_unavailable"},
+	{erros.ErrUpstreamTimeout, "upstream_timeout"},
+	{erros.ErrInvalidApiKey, "invalid_api_key"},
+	{erros.ErrRateLimitExceeded, "rate_limit_exceeded"},
+	{erros.ErrDailyQuotaExceeded, "daily_quota_exceeded"},
+	{erros.ErrRequestTimeout, "request_timeout"},
+	{erros.ErrInvalidHistoryFilter, "invalid_history_filter"},
+	{erros.ErrInvalidSubscription, "invalid_subscription"},
+	{erros.ErrSubscriptionNotFound, "subscription_not_found"},
+	{erros.ErrWebhookRejected, "webhook_rejected"},
+	{erros.ErrInvalidGroup, "invalid_group"},
+	{erros.ErrGroupNotFound, "group_not_found"},
+	{erros.ErrInvalidRequestBody, "invalid_request_body"},
+	{erros.ErrNotAcceptable, "unsupported_format"},
+	{erros.ErrInternal, "internal_error"},
+}
+
+// catalogo traz as mensagens de cada código. A mensagem em inglês é a do próprio erro.
+var catalogo = map[string]map[Idioma]string{
+	"invalid_zipcode":             {PortuguesBrasil: "CEP inválido", Espanhol: "código postal inválido"},
+	"zipcode_not_found":           {PortuguesBrasil: "CEP não encontrado", Espanhol: "código postal no encontrado"},
+	"city_required":               {PortuguesBrasil: "cidade é obrigatória", Espanhol: "la ciudad es obligatoria"},
+	"city_not_found":              {PortuguesBrasil: "cidade não encontrada", Espanhol: "ciudad no encontrada"},
+	"invalid_state":               {PortuguesBrasil: "UF inválida", Espanhol: "estado inválido"},
+	"invalid_address_search":      {PortuguesBrasil: "pesquisa de endereço inválida", Espanhol: "búsqueda de dirección inválida"},
+	"invalid_ibge_code":           {PortuguesBrasil: "código IBGE inválido", Espanhol: "código IBGE inválido"},
+	"location_mismatch":           {PortuguesBrasil: "a localidade do clima não corresponde à cidade", Espanhol: "la ubicación del clima no corresponde a la ciudad"},
+	"invalid_temperature_unit":    {PortuguesBrasil: "unidade de temperatura inválida", Espanhol: "unidad de temperatura inválida"},
+	"invalid_precision":           {PortuguesBrasil: "precisão inválida", Espanhol: "precisión inválida"},
+	"invalid_rounding_mode":       {PortuguesBrasil: "modo de arredondamento inválido", Espanhol: "modo de redondeo inválido"},
+	"invalid_temperature_options": {PortuguesBrasil: "opções de temperatura inválidas", Espanhol: "opciones de temperatura inválidas"},
+	"invalid_include":             {PortuguesBrasil: "opção de inclusão inválida", Espanhol: "opción de inclusión inválida"},
+	"weather_quota_exceeded":      {PortuguesBrasil: "chaves da API de clima indisponíveis ou cota esgotada", Espanhol: "claves de la API del clima no disponibles o cuota agotada"},
+	"upstream_unavailable":        {PortuguesBrasil: "serviço externo indisponível", Espanhol: "servicio externo no disponible"},
+	"upstream_timeout":            {PortuguesBrasil: "tempo do serviço externo esgotado", Espanhol: "tiempo del servicio externo agotado"},
+	"invalid_api_key":             {PortuguesBrasil: "chave de API ausente ou inválida", Espanhol: "clave de API ausente o inválida"},
+	"rate_limit_exceeded":         {PortuguesBrasil: "limite de requisições excedido", Espanhol: "límite de solicitudes excedido"},
+	"daily_quota_exceeded":        {PortuguesBrasil: "cota diária excedida", Espanhol: "cuota diaria excedida"},
+	"request_timeout":             {PortuguesBrasil: "tempo da requisição esgotado", Espanhol: "tiempo de la solicitud agotado"},
+	"invalid_history_filter":      {PortuguesBrasil: "filtro do histórico inválido", Espanhol: "filtro del historial inválido"},
+	"invalid_subscription":        {PortuguesBrasil: "assinatura inválida", Espanhol: "suscripción inválida"},
+	"subscription_not_found":      {PortuguesBrasil: "assinatura não encontrada", Espanhol: "suscripción no encontrada"},
+	"webhook_rejected":            {PortuguesBrasil: "webhook recusado pelo destinatário", Espanhol: "webhook rechazado por el destinatario"},
+	"invalid_group":               {PortuguesBrasil: "grupo de CEPs inválido", Espanhol: "grupo de códigos postales inválido"},
+	"group_not_found":             {PortuguesBrasil: "grupo de CEPs não encontrado", Espanhol: "grupo de códigos postales no encontrado"},
+	"invalid_request_body":        {PortuguesBrasil: "corpo da requisição inválido", Espanhol: "cuerpo de la solicitud inválido"},
+	"unsupported_format":          {PortuguesBrasil: "formato de resposta não suportado", Espanhol: "formato de respuesta no soportado"},
+	"internal_error":              {PortuguesBrasil: "Erro interno do servidor", Espanhol: "Error interno del servidor"},
+}
+
+// Codigo devolve o código do catálogo para o erro, ou vazio quando ele não vem de um erro conhecido.
+func Codigo(err error) string {
+	for _, item := range codigos {
+		if errors.Is(err, item.erro) {
+			return item.codigo
+		}
+	}
+	return ""
+}
+
+// Mensagem traduz a mensagem do erro conhecido no início de err.Error(), mantendo o detalhe que vem depois
+// dele: "invalid zipcode: \"abc\"" vira "CEP inválido: \"abc\"" em pt-BR. Um erro desconhecido volta como
+// está.
+func Mensagem(idioma Idioma, err error) string {
+	texto := err.Error()
+	for _, item := range codigos {
+		if !errors.Is(err, item.erro) {
+			continue
+		}
+		traducao, ok := catalogo[item.codigo][idioma]
+		if ok && strings.HasPrefix(texto, item.erro.Error()) {
+			return traducao + texto[len(item.erro.Error()):]
+		}
+		return texto
+	}
+	return texto
+}
+
+// Reconstroi é o inverso de Mensagem: devolve o erro do código com o detalhe que segue a tradução em idioma,
+// e "CEP inválido: \"abc\"" com invalid_zipcode volta a ser "invalid zipcode: \"abc\"". Uma mensagem que não
+// começa pela tradução devolve só o erro do código, e um código desconhecido devolve nil.
+func Reconstroi(idioma Idioma, codigo, mensagem string) error {
+	for _, item := range codigos {
+		if item.codigo != codigo {
+			continue
+		}
+		detalhe, ok := strings.CutPrefix(mensagem, Mensagem(idioma, item.erro))
+		if !ok || detalhe == "" {
+			return item.erro
+		}
+		return fmt.Errorf("%w%s", item.erro, detalhe)
+	}
+	return nil
+}
+
+// Error substitui http.Error, escrevendo a mensagem de err no idioma da requisição e o código do erro no
+// header HeaderCodigoErro.
+func Error(w http.ResponseWriter, r *http.Request, err error, code int) {
+	if codigo := Codigo(err); codigo != "" {
+		w.Header().Set(HeaderCodigoErro, codigo)
+	}
+	http.Error(w, Mensagem(DoContexto(r.Context()), err), code)
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type I18nTestSuite struct {
+	suite.Suite
+}
+
+func TestI18nSuite(t *testing.T) {
+	suite.Run(t, new(I18nTestSuite))
+}
+
+func (s *I18nTestSuite) TestNegociaPeloAcceptLanguage() {
+	cenarios := []struct {
+		acceptLanguage string
+		esperado       Idioma
+	}{
+		{"pt-BR", PortuguesBrasil},
+		{"pt-PT", PortuguesBrasil},
+		{"es-AR;q=0.9, en;q=0.5", Espanhol},
+		{"fr, en;q=0.8", Ingles},
+		{"de", PortuguesBrasil},
+		{"", PortuguesBrasil},
+		{"pt-BR;q=inválido", PortuguesBrasil},
+	}
+
+	for _, cenario := range cenarios {
+		// Act
+		idioma := Negocia(cenario.acceptLanguage, PortuguesBrasil)
+
+		// Assert
+		s.Equal(cenario.esperado, idioma, cenario.acceptLanguage)
+	}
+}
+
+func (s *I18nTestSuite) TestParseIdioma() {
+	// Act
+	idioma, ok := ParseIdioma("PT-br")
+	_, desconhecido := ParseIdioma("fr")
+
+	// Assert
+	s.True(ok)
+	s.Equal(PortuguesBrasil, idioma)
+	s.False(desconhecido)
+}
+
+func (s *I18nTestSuite) TestMensagemMantemODetalheDoErro() {
+	// Arrange
+	err := fmt.Errorf("%w: %q", erros.ErrInvalidPrecision, "x")
+
+	// Act & Assert
+	s.Equal(`precisão inválida: "x"`, Mensagem(PortuguesBrasil, err))
+	s.Equal(`precisión inválida: "x"`, Mensagem(Espanhol, err))
+	s.Equal(`invalid precision: "x"`, Mensagem(Ingles, err))
+}
+
+func (s *I18nTestSuite) TestMensagemDeErroDesconhecido() {
+	// Act & Assert
+	s.Equal("falha qualquer", Mensagem(PortuguesBrasil, fmt.Errorf("falha qualquer")))
+	s.Empty(Codigo(fmt.Errorf("falha qualquer")))
+}
+
+func (s *I18nTestSuite) TestCatalogoTraduzTodosOsCodigos() {
+	for _, item := range codigos {
+		for _, idioma := range []Idioma{PortuguesBrasil, Espanhol} {
+			// Assert
+			s.NotEmpty(catalogo[item.codigo][idioma], "%s em %s", item.codigo, idioma)
+		}
+		s.Equal(item.codigo, Codigo(item.erro))
+	}
+}
+
+func (s *I18nTestSuite) TestErrorUsaOIdiomaDoContexto() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(NovoContexto(context.Background(), Espanhol))
+	rec := httptest.NewRecorder()
+
+	// Act
+	Error(rec, req, erros.ErrZipCodeNotFound, http.StatusNotFound)
+
+	// Assert
+	s.Equal(http.StatusNotFound, rec.Code)
+	s.Equal("código postal no encontrado\n", rec.Body.String())
+	s.Equal("zipcode_not_found", rec.Header().Get(HeaderCodigoErro))
+}
+
+func (s *I18nTestSuite) TestReconstroiOErroDaMensagemTraduzida() {
+	// Act
+	comDetalhe := Reconstroi(PortuguesBrasil, "invalid_precision", `precisão inválida: "x"`)
+	semDetalhe := Reconstroi(Espanhol, "city_not_found", "ciudad no encontrada")
+	desconhecido := Reconstroi(Ingles, "codigo_desconhecido", "falha qualquer")
+
+	// Assert
+	s.ErrorIs(comDetalhe, erros.ErrInvalidPrecision)
+	s.Equal(`invalid precision: "x"`, comDetalhe.Error())
+	s.Equal(erros.ErrCityNotFound, semDetalhe)
+	s.Nil(desconhecido)
+}
+
+func (s *I18nTestSuite) TestCodigoWeatherApi() {
+	// Act & Assert
+	s.Equal("pt", PortuguesBrasil.CodigoWeatherApi())
+	s.Equal("es", Espanhol.CodigoWeatherApi())
+	s.Empty(Ingles.CodigoWeatherApi())
+	s.Equal("pt", PortuguesBrasil.CodigoOpenMeteo())
+	s.Equal("es", Espanhol.CodigoOpenMeteo())
+	s.Equal("en", Ingles.CodigoOpenMeteo())
+	s.Equal(Ingles, DoContexto(context.Background()))
+}
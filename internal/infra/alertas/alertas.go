@@ -0,0 +1,87 @@
+package alertas
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+)
+
+// Condicao indica se o alerta dispara acima ou abaixo do limite.
+type Condicao string
+
+const (
+	CondicaoAcima  Condicao = "above"
+	CondicaoAbaixo Condicao = "below"
+)
+
+func (c Condicao) Valida() bool {
+	return c == CondicaoAcima || c == CondicaoAbaixo
+}
+
+// Assinatura pede um webhook em URL quando a temperatura do CEP passa do limite, em Celsius. Segredo
+// assina o corpo de cada entrega. Disparada fica ligada enquanto a condição continuar atendida, para que
+// o alerta seja enviado uma vez a cada vez que o limite é cruzado. Cliente é o cliente da API dono da
+// assinatura; sem autenticação, todas pertencem ao cliente vazio.
+type Assinatura struct {
+	ID        string
+	Cliente   string
+	Cep       string
+	Condicao  Condicao
+	Limite    float64
+	URL       string
+	Segredo   string
+	Disparada bool
+	CriadaEm  time.Time
+}
+
+// Atende indica se a temperatura, em Celsius, cumpre a condição da assinatura.
+func (a Assinatura) Atende(celsius float64) bool {
+	if a.Condicao == CondicaoAbaixo {
+		return celsius < a.Limite
+	}
+	return celsius > a.Limite
+}
+
+// Falha é uma entrega que esgotou as tentativas ou foi recusada pelo destino, guardada com o corpo
+// enviado para ser reenviada manualmente.
+type Falha struct {
+	ID           int64
+	AssinaturaID string
+	EventoID     string
+	Corpo        []byte
+	Tentativas   int
+	Erro         string
+	Em           time.Time
+}
+
+// Repositorio separa as assinaturas por cliente: Busca, Lista e Remove só enxergam as do cliente informado.
+// Todas é usada pela avaliação periódica, que confere as assinaturas de todos os clientes.
+type Repositorio interface {
+	Salva(ctx context.Context, assinatura Assinatura) error
+	Busca(ctx context.Context, cliente, id string) (Assinatura, error)
+	Lista(ctx context.Context, cliente string) ([]Assinatura, error)
+	Todas(ctx context.Context) ([]Assinatura, error)
+	Remove(ctx context.Context, cliente, id string) error
+	DefineDisparada(ctx context.Context, id string, disparada bool) error
+	RegistraFalha(ctx context.Context, falha Falha) error
+	ListaFalhas(ctx context.Context, assinaturaID string) ([]Falha, error)
+	Fecha() error
+}
+
+// Abre o repositório da configuração: SQLite em alertas.arquivo ou, sem arquivo, em memória.
+func Abre(cfg config.AlertasConfig) (Repositorio, error) {
+	if cfg.Arquivo == "" {
+		return NewRepositorioMemoria(), nil
+	}
+	return NewRepositorioSQLite(cfg.Arquivo)
+}
+
+// NovoID gera os identificadores das assinaturas e dos eventos, e também os segredos não informados.
+func NovoID(bytes int) string {
+	aleatorio := make([]byte, bytes)
+	rand.Read(aleatorio)
+	return hex.EncodeToString(aleatorio)
+}
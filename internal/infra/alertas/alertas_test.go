@@ -0,0 +1,183 @@
+package alertas
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositorioTestSuite struct {
+	suite.Suite
+	novo        func() Repositorio
+	repositorio Repositorio
+	assinatura  Assinatura
+}
+
+func TestRepositorioMemoriaSuite(t *testing.T) {
+	suite.Run(t, &RepositorioTestSuite{novo: func() Repositorio { return NewRepositorioMemoria() }})
+}
+
+func TestRepositorioSQLiteSuite(t *testing.T) {
+	s := &RepositorioTestSuite{}
+	s.novo = func() Repositorio {
+		repositorio, err := NewRepositorioSQLite(filepath.Join(s.T().TempDir(), "dados", "alertas.db"))
+		s.Require().NoError(err)
+		return repositorio
+	}
+	suite.Run(t, s)
+}
+
+func (s *RepositorioTestSuite) SetupTest() {
+	s.repositorio = s.novo()
+	s.assinatura = Assinatura{
+		ID:       "a1",
+		Cep:      "01001000",
+		Condicao: CondicaoAcima,
+		Limite:   35,
+		URL:      "https://alertas.example.com/webhook",
+		Segredo:  "segredo-com-16-caracteres",
+		CriadaEm: time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC),
+	}
+	s.Require().NoError(s.repositorio.Salva(context.Background(), s.assinatura))
+}
+
+func (s *RepositorioTestSuite) TearDownTest() {
+	s.NoError(s.repositorio.Fecha())
+}
+
+func (s *RepositorioTestSuite) TestSalvaEBusca() {
+	// Arrange
+	segunda := s.assinatura
+	segunda.ID = "a2"
+	segunda.Condicao = CondicaoAbaixo
+	segunda.Limite = 5
+	segunda.CriadaEm = s.assinatura.CriadaEm.Add(time.Minute)
+	s.Require().NoError(s.repositorio.Salva(context.Background(), segunda))
+
+	// Act
+	encontrada, err := s.repositorio.Busca(context.Background(), "", "a1")
+	s.Require().NoError(err)
+	todas, err := s.repositorio.Lista(context.Background(), "")
+	s.Require().NoError(err)
+	_, errInexistente := s.repositorio.Busca(context.Background(), "", "inexistente")
+
+	// Assert
+	s.Equal(s.assinatura, encontrada)
+	s.Equal([]Assinatura{s.assinatura, segunda}, todas)
+	s.ErrorIs(errInexistente, erros.ErrSubscriptionNotFound)
+}
+
+func (s *RepositorioTestSuite) TestSalvaSubstituiEDefineDisparada() {
+	// Arrange
+	alterada := s.assinatura
+	alterada.Limite = 30
+
+	// Act
+	s.Require().NoError(s.repositorio.Salva(context.Background(), alterada))
+	s.Require().NoError(s.repositorio.DefineDisparada(context.Background(), "a1", true))
+	errInexistente := s.repositorio.DefineDisparada(context.Background(), "inexistente", true)
+
+	// Assert
+	encontrada, err := s.repositorio.Busca(context.Background(), "", "a1")
+	s.Require().NoError(err)
+	s.Equal(30.0, encontrada.Limite)
+	s.True(encontrada.Disparada)
+	s.ErrorIs(errInexistente, erros.ErrSubscriptionNotFound)
+}
+
+func (s *RepositorioTestSuite) TestSeparaAsAssinaturasPorCliente() {
+	// Arrange
+	daAcme := s.assinatura
+	daAcme.ID = "a2"
+	daAcme.Cliente = "acme"
+	s.Require().NoError(s.repositorio.Salva(context.Background(), daAcme))
+	tomada := s.assinatura
+	tomada.Cliente = "acme"
+	tomada.URL = "https://acme.example.com/webhook"
+
+	// Act
+	errTomada := s.repositorio.Salva(context.Background(), tomada)
+	_, errOutroCliente := s.repositorio.Busca(context.Background(), "", "a2")
+	daLista, err := s.repositorio.Lista(context.Background(), "acme")
+	s.Require().NoError(err)
+	todas, err := s.repositorio.Todas(context.Background())
+	s.Require().NoError(err)
+	errRemove := s.repositorio.Remove(context.Background(), "acme", "a1")
+
+	// Assert
+	s.ErrorIs(errTomada, erros.ErrSubscriptionNotFound)
+	s.ErrorIs(errOutroCliente, erros.ErrSubscriptionNotFound)
+	s.Equal([]Assinatura{daAcme}, daLista)
+	s.Equal([]Assinatura{s.assinatura, daAcme}, todas)
+	s.ErrorIs(errRemove, erros.ErrSubscriptionNotFound)
+	original, err := s.repositorio.Busca(context.Background(), "", "a1")
+	s.Require().NoError(err)
+	s.Equal(s.assinatura, original)
+}
+
+func (s *RepositorioTestSuite) TestFalhasSaemComAAssinatura() {
+	// Arrange
+	falha := Falha{
+		AssinaturaID: "a1",
+		EventoID:     "e1",
+		Corpo:        []byte(`{"id":"e1"}`),
+		Tentativas:   5,
+		Erro:         "webhook respondeu 503",
+		Em:           time.Date(2025, 6, 10, 12, 5, 0, 0, time.UTC),
+	}
+
+	// Act
+	s.Require().NoError(s.repositorio.RegistraFalha(context.Background(), falha))
+	falhas, err := s.repositorio.ListaFalhas(context.Background(), "a1")
+	s.Require().NoError(err)
+	s.Require().NoError(s.repositorio.Remove(context.Background(), "", "a1"))
+	depois, err := s.repositorio.ListaFalhas(context.Background(), "a1")
+	s.Require().NoError(err)
+
+	// Assert
+	s.Require().Len(falhas, 1)
+	falha.ID = falhas[0].ID
+	s.Equal(falha, falhas[0])
+	s.Empty(depois)
+	s.ErrorIs(s.repositorio.Remove(context.Background(), "", "a1"), erros.ErrSubscriptionNotFound)
+}
+
+func (s *RepositorioTestSuite) TestAtendeCondicao() {
+	// Arrange
+	abaixo := Assinatura{Condicao: CondicaoAbaixo, Limite: 5}
+
+	// Assert
+	s.True(s.assinatura.Atende(35.1))
+	s.False(s.assinatura.Atende(35))
+	s.True(abaixo.Atende(4.9))
+	s.False(abaixo.Atende(5))
+}
+
+func (s *RepositorioTestSuite) TestMigraArquivoSemCliente() {
+	// Arrange
+	arquivo := filepath.Join(s.T().TempDir(), "alertas.db")
+	db, err := sql.Open("sqlite", "file:"+arquivo)
+	s.Require().NoError(err)
+	_, err = db.Exec(`CREATE TABLE assinaturas (id TEXT PRIMARY KEY, cep TEXT NOT NULL, condicao TEXT NOT NULL,
+		limite REAL NOT NULL, url TEXT NOT NULL, segredo TEXT NOT NULL, disparada INTEGER NOT NULL DEFAULT 0,
+		criada_em INTEGER NOT NULL);
+		INSERT INTO assinaturas VALUES ('antiga', '01001000', 'above', 35, 'https://alertas.example.com/webhook', 'segredo-com-16-caracteres', 0, 0)`)
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+
+	// Act
+	repositorio, err := NewRepositorioSQLite(arquivo)
+	s.Require().NoError(err)
+	defer repositorio.Fecha()
+	antiga, err := repositorio.Busca(context.Background(), "", "antiga")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Empty(antiga.Cliente)
+	s.Equal("01001000", antiga.Cep)
+}
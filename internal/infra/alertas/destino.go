@@ -0,0 +1,69 @@
+package alertas
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+)
+
+// faixasNaoPermitidas são as redes que nenhuma assinatura pode usar como destino: "esta rede", privadas,
+// CGNAT, loopback, link-local (como o 169.254.169.254 dos metadados de nuvem), reservadas, multicast e
+// broadcast, além dos equivalentes IPv6.
+var faixasNaoPermitidas = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// DestinoPermitido recusa os endereços das faixasNaoPermitidas. Os IPv4 mapeados em IPv6 valem pelo IPv4.
+func DestinoPermitido(endereco netip.Addr) bool {
+	endereco = endereco.Unmap()
+	if !endereco.IsValid() {
+		return false
+	}
+	for _, faixa := range faixasNaoPermitidas {
+		if faixa.Contains(endereco) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidaDestino confere o host da URL de callback na criação da assinatura: um IP precisa ser permitido e um
+// nome não pode resolver para nenhum endereço interno. Um nome que não resolve agora é aceito, porque a
+// entrega confere o endereço de novo a cada conexão.
+func ValidaDestino(ctx context.Context, destino *url.URL) error {
+	host := destino.Hostname()
+	if endereco, err := netip.ParseAddr(host); err == nil {
+		if !DestinoPermitido(endereco) {
+			return fmt.Errorf("endereço %s não permitido", endereco)
+		}
+		return nil
+	}
+
+	enderecos, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, endereco := range enderecos {
+		if !DestinoPermitido(endereco) {
+			return fmt.Errorf("%s resolve para o endereço %s, que não é permitido", host, endereco)
+		}
+	}
+	return nil
+}
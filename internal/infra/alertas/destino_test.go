@@ -0,0 +1,66 @@
+package alertas
+
+import (
+	"context"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DestinoTestSuite struct {
+	suite.Suite
+}
+
+func TestDestinoSuite(t *testing.T) {
+	suite.Run(t, new(DestinoTestSuite))
+}
+
+func (s *DestinoTestSuite) TestDestinoPermitido() {
+	cenarios := []struct {
+		endereco  string
+		permitido bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.16.0.1", false},
+		{"192.168.1.10", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"255.255.255.255", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:100.64.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, cenario := range cenarios {
+		// Act
+		permitido := DestinoPermitido(netip.MustParseAddr(cenario.endereco))
+
+		// Assert
+		s.Equal(cenario.permitido, permitido, cenario.endereco)
+	}
+}
+
+func (s *DestinoTestSuite) TestValidaDestinoRecusaIpInterno() {
+	// Arrange
+	metadados, _ := url.Parse("http://169.254.169.254/latest/meta-data/")
+	ipv6, _ := url.Parse("http://[::1]:8080/webhook")
+	publico, _ := url.Parse("https://8.8.8.8/webhook")
+
+	// Act & Assert
+	s.Error(ValidaDestino(context.Background(), metadados))
+	s.Error(ValidaDestino(context.Background(), ipv6))
+	s.NoError(ValidaDestino(context.Background(), publico))
+}
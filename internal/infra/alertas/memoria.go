@@ -0,0 +1,127 @@
+package alertas
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+)
+
+// RepositorioMemoria guarda as assinaturas enquanto o processo estiver de pé. É usado nos testes e quando
+// alertas.arquivo não está definido.
+type RepositorioMemoria struct {
+	mu          sync.RWMutex
+	assinaturas map[string]Assinatura
+	falhas      []Falha
+	ultimaFalha int64
+}
+
+func NewRepositorioMemoria() *RepositorioMemoria {
+	return &RepositorioMemoria{assinaturas: map[string]Assinatura{}}
+}
+
+// Salva não substitui a assinatura de mesmo ID de outro cliente.
+func (r *RepositorioMemoria) Salva(_ context.Context, assinatura Assinatura) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if atual, ok := r.assinaturas[assinatura.ID]; ok && atual.Cliente != assinatura.Cliente {
+		return erros.ErrSubscriptionNotFound
+	}
+	r.assinaturas[assinatura.ID] = assinatura
+	return nil
+}
+
+func (r *RepositorioMemoria) Busca(_ context.Context, cliente, id string) (Assinatura, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	assinatura, ok := r.assinaturas[id]
+	if !ok || assinatura.Cliente != cliente {
+		return Assinatura{}, erros.ErrSubscriptionNotFound
+	}
+	return assinatura, nil
+}
+
+func (r *RepositorioMemoria) Lista(_ context.Context, cliente string) ([]Assinatura, error) {
+	return r.filtra(func(assinatura Assinatura) bool { return assinatura.Cliente == cliente }), nil
+}
+
+func (r *RepositorioMemoria) Todas(_ context.Context) ([]Assinatura, error) {
+	return r.filtra(func(Assinatura) bool { return true }), nil
+}
+
+func (r *RepositorioMemoria) filtra(inclui func(Assinatura) bool) []Assinatura {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	assinaturas := make([]Assinatura, 0, len(r.assinaturas))
+	for _, assinatura := range r.assinaturas {
+		if inclui(assinatura) {
+			assinaturas = append(assinaturas, assinatura)
+		}
+	}
+	// Mesma ordem do SQLite: por criação e, no mesmo instante, pelo ID.
+	slices.SortFunc(assinaturas, func(a, b Assinatura) int {
+		if c := a.CriadaEm.Compare(b.CriadaEm); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return assinaturas
+}
+
+func (r *RepositorioMemoria) Remove(_ context.Context, cliente, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if assinatura, ok := r.assinaturas[id]; !ok || assinatura.Cliente != cliente {
+		return erros.ErrSubscriptionNotFound
+	}
+	delete(r.assinaturas, id)
+	r.falhas = slices.DeleteFunc(r.falhas, func(falha Falha) bool { return falha.AssinaturaID == id })
+	return nil
+}
+
+func (r *RepositorioMemoria) DefineDisparada(_ context.Context, id string, disparada bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	assinatura, ok := r.assinaturas[id]
+	if !ok {
+		return erros.ErrSubscriptionNotFound
+	}
+	assinatura.Disparada = disparada
+	r.assinaturas[id] = assinatura
+	return nil
+}
+
+func (r *RepositorioMemoria) RegistraFalha(_ context.Context, falha Falha) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ultimaFalha++
+	falha.ID = r.ultimaFalha
+	r.falhas = append(r.falhas, falha)
+	return nil
+}
+
+func (r *RepositorioMemoria) ListaFalhas(_ context.Context, assinaturaID string) ([]Falha, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	falhas := []Falha{}
+	for _, falha := range r.falhas {
+		if falha.AssinaturaID == assinaturaID {
+			falhas = append(falhas, falha)
+		}
+	}
+	return falhas, nil
+}
+
+func (r *RepositorioMemoria) Fecha() error {
+	return nil
+}
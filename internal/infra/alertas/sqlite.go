@@ -0,0 +1,198 @@
+package alertas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	_ "modernc.org/sqlite"
+)
+
+// esquema cria as tabelas na primeira abertura. Os instantes são gravados em nanossegundos desde a época
+// (UTC), como no histórico.
+const esquema = `
+CREATE TABLE IF NOT EXISTS assinaturas (
+	id         TEXT    PRIMARY KEY,
+	cliente    TEXT    NOT NULL DEFAULT '',
+	cep        TEXT    NOT NULL,
+	condicao   TEXT    NOT NULL,
+	limite     REAL    NOT NULL,
+	url        TEXT    NOT NULL,
+	segredo    TEXT    NOT NULL,
+	disparada  INTEGER NOT NULL DEFAULT 0,
+	criada_em  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS falhas (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	assinatura_id TEXT    NOT NULL REFERENCES assinaturas (id) ON DELETE CASCADE,
+	evento_id     TEXT    NOT NULL,
+	corpo         BLOB    NOT NULL,
+	tentativas    INTEGER NOT NULL,
+	erro          TEXT    NOT NULL,
+	em            INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS falhas_assinatura_id ON falhas (assinatura_id, id);
+`
+
+// migracaoCliente acrescenta a coluna cliente aos arquivos criados antes dela; as assinaturas existentes
+// ficam com o cliente vazio, o mesmo de quando a autenticação está desligada.
+const migracaoCliente = `ALTER TABLE assinaturas ADD COLUMN cliente TEXT NOT NULL DEFAULT ''`
+
+// RepositorioSQLite grava as assinaturas e as entregas que falharam em um arquivo SQLite embarcado.
+type RepositorioSQLite struct {
+	db *sql.DB
+}
+
+func NewRepositorioSQLite(arquivo string) (*RepositorioSQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(arquivo), 0o755); err != nil {
+		return nil, fmt.Errorf("falha ao criar o diretório dos alertas: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+arquivo+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir os alertas %s: %w", arquivo, err)
+	}
+	if _, err := db.Exec(esquema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao preparar os alertas %s: %w", arquivo, err)
+	}
+	var temCliente bool
+	if err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info('assinaturas') WHERE name = 'cliente'`).Scan(&temCliente); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao preparar os alertas %s: %w", arquivo, err)
+	}
+	if !temCliente {
+		if _, err := db.Exec(migracaoCliente); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("falha ao migrar os alertas %s: %w", arquivo, err)
+		}
+	}
+
+	return &RepositorioSQLite{db: db}, nil
+}
+
+// Salva cria a assinatura ou substitui a de mesmo ID e cliente, preservando o estado de disparo. O ID de
+// outro cliente não é substituído.
+func (r *RepositorioSQLite) Salva(ctx context.Context, assinatura Assinatura) error {
+	return r.afetaUma(r.db.ExecContext(ctx,
+		`INSERT INTO assinaturas (id, cliente, cep, condicao, limite, url, segredo, disparada, criada_em)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET cep = excluded.cep, condicao = excluded.condicao, limite = excluded.limite,
+			url = excluded.url, segredo = excluded.segredo, disparada = excluded.disparada
+		WHERE assinaturas.cliente = excluded.cliente`,
+		assinatura.ID, assinatura.Cliente, assinatura.Cep, string(assinatura.Condicao), assinatura.Limite, assinatura.URL,
+		assinatura.Segredo, assinatura.Disparada, assinatura.CriadaEm.UnixNano(),
+	))
+}
+
+func (r *RepositorioSQLite) Busca(ctx context.Context, cliente, id string) (Assinatura, error) {
+	linha := r.db.QueryRowContext(ctx, colunas+` WHERE id = ? AND cliente = ?`, id, cliente)
+	assinatura, err := leAssinatura(linha)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Assinatura{}, erros.ErrSubscriptionNotFound
+	}
+	return assinatura, err
+}
+
+func (r *RepositorioSQLite) Lista(ctx context.Context, cliente string) ([]Assinatura, error) {
+	return r.consulta(ctx, colunas+` WHERE cliente = ? ORDER BY criada_em, id`, cliente)
+}
+
+func (r *RepositorioSQLite) Todas(ctx context.Context) ([]Assinatura, error) {
+	return r.consulta(ctx, colunas+` ORDER BY criada_em, id`)
+}
+
+func (r *RepositorioSQLite) consulta(ctx context.Context, query string, argumentos ...any) ([]Assinatura, error) {
+	linhas, err := r.db.QueryContext(ctx, query, argumentos...)
+	if err != nil {
+		return nil, err
+	}
+	defer linhas.Close()
+
+	assinaturas := []Assinatura{}
+	for linhas.Next() {
+		assinatura, err := leAssinatura(linhas)
+		if err != nil {
+			return nil, err
+		}
+		assinaturas = append(assinaturas, assinatura)
+	}
+	return assinaturas, linhas.Err()
+}
+
+func (r *RepositorioSQLite) Remove(ctx context.Context, cliente, id string) error {
+	return r.afetaUma(r.db.ExecContext(ctx, `DELETE FROM assinaturas WHERE id = ? AND cliente = ?`, id, cliente))
+}
+
+func (r *RepositorioSQLite) DefineDisparada(ctx context.Context, id string, disparada bool) error {
+	return r.afetaUma(r.db.ExecContext(ctx, `UPDATE assinaturas SET disparada = ? WHERE id = ?`, disparada, id))
+}
+
+func (r *RepositorioSQLite) RegistraFalha(ctx context.Context, falha Falha) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO falhas (assinatura_id, evento_id, corpo, tentativas, erro, em) VALUES (?, ?, ?, ?, ?, ?)`,
+		falha.AssinaturaID, falha.EventoID, falha.Corpo, falha.Tentativas, falha.Erro, falha.Em.UnixNano(),
+	)
+	return err
+}
+
+func (r *RepositorioSQLite) ListaFalhas(ctx context.Context, assinaturaID string) ([]Falha, error) {
+	linhas, err := r.db.QueryContext(ctx,
+		`SELECT id, assinatura_id, evento_id, corpo, tentativas, erro, em FROM falhas WHERE assinatura_id = ? ORDER BY id`,
+		assinaturaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer linhas.Close()
+
+	falhas := []Falha{}
+	for linhas.Next() {
+		var falha Falha
+		var em int64
+		if err := linhas.Scan(&falha.ID, &falha.AssinaturaID, &falha.EventoID, &falha.Corpo, &falha.Tentativas, &falha.Erro, &em); err != nil {
+			return nil, err
+		}
+		falha.Em = time.Unix(0, em).UTC()
+		falhas = append(falhas, falha)
+	}
+	return falhas, linhas.Err()
+}
+
+func (r *RepositorioSQLite) Fecha() error {
+	return r.db.Close()
+}
+
+const colunas = `SELECT id, cliente, cep, condicao, limite, url, segredo, disparada, criada_em FROM assinaturas`
+
+func leAssinatura(linha interface{ Scan(...any) error }) (Assinatura, error) {
+	var assinatura Assinatura
+	var condicao string
+	var criadaEm int64
+	err := linha.Scan(&assinatura.ID, &assinatura.Cliente, &assinatura.Cep, &condicao, &assinatura.Limite, &assinatura.URL,
+		&assinatura.Segredo, &assinatura.Disparada, &criadaEm)
+	if err != nil {
+		return Assinatura{}, err
+	}
+	assinatura.Condicao = Condicao(condicao)
+	assinatura.CriadaEm = time.Unix(0, criadaEm).UTC()
+	return assinatura, nil
+}
+
+// afetaUma traduz a ausência de linhas afetadas em ErrSubscriptionNotFound.
+func (r *RepositorioSQLite) afetaUma(resultado sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if afetadas, err := resultado.RowsAffected(); err != nil {
+		return err
+	} else if afetadas == 0 {
+		return erros.ErrSubscriptionNotFound
+	}
+	return nil
+}
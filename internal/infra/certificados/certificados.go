@@ -0,0 +1,183 @@
+package certificados
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+)
+
+// intervaloVerificacao limita a frequência com que os arquivos são consultados durante os handshakes.
+var intervaloVerificacao = time.Second
+
+// Servidor monta a configuração TLS de um servidor. O certificado e a CA dos clientes são relidos quando
+// os arquivos mudam; com CA de clientes, só clientes com certificado assinado por ela são aceitos.
+func Servidor(cfg config.TLSServidorConfig) (*tls.Config, error) {
+	arquivos, err := novosArquivos(cfg.Certificado, cfg.Chave, cfg.CAClientes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			par, pool := arquivos.atuais()
+
+			configuracao := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*par},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if pool != nil {
+				configuracao.ClientCAs = pool
+				configuracao.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return configuracao, nil
+		},
+	}, nil
+}
+
+// Cliente monta a configuração TLS das conexões de saída. Sem nenhuma opção definida retorna nil, e o
+// padrão do Go (autoridades do sistema) é usado.
+func Cliente(cfg config.TLSClienteConfig) (*tls.Config, error) {
+	if cfg == (config.TLSClienteConfig{}) {
+		return nil, nil
+	}
+
+	arquivos, err := novosArquivos(cfg.Certificado, cfg.Chave, cfg.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	configuracao := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.NomeServidor}
+	if cfg.Certificado != "" {
+		configuracao.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			par, _ := arquivos.atuais()
+			return par, nil
+		}
+	}
+	if cfg.CA != "" {
+		// A verificação padrão usaria uma CA fixa; ela é refeita em VerifyConnection com a CA atual.
+		configuracao.InsecureSkipVerify = true
+		configuracao.VerifyConnection = func(estado tls.ConnectionState) error {
+			_, pool := arquivos.atuais()
+			return verificaServidor(estado, pool, cfg.NomeServidor)
+		}
+	}
+
+	return configuracao, nil
+}
+
+func verificaServidor(estado tls.ConnectionState, pool *x509.CertPool, nomeServidor string) error {
+	if len(estado.PeerCertificates) == 0 {
+		return errors.New("o servidor não apresentou certificado")
+	}
+	if nomeServidor == "" {
+		nomeServidor = estado.ServerName
+	}
+
+	intermediarias := x509.NewCertPool()
+	for _, certificado := range estado.PeerCertificates[1:] {
+		intermediarias.AddCert(certificado)
+	}
+
+	_, err := estado.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediarias,
+		DNSName:       nomeServidor,
+	})
+	return err
+}
+
+// arquivos guarda o par certificado/chave e a CA lidos do disco e os relê quando algum arquivo muda.
+// Uma leitura com erro, como a de um certificado gravado pela metade, mantém os valores anteriores.
+type arquivos struct {
+	certificado, chave, ca string
+
+	mu          sync.Mutex
+	verificado  time.Time
+	modificacao map[string]time.Time
+	par         *tls.Certificate
+	pool        *x509.CertPool
+}
+
+func novosArquivos(certificado, chave, ca string) (*arquivos, error) {
+	a := &arquivos{certificado: certificado, chave: chave, ca: ca}
+	if err := a.le(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *arquivos) le() error {
+	modificacao := map[string]time.Time{}
+	for _, arquivo := range []string{a.certificado, a.chave, a.ca} {
+		if arquivo == "" {
+			continue
+		}
+		info, err := os.Stat(arquivo)
+		if err != nil {
+			return fmt.Errorf("falha ao ler %s: %w", arquivo, err)
+		}
+		modificacao[arquivo] = info.ModTime()
+	}
+
+	var par *tls.Certificate
+	if a.certificado != "" {
+		lido, err := tls.LoadX509KeyPair(a.certificado, a.chave)
+		if err != nil {
+			return fmt.Errorf("falha ao carregar o certificado %s: %w", a.certificado, err)
+		}
+		par = &lido
+	}
+
+	var pool *x509.CertPool
+	if a.ca != "" {
+		conteudo, err := os.ReadFile(a.ca)
+		if err != nil {
+			return fmt.Errorf("falha ao ler a CA %s: %w", a.ca, err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(conteudo) {
+			return fmt.Errorf("nenhum certificado PEM encontrado na CA %s", a.ca)
+		}
+	}
+
+	a.par, a.pool, a.modificacao = par, pool, modificacao
+	return nil
+}
+
+// atuais devolve o par e a CA, relendo os arquivos se algum foi alterado desde a última leitura.
+func (a *arquivos) atuais() (*tls.Certificate, *x509.CertPool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if time.Since(a.verificado) >= intervaloVerificacao {
+		a.verificado = time.Now()
+		if a.alterados() {
+			if err := a.le(); err != nil {
+				slog.Warn("falha ao recarregar certificados, mantendo os anteriores", "erro", err)
+			} else {
+				slog.Info("certificados recarregados", "certificado", a.certificado, "ca", a.ca)
+			}
+		}
+	}
+
+	return a.par, a.pool
+}
+
+func (a *arquivos) alterados() bool {
+	for arquivo, modificacao := range a.modificacao {
+		info, err := os.Stat(arquivo)
+		if err == nil && !info.ModTime().Equal(modificacao) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package certificados
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type CertificadosTestSuite struct {
+	suite.Suite
+	dir     string
+	ca      *x509.Certificate
+	chaveCA *ecdsa.PrivateKey
+}
+
+func TestCertificadosSuite(t *testing.T) {
+	suite.Run(t, new(CertificadosTestSuite))
+}
+
+func (s *CertificadosTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	intervaloVerificacao = 0
+	s.geraCA("ca.pem")
+	s.geraCertificado("servidor", big.NewInt(1))
+}
+
+func (s *CertificadosTestSuite) TearDownTest() {
+	intervaloVerificacao = time.Second
+}
+
+func (s *CertificadosTestSuite) TestServidorRecarregaCertificadoAlterado() {
+	// Arrange
+	servidor := s.novoServidor()
+	defer servidor.Close()
+	client := s.novoClient()
+	primeiro := s.serialApresentado(client, servidor.URL)
+
+	// Act
+	s.geraCertificado("servidor", big.NewInt(2))
+	segundo := s.serialApresentado(client, servidor.URL)
+
+	// Assert
+	s.Equal(int64(1), primeiro)
+	s.Equal(int64(2), segundo)
+}
+
+func (s *CertificadosTestSuite) TestClienteRecusaServidorDeOutraCA() {
+	// Arrange
+	servidor := s.novoServidor()
+	defer servidor.Close()
+	client := s.novoClient()
+
+	// Act
+	s.geraCA("ca.pem")
+	_, err := client.Get(servidor.URL)
+
+	// Assert
+	s.ErrorContains(err, "certificate signed by unknown authority")
+}
+
+func (s *CertificadosTestSuite) TestServidorFalhaSemCertificado() {
+	// Act
+	_, err := Servidor(config.TLSServidorConfig{Habilitado: true, Certificado: s.arquivo("inexistente.pem"), Chave: s.arquivo("inexistente.pem")})
+
+	// Assert
+	s.ErrorContains(err, "inexistente.pem")
+}
+
+func (s *CertificadosTestSuite) novoServidor() *httptest.Server {
+	tlsConfig, err := Servidor(config.TLSServidorConfig{
+		Habilitado:  true,
+		Certificado: s.arquivo("servidor.pem"),
+		Chave:       s.arquivo("servidor-chave.pem"),
+	})
+	s.Require().NoError(err)
+
+	servidor := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	servidor.TLS = tlsConfig
+	servidor.StartTLS()
+	return servidor
+}
+
+func (s *CertificadosTestSuite) novoClient() *http.Client {
+	tlsConfig, err := Cliente(config.TLSClienteConfig{CA: s.arquivo("ca.pem"), NomeServidor: "localhost"})
+	s.Require().NoError(err)
+
+	// Sem conexões reaproveitadas, cada requisição faz um novo handshake.
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+}
+
+func (s *CertificadosTestSuite) serialApresentado(client *http.Client, url string) int64 {
+	resp, err := client.Get(url)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func (s *CertificadosTestSuite) arquivo(nome string) string {
+	return filepath.Join(s.dir, nome)
+}
+
+func (s *CertificadosTestSuite) geraCA(nome string) {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	modelo := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "CA de teste"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, modelo, modelo, &chave.PublicKey, chave)
+	s.Require().NoError(err)
+	s.ca, err = x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	s.chaveCA = chave
+
+	s.gravaPEM(nome, "CERTIFICATE", der)
+}
+
+func (s *CertificadosTestSuite) geraCertificado(nome string, serial *big.Int) {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	modelo := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, modelo, s.ca, &chave.PublicKey, s.chaveCA)
+	s.Require().NoError(err)
+	chaveDer, err := x509.MarshalECPrivateKey(chave)
+	s.Require().NoError(err)
+
+	s.gravaPEM(nome+".pem", "CERTIFICATE", der)
+	s.gravaPEM(nome+"-chave.pem", "EC PRIVATE KEY", chaveDer)
+}
+
+func (s *CertificadosTestSuite) gravaPEM(nome, tipo string, der []byte) {
+	s.Require().NoError(os.WriteFile(s.arquivo(nome), pem.EncodeToMemory(&pem.Block{Type: tipo, Bytes: der}), 0o600))
+}
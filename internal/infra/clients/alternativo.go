@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WeatherClientComAlternativo consulta o provedor principal e, quando ele está sem cota ou sem chaves
+// disponíveis, repete a consulta no alternativo.
+type WeatherClientComAlternativo struct {
+	tracer      trace.Tracer
+	principal   WeatherClient
+	alternativo WeatherClient
+}
+
+func NewWeatherClientComAlternativo(tracer trace.Tracer, principal, alternativo WeatherClient) *WeatherClientComAlternativo {
+	return &WeatherClientComAlternativo{
+		tracer:      tracer,
+		principal:   principal,
+		alternativo: alternativo,
+	}
+}
+
+func (c *WeatherClientComAlternativo) ConsultaClima(ctx context.Context, cidade string) (*WeatherResponse, error) {
+	weatherResponse, err := c.principal.ConsultaClima(ctx, cidade)
+	if !errors.Is(err, erros.ErrWeatherApiQuotaExceeded) {
+		return weatherResponse, err
+	}
+
+	ctx, span := otel.StartSpan(ctx, c.tracer, "ConsultaClimaAlternativo")
+	defer span.End()
+
+	otel.AddSpanEvent(span, "Provedor principal sem cota, consultando o alternativo", map[string]interface{}{"motivo": err.Error()})
+
+	weatherResponse, err = c.alternativo.ConsultaClima(ctx, cidade)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+	}
+
+	return weatherResponse, err
+}
@@ -2,15 +2,16 @@ package clients
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
+	"strings"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -20,48 +21,86 @@ type CalculaTemperaturasClientService struct {
 	client http.Client
 }
 
-func NewCalculaTemperaturasClient(tracer trace.Tracer) *CalculaTemperaturasClientService {
+func NewCalculaTemperaturasClient(tracer trace.Tracer, cfg config.UpstreamConfig) *CalculaTemperaturasClientService {
 	return &CalculaTemperaturasClientService{
 		tracer: tracer,
-		uri:    "http://service-b:3001/",
-		client: http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-			Timeout:   10 * time.Second,
-		},
+		uri:    baseURL(cfg),
+		client: newHTTPClient(cfg, propagaRequestID),
 	}
 }
 
-func (c *CalculaTemperaturasClientService) CalculaTemperaturas(cep string) (response *TemperaturasResponse, err error) {
-	_, span := otel.StartSpan(context.Background(), c.tracer, "CalculaTemperaturas")
+func (c *CalculaTemperaturasClientService) CalculaTemperaturas(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (response *TemperaturasResponse, err error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "CalculaTemperaturas")
 	defer span.End()
 
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%scidades/%s/temperaturas", c.uri, cep), nil)
+	response = &TemperaturasResponse{}
+	header, err := c.consulta(ctx, span, fmt.Sprintf("%scidades/%s/temperaturas", c.uri, cep), opcoes, response)
+	if err != nil {
+		return nil, err
+	}
+
+	// O Serviço B informa o horário da leitura no header Last-Modified.
+	response.ObservedAt, _ = http.ParseTime(header.Get("Last-Modified"))
+
+	return response, nil
+}
+
+func (c *CalculaTemperaturasClientService) CalculaTemperaturasV2(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (response *TemperaturasV2Response, err error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "CalculaTemperaturasV2")
+	defer span.End()
+
+	response = &TemperaturasV2Response{}
+	if _, err = c.consulta(ctx, span, fmt.Sprintf("%sv2/cidades/%s/temperaturas", c.uri, cep), opcoes, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *CalculaTemperaturasClientService) consulta(ctx context.Context, span trace.Span, uri string, opcoes domain.OpcoesTemperatura, response validavel) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		otel.RecordSpanError(span, err)
-		return response, err
+		return nil, err
 	}
+	req.URL.RawQuery = opcoes.Query().Encode()
+	req.Header.Set("Accept", "application/json")
+	// O Serviço A já negociou o idioma; o Serviço B responde as mensagens e a condição do tempo nele.
+	req.Header.Set("Accept-Language", string(i18n.DoContexto(ctx)))
+
 	resp, err := c.client.Do(req)
 	if err != nil {
+		err = erroDeConexao(config.UpstreamServicoB, err)
 		otel.RecordSpanError(span, err)
-		return response, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnprocessableEntity {
-			return response, erros.ErrInvalidZipCode
-		}
-
-		if resp.StatusCode == http.StatusNotFound {
-			return response, erros.ErrZipCodeNotFound
+		err := erroDoServicoB(ctx, resp)
+		if !errors.Is(err, erros.TipoValidacao) && !errors.Is(err, erros.TipoNaoEncontrado) {
+			otel.RecordSpanError(span, err)
 		}
-
-		return response, fmt.Errorf("error fetching data: %s", resp.Status)
+		return nil, err
 	}
 
-	body, _ := io.ReadAll(resp.Body)
+	if err := decodificaJSON(ctx, config.UpstreamServicoB, resp, response); err != nil {
+		otel.RecordSpanError(span, err)
+		return nil, err
+	}
 
-	json.Unmarshal(body, &response)
+	return resp.Header, nil
+}
 
-	return response, err
+// erroDoServicoB reconstrói o erro que o Serviço B já classificou: o tipo vem do status e o erro, do código
+// do catálogo no header i18n.HeaderCodigoErro. Sem código conhecido, ou com um erro interno do Serviço B,
+// vale a falha genérica de upstream.
+func erroDoServicoB(ctx context.Context, resp *http.Response) error {
+	corpo, _ := leCorpo(config.UpstreamServicoB, resp)
+	causa := i18n.Reconstroi(i18n.DoContexto(ctx), resp.Header.Get(i18n.HeaderCodigoErro), strings.TrimSpace(string(corpo)))
+	tipo, ok := erros.TipoDoStatus(resp.StatusCode)
+	if causa == nil || !ok || tipo == erros.TipoInterno {
+		return erroDeStatus(config.UpstreamServicoB, resp, nil)
+	}
+	return erros.NewErroUpstream(config.UpstreamServicoB, tipo, causa)
 }
@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type CalculaTemperaturasClientTestSuite struct {
+	suite.Suite
+	erro     error
+	status   int
+	servicoB *httptest.Server
+	client   *CalculaTemperaturasClientService
+}
+
+func TestCalculaTemperaturasClientSuite(t *testing.T) {
+	suite.Run(t, new(CalculaTemperaturasClientTestSuite))
+}
+
+func (s *CalculaTemperaturasClientTestSuite) SetupTest() {
+	// Como o Serviço B, o stub responde o erro no idioma negociado pelo Accept-Language.
+	s.servicoB = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idioma := i18n.Negocia(r.Header.Get("Accept-Language"), i18n.Ingles)
+		i18n.Error(w, r.WithContext(i18n.NovoContexto(r.Context(), idioma)), s.erro, s.status)
+	}))
+	s.client = NewCalculaTemperaturasClient(noop.NewTracerProvider().Tracer("teste"), config.UpstreamConfig{
+		BaseURL: s.servicoB.URL,
+		Timeout: time.Second,
+	})
+}
+
+func (s *CalculaTemperaturasClientTestSuite) TearDownTest() {
+	s.servicoB.Close()
+}
+
+func (s *CalculaTemperaturasClientTestSuite) TestReconstroiOErroDoServicoB() {
+	cenarios := []struct {
+		nome       string
+		erro       error
+		status     int
+		tipo       erros.Tipo
+		retentavel bool
+	}{
+		{"cep invalido", erros.ErrInvalidZipCode, http.StatusUnprocessableEntity, erros.TipoValidacao, false},
+		{"cep inexistente", erros.ErrZipCodeNotFound, http.StatusNotFound, erros.TipoNaoEncontrado, false},
+		{"cidade inexistente", erros.ErrCityNotFound, http.StatusNotFound, erros.TipoNaoEncontrado, false},
+		{"localidade divergente", erros.ErrLocationMismatch, http.StatusNotFound, erros.TipoNaoEncontrado, false},
+		{"cota esgotada", erros.ErrWeatherApiQuotaExceeded, http.StatusServiceUnavailable, erros.TipoUpstreamCota, false},
+		{"timeout", erros.ErrUpstreamTimeout, http.StatusGatewayTimeout, erros.TipoTimeout, true},
+		{"indisponivel", erros.ErrUpstreamUnavailable, http.StatusBadGateway, erros.TipoUpstreamIndisponivel, true},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			s.erro, s.status = cenario.erro, cenario.status
+
+			// Act
+			_, err := s.client.CalculaTemperaturas(context.Background(), "01001000", domain.OpcoesTemperatura{})
+
+			// Assert
+			classificacao := erros.Classifica(err)
+			s.ErrorIs(err, cenario.erro)
+			s.Equal(cenario.tipo, classificacao.Tipo)
+			s.Equal(cenario.status, classificacao.Status)
+			s.Equal(cenario.retentavel, classificacao.Retentavel)
+			s.Equal(config.UpstreamServicoB, classificacao.Upstream)
+		})
+	}
+}
+
+func (s *CalculaTemperaturasClientTestSuite) TestMensagemDoServicoBNaoETraduzidaDuasVezes() {
+	// Arrange
+	s.erro, s.status = fmt.Errorf("%w: %q", erros.ErrInvalidPrecision, "x"), http.StatusBadRequest
+	ctx := i18n.NovoContexto(context.Background(), i18n.PortuguesBrasil)
+
+	// Act
+	_, err := s.client.CalculaTemperaturasV2(ctx, "01001000", domain.OpcoesTemperatura{})
+
+	// Assert
+	s.ErrorIs(err, erros.ErrInvalidPrecision)
+	s.Equal(http.StatusBadRequest, erros.Classifica(err).Status)
+	s.Equal(`precisão inválida: "x"`, i18n.Mensagem(i18n.PortuguesBrasil, erros.Classifica(err).Publico))
+}
+
+func (s *CalculaTemperaturasClientTestSuite) TestErroInternoDoServicoBViraUpstreamIndisponivel() {
+	// Arrange
+	s.erro, s.status = erros.ErrInternal, http.StatusInternalServerError
+
+	// Act
+	_, err := s.client.CalculaTemperaturas(context.Background(), "01001000", domain.OpcoesTemperatura{})
+
+	// Assert
+	classificacao := erros.Classifica(err)
+	s.Equal(erros.TipoUpstreamIndisponivel, classificacao.Tipo)
+	s.Equal(http.StatusBadGateway, classificacao.Status)
+	s.True(classificacao.Retentavel)
+}
@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+)
+
+// Códigos da WeatherAPI que indicam problema com a chave e não com a consulta.
+const (
+	codigoChaveInvalida     = 2006
+	codigoCotaExcedida      = 2007
+	codigoChaveDesabilitada = 2008
+)
+
+// tempoChaveInvalida é quanto uma chave inválida ou desabilitada fica fora da rotação antes de ser tentada de novo.
+var tempoChaveInvalida = time.Hour
+
+// chavesWeatherApi é compartilhado entre os clients, que são criados a cada requisição, para que a
+// indisponibilidade de uma chave seja lembrada entre as consultas.
+var chavesWeatherApi = newRotacaoChaves()
+
+type rotacaoChaves struct {
+	mu            sync.Mutex
+	proxima       int
+	indisponiveis map[string]time.Time
+	agora         func() time.Time
+}
+
+func newRotacaoChaves() *rotacaoChaves {
+	return &rotacaoChaves{
+		indisponiveis: map[string]time.Time{},
+		agora:         time.Now,
+	}
+}
+
+func chaveIndisponivel(codigo int) bool {
+	return codigo == codigoChaveInvalida || codigo == codigoCotaExcedida || codigo == codigoChaveDesabilitada
+}
+
+// candidatas devolve as chaves disponíveis na ordem em que devem ser tentadas.
+func (r *rotacaoChaves) candidatas(chaves []string, estrategia string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agora := r.agora()
+	disponiveis := make([]string, 0, len(chaves))
+	for _, chave := range chaves {
+		if ate, ok := r.indisponiveis[chave]; ok && agora.Before(ate) {
+			continue
+		}
+		disponiveis = append(disponiveis, chave)
+	}
+
+	if estrategia != config.RotacaoRoundRobin || len(disponiveis) == 0 {
+		return disponiveis
+	}
+
+	inicio := r.proxima % len(disponiveis)
+	r.proxima++
+	return slices.Concat(disponiveis[inicio:], disponiveis[:inicio])
+}
+
+// marcaIndisponivel tira a chave da rotação: até o início do próximo mês (UTC) quando a cota mensal
+// acabou, ou por tempoChaveInvalida quando ela foi recusada.
+func (r *rotacaoChaves) marcaIndisponivel(chave string, codigo int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agora := r.agora()
+	ate := agora.Add(tempoChaveInvalida)
+	if codigo == codigoCotaExcedida {
+		ate = time.Date(agora.UTC().Year(), agora.UTC().Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	r.indisponiveis[chave] = ate
+}
@@ -1,9 +1,21 @@
 package clients
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+)
 
 type CepClient interface {
-	ConsultaCep(cep string) (*DadosCepResponse, error)
+	ConsultaCep(ctx context.Context, cep string) (*DadosCepResponse, error)
+}
+
+// EnderecoClient busca os CEPs dos logradouros de uma cidade, como a pesquisa de endereços da ViaCEP.
+type EnderecoClient interface {
+	BuscaEnderecos(ctx context.Context, uf, cidade, logradouro string) ([]DadosCepResponse, error)
 }
 
 type DadosCepResponse struct {
@@ -13,16 +25,42 @@ type DadosCepResponse struct {
 	Bairro      string `json:"bairro"`
 	Localidade  string `json:"localidade"`
 	Uf          string `json:"uf"`
+	Ibge        string `json:"ibge"`
 	Erro        string `json:"erro,omitempty"`
 }
 
+// valida exige o CEP, a cidade e a UF; a resposta de CEP inexistente, {"erro": "true"}, não traz nenhum deles.
+func (d *DadosCepResponse) valida() error {
+	if d.Erro != "" {
+		return nil
+	}
+	if d.Cep == "" || d.Localidade == "" || d.Uf == "" {
+		return errors.New("cep, localidade e uf são obrigatórios")
+	}
+	return nil
+}
+
 type WeatherClient interface {
-	ConsultaClima(cidade string) (*WeatherResponse, error)
+	ConsultaClima(ctx context.Context, cidade string) (*WeatherResponse, error)
 }
 
 type WeatherResponse struct {
 	Location Location `json:"location"`
 	Current  Current  `json:"current"`
+
+	// Provedor identifica quem respondeu, config.ProvedorWeatherApi ou config.ProvedorOpenMeteo.
+	Provedor string `json:"-"`
+}
+
+// valida exige a localidade e o horário da leitura, sem os quais a temperatura não tem como ser conferida.
+func (w *WeatherResponse) valida() error {
+	if w.Location.Name == "" {
+		return errors.New("location.name é obrigatório")
+	}
+	if w.Current.LastUpdatedEpoch <= 0 {
+		return errors.New("current.last_updated_epoch é obrigatório")
+	}
+	return nil
 }
 
 type Location struct {
@@ -105,7 +143,8 @@ func (e WeatherErrorResponse) Error() string {
 }
 
 type CalculaTemperaturasClient interface {
-	CalculaTemperaturas(cep string) (*TemperaturasResponse, error)
+	CalculaTemperaturas(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (*TemperaturasResponse, error)
+	CalculaTemperaturasV2(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (*TemperaturasV2Response, error)
 }
 
 type TemperaturasResponse struct {
@@ -113,4 +152,41 @@ type TemperaturasResponse struct {
 	Celcius    string `json:"temp_C"`
 	Fahrenheit string `json:"temp_F"`
 	Kelvin     string `json:"temp_K"`
+	Rankine    string `json:"temp_R"`
+
+	ObservedAt time.Time `json:"-"`
+}
+
+// valida exige a cidade e ao menos uma das escalas, já que o Serviço A pode pedir só algumas delas.
+func (t *TemperaturasResponse) valida() error {
+	if t.City == "" {
+		return errors.New("city é obrigatório")
+	}
+	if t.Celcius == "" && t.Fahrenheit == "" && t.Kelvin == "" && t.Rankine == "" {
+		return errors.New("nenhuma temperatura na resposta")
+	}
+	return nil
+}
+
+type TemperaturasV2Response struct {
+	City         string                `json:"city"`
+	Temperatures []TemperaturaResponse `json:"temperatures"`
+	ObservedAt   time.Time             `json:"observed_at"`
+	Condition    string                `json:"condition"`
+}
+
+func (t *TemperaturasV2Response) valida() error {
+	if t.City == "" {
+		return errors.New("city é obrigatório")
+	}
+	if len(t.Temperatures) == 0 {
+		return errors.New("nenhuma temperatura na resposta")
+	}
+	return nil
+}
+
+type TemperaturaResponse struct {
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit"`
+	Symbol string  `json:"symbol"`
 }
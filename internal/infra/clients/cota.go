@@ -0,0 +1,213 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	errTaxaWeatherApiExcedida  = fmt.Errorf("%w: requests per second limit reached", erros.ErrWeatherApiQuotaExceeded)
+	errOrcamentoMensalEsgotado = fmt.Errorf("%w: monthly budget exhausted", erros.ErrWeatherApiQuotaExceeded)
+)
+
+// cotaWeatherApi é compartilhada entre os clients, como chavesWeatherApi, e soma as chamadas de todas as chaves.
+var cotaWeatherApi = newCotaMensal()
+
+type cotaMensal struct {
+	mu    sync.Mutex
+	agora func() time.Time
+
+	cfg        config.CotaConfig
+	carregado  bool
+	mes        string
+	usadas     int
+	tokens     float64
+	atualizado time.Time
+
+	metricas  sync.Once
+	limitadas metric.Int64Counter
+}
+
+// usoWeatherApi é o conteúdo de clima.cota.arquivo_uso.
+type usoWeatherApi struct {
+	Mes         string `json:"mes"`
+	Requisicoes int    `json:"requisicoes"`
+}
+
+func newCotaMensal() *cotaMensal {
+	return &cotaMensal{agora: time.Now}
+}
+
+// reserva registra uma chamada à WeatherAPI antes que ela seja feita. Com a política fila, espera pela
+// vez no limite por segundo até clima.cota.espera_maxima ou até o fim do contexto.
+func (c *cotaMensal) reserva(ctx context.Context, cfg config.CotaConfig) error {
+	c.metricas.Do(c.registraMetricas)
+
+	var prazo time.Time
+	for {
+		espera, err := c.tentaReservar(cfg)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errTaxaWeatherApiExcedida) || cfg.Politica != config.PoliticaFila {
+			c.registraLimitada(ctx, cfg, err)
+			return err
+		}
+
+		if prazo.IsZero() {
+			prazo = c.agora().Add(cfg.EsperaMaxima)
+		}
+		if c.agora().Add(espera).After(prazo) {
+			c.registraLimitada(ctx, cfg, err)
+			return err
+		}
+
+		timer := time.NewTimer(espera)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// tentaReservar consome um token e uma unidade do orçamento ou devolve quanto esperar pelo próximo token.
+func (c *cotaMensal) tentaReservar(cfg config.CotaConfig) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agora := c.agora()
+	if !c.carregado || c.cfg.ArquivoUso != cfg.ArquivoUso {
+		c.carrega(cfg, agora)
+	} else if cfg.Rajada != c.cfg.Rajada {
+		c.tokens = math.Min(c.tokens, float64(cfg.Rajada))
+	}
+	c.cfg = cfg
+
+	if mes := agora.UTC().Format("2006-01"); c.mes != mes {
+		c.mes = mes
+		c.usadas = 0
+	}
+
+	if cfg.OrcamentoMensal > 0 && c.usadas >= cfg.OrcamentoMensal {
+		return 0, errOrcamentoMensalEsgotado
+	}
+
+	if cfg.RequisicoesPorSegundo > 0 {
+		c.tokens = math.Min(float64(cfg.Rajada), c.tokens+agora.Sub(c.atualizado).Seconds()*cfg.RequisicoesPorSegundo)
+		c.atualizado = agora
+		if c.tokens < 1 {
+			return time.Duration((1 - c.tokens) / cfg.RequisicoesPorSegundo * float64(time.Second)), errTaxaWeatherApiExcedida
+		}
+		c.tokens--
+	}
+
+	c.usadas++
+	c.salva()
+	return 0, nil
+}
+
+// carrega lê o consumo gravado, que só vale se for do mês corrente.
+func (c *cotaMensal) carrega(cfg config.CotaConfig, agora time.Time) {
+	arquivo := cfg.ArquivoUso
+	c.carregado = true
+	c.mes = agora.UTC().Format("2006-01")
+	c.usadas = 0
+	c.tokens = float64(cfg.Rajada)
+	c.atualizado = agora
+
+	if arquivo == "" {
+		return
+	}
+
+	conteudo, err := os.ReadFile(arquivo)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	uso := usoWeatherApi{}
+	if err == nil {
+		err = json.Unmarshal(conteudo, &uso)
+	}
+	if err != nil {
+		slog.Warn("falha ao ler o consumo da WeatherAPI, contando a partir de zero", "arquivo", arquivo, "erro", err)
+		return
+	}
+
+	if uso.Mes == c.mes {
+		c.usadas = uso.Requisicoes
+	}
+}
+
+// salva grava o consumo em um arquivo temporário e o renomeia, para não deixar o arquivo pela metade.
+func (c *cotaMensal) salva() {
+	if c.cfg.ArquivoUso == "" {
+		return
+	}
+
+	conteudo, _ := json.Marshal(usoWeatherApi{Mes: c.mes, Requisicoes: c.usadas})
+	temporario := c.cfg.ArquivoUso + ".tmp"
+
+	err := os.MkdirAll(filepath.Dir(c.cfg.ArquivoUso), 0o755)
+	if err == nil {
+		err = os.WriteFile(temporario, conteudo, 0o644)
+	}
+	if err == nil {
+		err = os.Rename(temporario, c.cfg.ArquivoUso)
+	}
+	if err != nil {
+		slog.Warn("falha ao gravar o consumo da WeatherAPI", "arquivo", c.cfg.ArquivoUso, "erro", err)
+	}
+}
+
+func (c *cotaMensal) registraMetricas() {
+	meter := otel.GetMeter("weatherapi")
+
+	c.limitadas, _ = meter.Int64Counter("weatherapi.requisicoes.limitadas",
+		metric.WithDescription("Consultas à WeatherAPI recusadas ou desviadas pelo limite local"),
+		metric.WithUnit("{requisicao}"))
+
+	usadas, _ := meter.Int64ObservableGauge("weatherapi.cota.usada",
+		metric.WithDescription("Chamadas à WeatherAPI feitas no mês corrente (UTC)"),
+		metric.WithUnit("{requisicao}"))
+	restante, _ := meter.Int64ObservableGauge("weatherapi.cota.restante",
+		metric.WithDescription("Chamadas à WeatherAPI que ainda cabem no orçamento mensal"),
+		metric.WithUnit("{requisicao}"))
+
+	meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		o.ObserveInt64(usadas, int64(c.usadas))
+		if c.cfg.OrcamentoMensal > 0 {
+			o.ObserveInt64(restante, int64(max(c.cfg.OrcamentoMensal-c.usadas, 0)))
+		}
+		return nil
+	}, usadas, restante)
+}
+
+func (c *cotaMensal) registraLimitada(ctx context.Context, cfg config.CotaConfig, err error) {
+	motivo := "taxa"
+	if errors.Is(err, errOrcamentoMensalEsgotado) {
+		motivo = "orcamento"
+	}
+
+	c.limitadas.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("motivo", motivo),
+		attribute.String("politica", cfg.Politica),
+	))
+}
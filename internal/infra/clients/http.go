@@ -0,0 +1,157 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/certificados"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/requestid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// transportes mantém um transport por configuração para que as conexões sejam reaproveitadas
+// entre as requisições, já que os clients são criados a cada chamada dos handlers.
+var transportes sync.Map
+
+// newHTTPClient instrumenta o transport da configuração com o otelhttp. Os intermediarios ficam abaixo
+// do otelhttp, então o que eles alteram na requisição não é registrado nos spans.
+func newHTTPClient(cfg config.UpstreamConfig, intermediarios ...func(http.RoundTripper) http.RoundTripper) http.Client {
+	transporte := transportePara(cfg)
+	for _, intermediario := range intermediarios {
+		transporte = intermediario(transporte)
+	}
+
+	return http.Client{
+		Transport: otelhttp.NewTransport(transporte),
+		Timeout:   cfg.Timeout,
+	}
+}
+
+func transportePara(cfg config.UpstreamConfig) http.RoundTripper {
+	if transporte, ok := transportes.Load(cfg); ok {
+		return transporte.(http.RoundTripper)
+	}
+
+	transporte := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   cfg.ConnectTimeout,
+		ResponseHeaderTimeout: cfg.ReadTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConns,
+	}
+	if cfg.H2C {
+		// Sem HTTP/1, o transport fala HTTP/2 direto com o upstream, sem negociação.
+		transporte.Protocols = new(http.Protocols)
+		transporte.Protocols.SetUnencryptedHTTP2(true)
+	}
+	if cfg.Proxy != "" {
+		if proxy, err := url.Parse(cfg.Proxy); err == nil {
+			transporte.Proxy = http.ProxyURL(proxy)
+		}
+	}
+
+	tlsConfig, err := certificados.Cliente(cfg.TLS)
+	if err != nil {
+		// Sem o certificado não há como falar com o upstream; o erro aparece em cada requisição e o
+		// transport não é guardado, para que a próxima tentativa leia os arquivos de novo.
+		return roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	}
+	transporte.TLSClientConfig = tlsConfig
+
+	atual, _ := transportes.LoadOrStore(cfg, transporte)
+	return atual.(http.RoundTripper)
+}
+
+// LiberaTransportes descarta os transports de configurações que deixaram de ser usadas, por exemplo
+// depois de uma recarga que alterou timeouts, fechando as conexões ociosas deles.
+func LiberaTransportes(ativos ...config.UpstreamConfig) {
+	transportes.Range(func(chave, transporte any) bool {
+		if slices.Contains(ativos, chave.(config.UpstreamConfig)) {
+			return true
+		}
+
+		transportes.Delete(chave)
+		transporte.(*http.Transport).CloseIdleConnections()
+		return true
+	})
+}
+
+// baseURL garante a barra final para que os caminhos possam ser concatenados.
+func baseURL(cfg config.UpstreamConfig) string {
+	if strings.HasSuffix(cfg.BaseURL, "/") {
+		return cfg.BaseURL
+	}
+	return cfg.BaseURL + "/"
+}
+
+// erroDeConexao classifica a falha de uma chamada sem resposta do upstream: timeout ou upstream
+// indisponível, ambos retentáveis. O cancelamento pelo próprio cliente segue como está.
+func erroDeConexao(upstream string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var erroRede net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &erroRede) && erroRede.Timeout()) {
+		return erros.NewErroUpstream(upstream, erros.TipoTimeout, fmt.Errorf("%w: %w", erros.ErrUpstreamTimeout, err))
+	}
+	return erros.NewErroUpstream(upstream, erros.TipoUpstreamIndisponivel, fmt.Errorf("%w: %w", erros.ErrUpstreamUnavailable, err))
+}
+
+// erroDeStatus classifica uma resposta de erro do upstream. O 504 é timeout; os demais, upstream
+// indisponível, que só vale repetir nos 5xx e no 429. detalhe, quando houver, é o erro lido do corpo.
+func erroDeStatus(upstream string, resp *http.Response, detalhe error) error {
+	sentinela, tipo := erros.ErrUpstreamUnavailable, erros.TipoUpstreamIndisponivel
+	if resp.StatusCode == http.StatusGatewayTimeout {
+		sentinela, tipo = erros.ErrUpstreamTimeout, erros.TipoTimeout
+	}
+
+	causa := fmt.Errorf("%w: %s", sentinela, resp.Status)
+	if detalhe != nil {
+		causa = fmt.Errorf("%w: %s: %w", sentinela, resp.Status, detalhe)
+	}
+
+	erro := erros.NewErroUpstream(upstream, tipo, causa)
+	erro.Retentavel = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return erro
+}
+
+// propagaRequestID repassa ao upstream o X-Request-ID da requisição em andamento, para que os logs dos
+// dois serviços possam ser correlacionados.
+func propagaRequestID(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		id := requestid.DoContexto(req.Context())
+		if id == "" || req.Header.Get(requestid.Header) != "" {
+			return base.RoundTrip(req)
+		}
+
+		comID := req.Clone(req.Context())
+		comID.Header.Set(requestid.Header, id)
+		return base.RoundTrip(comID)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,185 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenMeteoClient é o provedor de clima alternativo à WeatherAPI. Não exige chave, mas precisa
+// geocodificar a cidade antes de consultar a temperatura.
+type OpenMeteoClient struct {
+	tracer          trace.Tracer
+	uri             string
+	geocodingUri    string
+	client          http.Client
+	geocodingClient http.Client
+}
+
+// resultadosGeocoding é quantos homônimos a geocodificação traz para que o do estado pedido seja escolhido.
+const resultadosGeocoding = 10
+
+type openMeteoGeocodingResponse struct {
+	Results []openMeteoLocal `json:"results"`
+}
+
+// valida aceita uma pesquisa sem resultados, que é a cidade inexistente, mas não um resultado sem nome ou
+// sem estado, que o escolheLocal não teria como comparar.
+func (g *openMeteoGeocodingResponse) valida() error {
+	for _, resultado := range g.Results {
+		if resultado.Name == "" || resultado.Admin1 == "" {
+			return errors.New("name e admin1 são obrigatórios em cada resultado")
+		}
+	}
+	return nil
+}
+
+type openMeteoLocal struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Country   string  `json:"country"`
+	Admin1    string  `json:"admin1"`
+	Timezone  string  `json:"timezone"`
+}
+
+type openMeteoForecastResponse struct {
+	Current struct {
+		Time          int64   `json:"time"`
+		Temperature2m float64 `json:"temperature_2m"`
+	} `json:"current"`
+}
+
+func (f *openMeteoForecastResponse) valida() error {
+	if f.Current.Time == 0 {
+		return errors.New("current.time é obrigatório")
+	}
+	return nil
+}
+
+type openMeteoErrorResponse struct {
+	Reason string `json:"reason"`
+}
+
+func NewOpenMeteoClient(tracer trace.Tracer, cfg config.UpstreamConfig, geocoding config.UpstreamConfig) *OpenMeteoClient {
+	return &OpenMeteoClient{
+		tracer:          tracer,
+		uri:             baseURL(cfg) + "forecast",
+		geocodingUri:    baseURL(geocoding) + "search",
+		client:          newHTTPClient(cfg),
+		geocodingClient: newHTTPClient(geocoding),
+	}
+}
+
+func (c *OpenMeteoClient) ConsultaClima(ctx context.Context, cidade string) (*WeatherResponse, error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "ConsultaClima")
+	defer span.End()
+
+	otel.AddSpanEvent(span, "Iniciando a consulta Open-Meteo", map[string]interface{}{"cidade": cidade})
+
+	weatherResponse := &WeatherResponse{}
+
+	// A geocodificação pesquisa só o nome; o estado de "cidade, estado, país" escolhe entre os homônimos.
+	nome, estado, _ := strings.Cut(cidade, ",")
+	estado, _, _ = strings.Cut(estado, ",")
+
+	geocoding := openMeteoGeocodingResponse{}
+	err := c.consulta(ctx, config.UpstreamOpenMeteoGeocoding, c.geocodingClient, c.geocodingUri, map[string]string{
+		"name":        strings.TrimSpace(nome),
+		"count":       strconv.Itoa(resultadosGeocoding),
+		"language":    i18n.DoContexto(ctx).CodigoOpenMeteo(),
+		"countryCode": "BR",
+	}, &geocoding)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return weatherResponse, err
+	}
+	if len(geocoding.Results) == 0 {
+		return weatherResponse, erros.NewErroUpstream(config.UpstreamOpenMeteoGeocoding, erros.TipoNaoEncontrado, erros.ErrCityNotFound)
+	}
+
+	local := escolheLocal(geocoding.Results, strings.TrimSpace(estado))
+	otel.AddSpanEvent(span, "Cidade geocodificada", map[string]interface{}{"cidade": local.Name, "uf": local.Admin1})
+
+	forecast := openMeteoForecastResponse{}
+	err = c.consulta(ctx, config.UpstreamOpenMeteo, c.client, c.uri, map[string]string{
+		"latitude":   strconv.FormatFloat(local.Latitude, 'f', -1, 64),
+		"longitude":  strconv.FormatFloat(local.Longitude, 'f', -1, 64),
+		"current":    "temperature_2m",
+		"timeformat": "unixtime",
+	}, &forecast)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return weatherResponse, err
+	}
+
+	weatherResponse.Location = Location{
+		Name:    local.Name,
+		Region:  local.Admin1,
+		Country: local.Country,
+		Lat:     local.Latitude,
+		Lon:     local.Longitude,
+		TzID:    local.Timezone,
+	}
+	weatherResponse.Current.TempC = forecast.Current.Temperature2m
+	weatherResponse.Current.LastUpdatedEpoch = int(forecast.Current.Time)
+	weatherResponse.Provedor = config.ProvedorOpenMeteo
+
+	otel.AddSpanEvent(span, "Temperaturas obtidas com sucesso", nil)
+
+	return weatherResponse, nil
+}
+
+func (c *OpenMeteoClient) consulta(ctx context.Context, upstream string, client http.Client, uri string, parametros map[string]string, destino any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	q := req.URL.Query()
+	for chave, valor := range parametros {
+		q.Set(chave, valor)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return erroDeConexao(upstream, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := leCorpo(upstream, resp)
+		errorResponse := openMeteoErrorResponse{}
+		json.Unmarshal(body, &errorResponse)
+		var detalhe error
+		if errorResponse.Reason != "" {
+			detalhe = errors.New(errorResponse.Reason)
+		}
+		return erroDeStatus(upstream, resp, detalhe)
+	}
+
+	return decodificaJSON(ctx, upstream, resp, destino)
+}
+
+// escolheLocal fica com o primeiro resultado do estado pedido. Sem estado ou sem resultado nele, fica com o
+// primeiro, e a divergência é apontada por quem compara a região da resposta.
+func escolheLocal(resultados []openMeteoLocal, estado string) openMeteoLocal {
+	for _, resultado := range resultados {
+		if estado != "" && domain.ChaveEstado(resultado.Admin1) == domain.ChaveEstado(estado) {
+			return resultado
+		}
+	}
+	return resultados[0]
+}
@@ -0,0 +1,132 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type OpenMeteoClientTestSuite struct {
+	suite.Suite
+	languagesRecebidos []string
+	corpoForecast      string
+	corpoGeocoding     string
+	upstream           *httptest.Server
+	client             *OpenMeteoClient
+}
+
+func TestOpenMeteoClientSuite(t *testing.T) {
+	suite.Run(t, new(OpenMeteoClientTestSuite))
+}
+
+func (s *OpenMeteoClientTestSuite) SetupTest() {
+	s.languagesRecebidos = nil
+	s.corpoForecast, s.corpoGeocoding = "", ""
+	s.upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/forecast" && s.corpoForecast != "" {
+			io.WriteString(w, s.corpoForecast)
+			return
+		}
+		if r.URL.Path == "/v1/forecast" {
+			fmt.Fprintf(w, `{"current":{"time":1700000000,"temperature_2m":%s}}`, r.URL.Query().Get("latitude"))
+			return
+		}
+
+		language := r.URL.Query().Get("language")
+		s.languagesRecebidos = append(s.languagesRecebidos, language)
+		if s.corpoGeocoding != "" {
+			io.WriteString(w, s.corpoGeocoding)
+			return
+		}
+		estado := map[string]string{"pt": "Distrito Federal", "es": "Distrito Federal", "en": "Federal District"}[language]
+		fmt.Fprintf(w, `{"results":[
+			{"name":"Brasília","latitude":-19,"longitude":-46,"country":"Brazil","admin1":"Minas Gerais"},
+			{"name":"Brasília","latitude":-15,"longitude":-47,"country":"Brazil","admin1":%q}
+		]}`, estado)
+	}))
+	cfg := config.UpstreamConfig{BaseURL: s.upstream.URL + "/v1/", Timeout: time.Second}
+	s.client = NewOpenMeteoClient(noop.NewTracerProvider().Tracer("teste"), cfg, cfg)
+}
+
+func (s *OpenMeteoClientTestSuite) TearDownTest() {
+	s.upstream.Close()
+}
+
+func (s *OpenMeteoClientTestSuite) TestGeocodificaNoIdiomaDaRequisicao() {
+	cenarios := []struct {
+		idioma   i18n.Idioma
+		language string
+	}{
+		{i18n.PortuguesBrasil, "pt"},
+		{i18n.Espanhol, "es"},
+		{i18n.Ingles, "en"},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(string(cenario.idioma), func() {
+			// Arrange
+			s.languagesRecebidos = nil
+			ctx := i18n.NovoContexto(context.Background(), cenario.idioma)
+
+			// Act
+			weatherResponse, err := s.client.ConsultaClima(ctx, "Brasília, Distrito Federal, Brazil")
+
+			// Assert
+			s.Require().NoError(err)
+			s.Equal([]string{cenario.language}, s.languagesRecebidos)
+			s.Equal(-15.0, weatherResponse.Location.Lat)
+			s.Equal(-15.0, weatherResponse.Current.TempC)
+		})
+	}
+}
+
+func (s *OpenMeteoClientTestSuite) TestRespostasIncompletasViramUpstreamIndisponivel() {
+	cenarios := []struct {
+		nome           string
+		corpoForecast  string
+		corpoGeocoding string
+		upstream       string
+	}{
+		{"forecast sem current.time", `{}`, "", config.UpstreamOpenMeteo},
+		{"geocodificacao sem admin1", "", `{"results":[{"name":"Brasília","latitude":-15,"longitude":-47}]}`, config.UpstreamOpenMeteoGeocoding},
+		{"geocodificacao sem name", "", `{"results":[{"admin1":"Distrito Federal","latitude":-15,"longitude":-47}]}`, config.UpstreamOpenMeteoGeocoding},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			s.corpoForecast, s.corpoGeocoding = cenario.corpoForecast, cenario.corpoGeocoding
+
+			// Act
+			_, err := s.client.ConsultaClima(context.Background(), "Brasília, Distrito Federal, Brazil")
+
+			// Assert
+			classificacao := erros.Classifica(err)
+			s.ErrorIs(err, erros.ErrInvalidUpstreamResponse)
+			s.Equal(erros.TipoUpstreamIndisponivel, classificacao.Tipo)
+			s.Equal(cenario.upstream, classificacao.Upstream)
+		})
+	}
+}
+
+func (s *OpenMeteoClientTestSuite) TestGeocodificacaoSemResultadosECidadeInexistente() {
+	// Arrange
+	s.corpoGeocoding = `{}`
+
+	// Act
+	_, err := s.client.ConsultaClima(context.Background(), "Cidade Nenhuma, Distrito Federal, Brazil")
+
+	// Assert
+	s.ErrorIs(err, erros.ErrCityNotFound)
+}
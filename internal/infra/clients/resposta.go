@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tamanhoMaximoResposta limita o corpo lido de um upstream. As respostas esperadas têm poucos KB; a pesquisa
+// de endereços da ViaCEP, com até 50 endereços, é a maior delas.
+const tamanhoMaximoResposta = 1 << 20
+
+// tamanhoTrecho é quanto do corpo de uma resposta inválida vai para o span.
+const tamanhoTrecho = 256
+
+// validavel é implementado pelas respostas que têm campos obrigatórios.
+type validavel interface {
+	valida() error
+}
+
+// leCorpo lê o corpo da resposta até tamanhoMaximoResposta. Uma falha na leitura é tratada como falha de
+// conexão, já que o upstream parou de responder no meio do corpo.
+func leCorpo(upstream string, resp *http.Response) ([]byte, error) {
+	corpo, err := io.ReadAll(io.LimitReader(resp.Body, tamanhoMaximoResposta+1))
+	if err != nil {
+		return corpo, erroDeConexao(upstream, err)
+	}
+	if len(corpo) > tamanhoMaximoResposta {
+		return corpo[:tamanhoMaximoResposta], fmt.Errorf("%w: corpo maior que %d bytes", erros.ErrInvalidUpstreamResponse, tamanhoMaximoResposta)
+	}
+	return corpo, nil
+}
+
+// decodificaJSON lê a resposta de sucesso de um upstream em destino. O corpo precisa ser JSON, caber em
+// tamanhoMaximoResposta e, quando destino é validavel, trazer os campos obrigatórios. Do contrário, o erro é
+// upstream indisponível e o span do contexto recebe o tamanho e o início do corpo.
+func decodificaJSON(ctx context.Context, upstream string, resp *http.Response, destino any) error {
+	corpo, err := leCorpo(upstream, resp)
+	if err == nil {
+		err = decodifica(resp.Header.Get("Content-Type"), corpo, destino)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, erros.ErrInvalidUpstreamResponse) {
+		return err
+	}
+
+	otel.AddSpanEvent(trace.SpanFromContext(ctx), "Resposta inválida do upstream", map[string]interface{}{
+		"upstream":     upstream,
+		"content_type": resp.Header.Get("Content-Type"),
+		"tamanho":      len(corpo),
+		"trecho":       trecho(corpo),
+	})
+
+	erro := erros.NewErroUpstream(upstream, erros.TipoUpstreamIndisponivel, err)
+	// A mesma resposta tende a se repetir; só vale tentar de novo quando o upstream mudar de comportamento.
+	erro.Retentavel = false
+	return erro
+}
+
+func decodifica(contentType string, corpo []byte, destino any) error {
+	if !ehJSON(contentType) {
+		return fmt.Errorf("%w: content type %q", erros.ErrInvalidUpstreamResponse, contentType)
+	}
+	if err := json.Unmarshal(corpo, destino); err != nil {
+		return fmt.Errorf("%w: %w", erros.ErrInvalidUpstreamResponse, err)
+	}
+	if validavel, ok := destino.(validavel); ok {
+		if err := validavel.valida(); err != nil {
+			return fmt.Errorf("%w: %w", erros.ErrInvalidUpstreamResponse, err)
+		}
+	}
+	return nil
+}
+
+// ehJSON aceita application/json e os tipos com sufixo +json, como application/problem+json.
+func ehJSON(contentType string) bool {
+	tipo, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return tipo == "application/json" || strings.HasSuffix(tipo, "+json")
+}
+
+func trecho(corpo []byte) string {
+	if len(corpo) > tamanhoTrecho {
+		corpo = corpo[:tamanhoTrecho]
+	}
+	return strings.ToValidUTF8(string(corpo), "")
+}
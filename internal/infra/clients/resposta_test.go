@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RespostaTestSuite struct {
+	suite.Suite
+	spans *tracetest.SpanRecorder
+	span  trace.Span
+	ctx   context.Context
+}
+
+func TestRespostaSuite(t *testing.T) {
+	suite.Run(t, new(RespostaTestSuite))
+}
+
+func (s *RespostaTestSuite) SetupTest() {
+	s.spans = tracetest.NewSpanRecorder()
+	s.ctx, s.span = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans)).Tracer("teste").Start(context.Background(), "teste")
+}
+
+func (s *RespostaTestSuite) TearDownTest() {
+	s.span.End()
+}
+
+func resposta(contentType, corpo string) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(corpo))}
+}
+
+func (s *RespostaTestSuite) TestDecodificaRespostaValida() {
+	// Arrange
+	weatherResponse := &WeatherResponse{}
+
+	// Act
+	err := decodificaJSON(s.ctx, config.UpstreamWeatherApi, resposta("application/json; charset=utf-8",
+		`{"location":{"name":"São Paulo"},"current":{"temp_c":25,"last_updated_epoch":1700000000}}`), weatherResponse)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(25.0, weatherResponse.Current.TempC)
+}
+
+func (s *RespostaTestSuite) TestRespostasInvalidasViramUpstreamIndisponivel() {
+	cenarios := []struct {
+		nome        string
+		contentType string
+		corpo       string
+	}{
+		{"pagina html", "text/html", `<html><body>502 Bad Gateway</body></html>`},
+		{"json truncado", "application/json", `{"location":{"name":"São Pa`},
+		{"sem location.name", "application/json", `{"current":{"temp_c":25,"last_updated_epoch":1700000000}}`},
+		{"sem last_updated_epoch", "application/json", `{"location":{"name":"São Paulo"},"current":{"temp_c":0}}`},
+		{"corpo grande demais", "application/json", `{"location":{"name":"` + strings.Repeat("a", tamanhoMaximoResposta) + `"}}`},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Act
+			err := decodificaJSON(s.ctx, config.UpstreamWeatherApi, resposta(cenario.contentType, cenario.corpo), &WeatherResponse{})
+
+			// Assert
+			classificacao := erros.Classifica(err)
+			s.ErrorIs(err, erros.ErrInvalidUpstreamResponse)
+			s.Equal(erros.TipoUpstreamIndisponivel, classificacao.Tipo)
+			s.Equal(config.UpstreamWeatherApi, classificacao.Upstream)
+			s.Equal(http.StatusBadGateway, classificacao.Status)
+		})
+	}
+}
+
+func (s *RespostaTestSuite) TestRespostaInvalidaRegistraTamanhoETrechoNoSpan() {
+	// Arrange
+	corpo := `<html>` + strings.Repeat("x", 1000) + `</html>`
+
+	// Act
+	err := decodificaJSON(s.ctx, config.UpstreamViaCep, resposta("text/html", corpo), &DadosCepResponse{})
+
+	// Assert
+	s.Require().Error(err)
+	s.span.End()
+	s.Require().Len(s.spans.Ended(), 1)
+	eventos := s.spans.Ended()[0].Events()
+	s.Require().Len(eventos, 1)
+	atributos := map[string]string{}
+	for _, atributo := range eventos[0].Attributes {
+		atributos[string(atributo.Key)] = atributo.Value.Emit()
+	}
+	s.Equal("Resposta inválida do upstream", eventos[0].Name)
+	s.Equal(config.UpstreamViaCep, atributos["upstream"])
+	s.Equal("1013", atributos["tamanho"])
+	s.Equal(corpo[:tamanhoTrecho], atributos["trecho"])
+}
+
+func (s *RespostaTestSuite) TestRespostaDeCepInexistenteNaoExigeCampos() {
+	// Arrange
+	dadosCep := &DadosCepResponse{}
+
+	// Act
+	err := decodificaJSON(s.ctx, config.UpstreamViaCep, resposta("application/json", `{"erro": "true"}`), dadosCep)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal("true", dadosCep.Erro)
+}
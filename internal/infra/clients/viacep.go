@@ -2,39 +2,34 @@ package clients
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"time"
+	"net/url"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type ViaCepClient struct {
 	tracer trace.Tracer
+	uri    string
 	client http.Client
 }
 
-var viacepuri = "https://viacep.com.br/ws/"
 var format = "/json/"
 
-func NewViaCepClient(tracer trace.Tracer) *ViaCepClient {
+func NewViaCepClient(tracer trace.Tracer, cfg config.UpstreamConfig) *ViaCepClient {
 	return &ViaCepClient{
 		tracer: tracer,
-		client: http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-			Timeout:   10 * time.Second,
-		},
+		uri:    baseURL(cfg),
+		client: newHTTPClient(cfg),
 	}
 }
 
-func (c *ViaCepClient) ConsultaCep(cep string) (*DadosCepResponse, error) {
-	_, span := otel.StartSpan(context.Background(), c.tracer, "ConsultaCep")
+func (c *ViaCepClient) ConsultaCep(ctx context.Context, cep string) (*DadosCepResponse, error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "ConsultaCep")
 	defer span.End()
 	
 	if !helpers.ValidateZipCode(cep) {
@@ -45,7 +40,7 @@ func (c *ViaCepClient) ConsultaCep(cep string) (*DadosCepResponse, error) {
 
 	dadosCep := &DadosCepResponse{}
 
-	req, err := http.NewRequest("GET", viacepuri+cep+format, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.uri+cep+format, nil)
 	if err != nil {
 		otel.RecordSpanError(span, err)
 		return dadosCep, err
@@ -53,19 +48,23 @@ func (c *ViaCepClient) ConsultaCep(cep string) (*DadosCepResponse, error) {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
+		err = erroDeConexao(config.UpstreamViaCep, err)
 		otel.RecordSpanError(span, err)
 		return dadosCep, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return dadosCep, fmt.Errorf("error fetching data: %s", resp.Status)
+		err := erroDeStatus(config.UpstreamViaCep, resp, nil)
+		otel.RecordSpanError(span, err)
+		return dadosCep, err
+	}
+	if err := decodificaJSON(ctx, config.UpstreamViaCep, resp, dadosCep); err != nil {
+		otel.RecordSpanError(span, err)
+		return dadosCep, err
 	}
-	body, _ := io.ReadAll(resp.Body)
-
-	json.Unmarshal(body, &dadosCep)
 
 	if len(dadosCep.Erro) > 0 {
-		return dadosCep, erros.ErrZipCodeNotFound
+		return dadosCep, erros.NewErroUpstream(config.UpstreamViaCep, erros.TipoNaoEncontrado, erros.ErrZipCodeNotFound)
 	}
 
 	dadosCep.Cep = helpers.NormalizeZipCode(dadosCep.Cep)
@@ -74,3 +73,48 @@ func (c *ViaCepClient) ConsultaCep(cep string) (*DadosCepResponse, error) {
 
 	return dadosCep, nil
 }
+
+// BuscaEnderecos usa a pesquisa de endereços da ViaCEP (/ws/{UF}/{cidade}/{logradouro}/json/), que exige ao
+// menos três caracteres na cidade e no logradouro e devolve até 50 endereços.
+func (c *ViaCepClient) BuscaEnderecos(ctx context.Context, uf, cidade, logradouro string) ([]DadosCepResponse, error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "BuscaEnderecos")
+	defer span.End()
+
+	otel.AddSpanEvent(span, "Iniciando pesquisa de endereços ViaCep", map[string]interface{}{
+		"uf":     uf,
+		"cidade": cidade,
+	})
+
+	caminho := url.PathEscape(uf) + "/" + url.PathEscape(cidade) + "/" + url.PathEscape(logradouro) + format
+	req, err := http.NewRequestWithContext(ctx, "GET", c.uri+caminho, nil)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		err = erroDeConexao(config.UpstreamViaCep, err)
+		otel.RecordSpanError(span, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := erroDeStatus(config.UpstreamViaCep, resp, nil)
+		otel.RecordSpanError(span, err)
+		return nil, err
+	}
+
+	enderecos := []DadosCepResponse{}
+	if err := decodificaJSON(ctx, config.UpstreamViaCep, resp, &enderecos); err != nil {
+		otel.RecordSpanError(span, err)
+		return nil, err
+	}
+	for i := range enderecos {
+		enderecos[i].Cep = helpers.NormalizeZipCode(enderecos[i].Cep)
+	}
+
+	otel.AddSpanEvent(span, "Endereços localizados", map[string]interface{}{"quantidade": len(enderecos)})
+
+	return enderecos, nil
+}
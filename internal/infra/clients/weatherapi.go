@@ -2,51 +2,101 @@ package clients
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type WeatherApiClient struct {
 	tracer trace.Tracer
+	uri    string
 	client http.Client
-	key    string
+	chaves []string
+	clima  config.ClimaConfig
 }
 
-var weatherapiuri = "https://api.weatherapi.com/v1/current.json"
-
-func NewWeatherApiClient(tracer trace.Tracer) *WeatherApiClient {
-	cfg := config.Get()
+var current = "current.json"
 
+func NewWeatherApiClient(tracer trace.Tracer, cfg config.UpstreamConfig, chaves []string, clima config.ClimaConfig) *WeatherApiClient {
 	return &WeatherApiClient{
 		tracer: tracer,
-		client: http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-			Timeout:   10 * time.Second,
-		},
-		key: cfg.GetWeatherApiKey(),
+		uri:    baseURL(cfg) + current,
+		client: newHTTPClient(cfg, injetaChave),
+		chaves: chaves,
+		clima:  clima,
 	}
 }
 
-func (c *WeatherApiClient) ConsultaClima(cidade string) (*WeatherResponse, error) {
-	_, span := otel.StartSpan(context.Background(), c.tracer, "ConsultaClima")
+// ConsultaClima tenta as chaves disponíveis na ordem da estratégia configurada. Uma chave sem cota,
+// inválida ou desabilitada é marcada como indisponível e a consulta segue com a próxima. Cada tentativa
+// passa antes pelo limite local de clima.cota.
+func (c *WeatherApiClient) ConsultaClima(ctx context.Context, cidade string) (*WeatherResponse, error) {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "ConsultaClima")
 	defer span.End()
 
 	otel.AddSpanEvent(span, "Iniciando a consulta WeatherAPI", nil)
 
+	candidatas := chavesWeatherApi.candidatas(c.chaves, c.clima.RotacaoChaves)
+	if len(candidatas) == 0 {
+		err := erros.NewErroUpstream(config.UpstreamWeatherApi, erros.TipoUpstreamCota, erros.ErrWeatherApiQuotaExceeded)
+		otel.RecordSpanError(span, err)
+		return &WeatherResponse{}, err
+	}
+
+	for _, chave := range candidatas {
+		if err := cotaWeatherApi.reserva(ctx, c.clima.Cota); err != nil {
+			if errors.Is(err, erros.ErrWeatherApiQuotaExceeded) {
+				erroCota := erros.NewErroUpstream(config.UpstreamWeatherApi, erros.TipoUpstreamCota, err)
+				// O limite por segundo libera a próxima requisição logo; o orçamento mensal, só no mês seguinte.
+				erroCota.Retentavel = errors.Is(err, errTaxaWeatherApiExcedida)
+				err = erroCota
+			}
+			otel.RecordSpanError(span, err)
+			return &WeatherResponse{}, err
+		}
+
+		weatherResponse, err := c.consulta(ctx, cidade, chave)
+
+		weatherErrorResponse := WeatherErrorResponse{}
+		if errors.As(err, &weatherErrorResponse) && chaveIndisponivel(weatherErrorResponse.ErrorCode()) {
+			chavesWeatherApi.marcaIndisponivel(chave, weatherErrorResponse.ErrorCode())
+			otel.AddSpanEvent(span, "Chave da WeatherAPI indisponível, tentando a próxima", map[string]interface{}{
+				"chave_id": identificaChave(chave),
+				"codigo":   weatherErrorResponse.ErrorCode(),
+			})
+			continue
+		}
+		if err != nil {
+			otel.RecordSpanError(span, err)
+			return weatherResponse, err
+		}
+
+		otel.AddSpanEvent(span, "Temperaturas obtidas com sucesso", map[string]interface{}{"chave_id": identificaChave(chave)})
+		return weatherResponse, nil
+	}
+
+	err := erros.NewErroUpstream(config.UpstreamWeatherApi, erros.TipoUpstreamCota, erros.ErrWeatherApiQuotaExceeded)
+	otel.RecordSpanError(span, err)
+	return &WeatherResponse{}, err
+}
+
+func (c *WeatherApiClient) consulta(ctx context.Context, cidade, chave string) (*WeatherResponse, error) {
 	weatherResponse := &WeatherResponse{}
-	weatherErrorResponse := WeatherErrorResponse{}
+	// A WeatherAPI envolve o erro em {"error": {"code": ..., "message": ...}}.
+	weatherErrorBody := struct {
+		Error WeatherErrorResponse `json:"error"`
+	}{}
 
-	req, err := http.NewRequest("GET", weatherapiuri, nil)
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, chaveContexto{}, chave), "GET", c.uri, nil)
 	if err != nil {
-		otel.RecordSpanError(span, err)
 		return weatherResponse, err
 	}
 
@@ -54,34 +104,63 @@ func (c *WeatherApiClient) ConsultaClima(cidade string) (*WeatherResponse, error
 	url := req.URL
 	q := url.Query()
 	q.Set("q", cidade)
-	q.Set("lang", "pt")
-	q.Set("key", c.key)
+	if lang := i18n.DoContexto(ctx).CodigoWeatherApi(); lang != "" {
+		q.Set("lang", lang)
+	}
 	url.RawQuery = q.Encode()
 
 	req.URL = url
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		otel.RecordSpanError(span, err)
-		return weatherResponse, err
+		return weatherResponse, erroDeConexao(config.UpstreamWeatherApi, err)
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-
 	if resp.StatusCode != http.StatusOK {
-		json.Unmarshal(body, &weatherErrorResponse)
+		// O corpo de erro só complementa o status; um corpo ilegível não muda a classificação.
+		body, _ := leCorpo(config.UpstreamWeatherApi, resp)
+		json.Unmarshal(body, &weatherErrorBody)
 
-		if weatherErrorResponse.ErrorCode() == 1006 {
-			return weatherResponse, erros.ErrCityNotFound
+		if weatherErrorBody.Error.ErrorCode() == 1006 {
+			return weatherResponse, erros.NewErroUpstream(config.UpstreamWeatherApi, erros.TipoNaoEncontrado, erros.ErrCityNotFound)
 		}
 
-		return weatherResponse, weatherErrorResponse
+		// O código da WeatherAPI continua acessível por errors.As, para a rotação das chaves, mas não chega
+		// ao cliente.
+		return weatherResponse, erroDeStatus(config.UpstreamWeatherApi, resp, weatherErrorBody.Error)
 	}
 
-	json.Unmarshal(body, &weatherResponse)
-
-	otel.AddSpanEvent(span, "Temperaturas obtidas com sucesso", nil)
+	if err := decodificaJSON(ctx, config.UpstreamWeatherApi, resp, weatherResponse); err != nil {
+		return weatherResponse, err
+	}
+	weatherResponse.Provedor = config.ProvedorWeatherApi
 
 	return weatherResponse, nil
 }
+
+type chaveContexto struct{}
+
+// injetaChave adiciona o parâmetro key abaixo do otelhttp, de modo que a chave não aparece na URL
+// registrada nos spans nem nas mensagens de erro do http.Client, que usam a requisição original.
+func injetaChave(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		chave, _ := req.Context().Value(chaveContexto{}).(string)
+		if chave == "" {
+			return base.RoundTrip(req)
+		}
+
+		comChave := req.Clone(req.Context())
+		q := comChave.URL.Query()
+		q.Set("key", chave)
+		comChave.URL.RawQuery = q.Encode()
+
+		return base.RoundTrip(comChave)
+	})
+}
+
+// identificaChave gera um identificador curto e estável para citar a chave em spans e logs sem expô-la.
+func identificaChave(chave string) string {
+	hash := sha256.Sum256([]byte(chave))
+	return hex.EncodeToString(hash[:4])
+}
@@ -0,0 +1,267 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type WeatherApiClientTestSuite struct {
+	suite.Suite
+	chavesRecebidas []string
+	langsRecebidos  []string
+	upstream        *httptest.Server
+	cota            config.CotaConfig
+}
+
+func TestWeatherApiClientSuite(t *testing.T) {
+	suite.Run(t, new(WeatherApiClientTestSuite))
+}
+
+func (s *WeatherApiClientTestSuite) SetupTest() {
+	chavesWeatherApi = newRotacaoChaves()
+	cotaWeatherApi = newCotaMensal()
+	s.cota = config.CotaConfig{Rajada: 1, Politica: config.PoliticaFila}
+	s.chavesRecebidas = nil
+	s.langsRecebidos = nil
+	s.upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chave := r.URL.Query().Get("key")
+		s.chavesRecebidas = append(s.chavesRecebidas, chave)
+		s.langsRecebidos = append(s.langsRecebidos, r.URL.Query().Get("lang"))
+		w.Header().Set("Content-Type", "application/json")
+
+		if chave == "sem-cota" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`))
+			return
+		}
+		if chave == "falha-interna" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"code":9999,"message":"Internal application error."}}`))
+			return
+		}
+		w.Write([]byte(`{"location":{"name":"São Paulo"},"current":{"temp_c":25,"last_updated_epoch":1700000000,"condition":{"text":"Ensolarado"}}}`))
+	}))
+}
+
+func (s *WeatherApiClientTestSuite) TearDownTest() {
+	s.upstream.Close()
+}
+
+func (s *WeatherApiClientTestSuite) novoClient(estrategia string, chaves ...string) *WeatherApiClient {
+	return NewWeatherApiClient(noop.NewTracerProvider().Tracer("teste"), config.UpstreamConfig{
+		BaseURL: s.upstream.URL + "/v1/",
+		Timeout: time.Second,
+	}, chaves, config.ClimaConfig{RotacaoChaves: estrategia, Cota: s.cota})
+}
+
+func (s *WeatherApiClientTestSuite) TestFailoverQuandoChaveEstaSemCota() {
+	// Arrange
+	client := s.novoClient(config.RotacaoFailover, "sem-cota", "reserva")
+
+	// Act
+	primeira, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+	_, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.Require().NoError(errPrimeira)
+	s.Require().NoError(errSegunda)
+	s.Equal(25.0, primeira.Current.TempC)
+	s.Equal([]string{"sem-cota", "reserva", "reserva"}, s.chavesRecebidas)
+}
+
+func (s *WeatherApiClientTestSuite) TestRepassaIdiomaDaRequisicaoEmLang() {
+	// Arrange
+	client := s.novoClient(config.RotacaoFailover, "reserva")
+
+	// Act
+	resposta, err := client.ConsultaClima(i18n.NovoContexto(context.Background(), i18n.PortuguesBrasil), "São Paulo")
+	_, errIngles := client.ConsultaClima(i18n.NovoContexto(context.Background(), i18n.Ingles), "São Paulo")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Require().NoError(errIngles)
+	s.Equal("Ensolarado", resposta.Current.Condition.Text)
+	s.Equal([]string{"pt", ""}, s.langsRecebidos)
+}
+
+func (s *WeatherApiClientTestSuite) TestRoundRobinAlternaAsChaves() {
+	// Arrange
+	client := s.novoClient(config.RotacaoRoundRobin, "chave-1", "chave-2")
+
+	// Act
+	for range 3 {
+		_, err := client.ConsultaClima(context.Background(), "São Paulo")
+		s.Require().NoError(err)
+	}
+
+	// Assert
+	s.Equal([]string{"chave-1", "chave-2", "chave-1"}, s.chavesRecebidas)
+}
+
+func (s *WeatherApiClientTestSuite) TestTodasAsChavesSemCota() {
+	// Arrange
+	client := s.novoClient(config.RotacaoFailover, "sem-cota")
+
+	// Act
+	_, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+	_, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.ErrorIs(errPrimeira, erros.ErrWeatherApiQuotaExceeded)
+	s.ErrorIs(errSegunda, erros.ErrWeatherApiQuotaExceeded)
+	s.Len(s.chavesRecebidas, 1)
+}
+
+func (s *WeatherApiClientTestSuite) TestErroDeConexaoNaoExpoeAChave() {
+	// Arrange
+	client := s.novoClient(config.RotacaoFailover, "chave-secreta")
+	s.upstream.Close()
+
+	// Act
+	_, err := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.Require().Error(err)
+	s.NotContains(err.Error(), "chave-secreta")
+	s.NotContains(err.Error(), "key=")
+}
+
+func (s *WeatherApiClientTestSuite) TestFalhaDaWeatherApiEhUpstreamIndisponivel() {
+	// Arrange
+	client := s.novoClient(config.RotacaoFailover, "falha-interna")
+
+	// Act
+	_, err := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	classificacao := erros.Classifica(err)
+	s.ErrorIs(err, erros.ErrUpstreamUnavailable)
+	s.Equal(erros.TipoUpstreamIndisponivel, classificacao.Tipo)
+	s.Equal(config.UpstreamWeatherApi, classificacao.Upstream)
+	s.True(classificacao.Retentavel)
+	s.Equal(http.StatusBadGateway, classificacao.Status)
+}
+
+func (s *WeatherApiClientTestSuite) TestOrcamentoMensalPersisteEntreReinicios() {
+	// Arrange
+	s.cota.OrcamentoMensal = 2
+	s.cota.ArquivoUso = filepath.Join(s.T().TempDir(), "dados", "uso-weatherapi.json")
+	client := s.novoClient(config.RotacaoFailover, "chave-1")
+	_, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Act
+	cotaWeatherApi = newCotaMensal()
+	_, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+	_, errTerceira := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.NoError(errPrimeira)
+	s.NoError(errSegunda)
+	s.ErrorIs(errTerceira, erros.ErrWeatherApiQuotaExceeded)
+	s.ErrorIs(errTerceira, errOrcamentoMensalEsgotado)
+	s.Len(s.chavesRecebidas, 2)
+	conteudo, err := os.ReadFile(s.cota.ArquivoUso)
+	s.Require().NoError(err)
+	s.JSONEq(fmt.Sprintf(`{"mes":%q,"requisicoes":2}`, time.Now().UTC().Format("2006-01")), string(conteudo))
+}
+
+func (s *WeatherApiClientTestSuite) TestOrcamentoDeOutroMesNaoConta() {
+	// Arrange
+	s.cota.OrcamentoMensal = 1
+	s.cota.ArquivoUso = filepath.Join(s.T().TempDir(), "uso-weatherapi.json")
+	s.Require().NoError(os.WriteFile(s.cota.ArquivoUso, []byte(`{"mes":"2000-01","requisicoes":1}`), 0o644))
+	client := s.novoClient(config.RotacaoFailover, "chave-1")
+
+	// Act
+	_, err := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.NoError(err)
+}
+
+func (s *WeatherApiClientTestSuite) TestPoliticaFalhaRecusaAcimaDaTaxa() {
+	// Arrange
+	s.cota.RequisicoesPorSegundo = 0.1
+	s.cota.Politica = config.PoliticaFalha
+	client := s.novoClient(config.RotacaoFailover, "chave-1")
+	_, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Act
+	_, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.NoError(errPrimeira)
+	s.ErrorIs(errSegunda, errTaxaWeatherApiExcedida)
+	s.Len(s.chavesRecebidas, 1)
+}
+
+func (s *WeatherApiClientTestSuite) TestPoliticaFilaEsperaPelaVez() {
+	// Arrange
+	s.cota.RequisicoesPorSegundo = 20
+	s.cota.EsperaMaxima = time.Second
+	client := s.novoClient(config.RotacaoFailover, "chave-1")
+	inicio := time.Now()
+
+	// Act
+	_, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+	_, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.NoError(errPrimeira)
+	s.NoError(errSegunda)
+	s.GreaterOrEqual(time.Since(inicio), 40*time.Millisecond)
+	s.Len(s.chavesRecebidas, 2)
+}
+
+func (s *WeatherApiClientTestSuite) TestPoliticaFilaRecusaAlemDaEsperaMaxima() {
+	// Arrange
+	s.cota.RequisicoesPorSegundo = 0.1
+	s.cota.EsperaMaxima = 10 * time.Millisecond
+	client := s.novoClient(config.RotacaoFailover, "chave-1")
+	client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Act
+	_, err := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.ErrorIs(err, errTaxaWeatherApiExcedida)
+}
+
+type weatherClientFunc func(ctx context.Context, cidade string) (*WeatherResponse, error)
+
+func (f weatherClientFunc) ConsultaClima(ctx context.Context, cidade string) (*WeatherResponse, error) {
+	return f(ctx, cidade)
+}
+
+func (s *WeatherApiClientTestSuite) TestPoliticaAlternativoConsultaOutroProvedor() {
+	// Arrange
+	s.cota.OrcamentoMensal = 1
+	s.cota.Politica = config.PoliticaAlternativo
+	tracer := noop.NewTracerProvider().Tracer("teste")
+	alternativo := weatherClientFunc(func(ctx context.Context, cidade string) (*WeatherResponse, error) {
+		return &WeatherResponse{Location: Location{Name: "Alternativo"}}, nil
+	})
+	client := NewWeatherClientComAlternativo(tracer, s.novoClient(config.RotacaoFailover, "chave-1"), alternativo)
+
+	// Act
+	primeira, errPrimeira := client.ConsultaClima(context.Background(), "São Paulo")
+	segunda, errSegunda := client.ConsultaClima(context.Background(), "São Paulo")
+
+	// Assert
+	s.NoError(errPrimeira)
+	s.NoError(errSegunda)
+	s.Equal("São Paulo", primeira.Location.Name)
+	s.Equal("Alternativo", segunda.Location.Name)
+}
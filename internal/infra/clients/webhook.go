@@ -0,0 +1,142 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers das entregas de webhook. A assinatura é o HMAC-SHA256, com o segredo da assinatura, de
+// "<timestamp>.<corpo>", em hexadecimal e com o prefixo "sha256=".
+const (
+	HeaderWebhookID         = "X-Webhook-Id"
+	HeaderWebhookTimestamp  = "X-Webhook-Timestamp"
+	HeaderWebhookAssinatura = "X-Webhook-Signature"
+)
+
+// WebhookClient entrega os alertas nas URLs das assinaturas. O otelhttp injeta o traceparent, então a
+// entrega aparece no mesmo trace da avaliação que a disparou.
+type WebhookClient struct {
+	tracer trace.Tracer
+	client http.Client
+}
+
+// NewWebhookClient não segue redirecionamentos, para que um destino permitido não leve a entrega a um
+// endereço interno.
+func NewWebhookClient(tracer trace.Tracer, cfg config.AlertasConfig) *WebhookClient {
+	return &WebhookClient{
+		tracer: tracer,
+		client: http.Client{
+			Transport: otelhttp.NewTransport(transporteWebhook(cfg.Timeout)),
+			Timeout:   cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// transportesWebhook guarda um transport por timeout, como transportes faz para os upstreams.
+var transportesWebhook sync.Map
+
+// destinoWebhookPermitido é conferido a cada conexão, com o endereço já resolvido, o que também cobre um
+// nome que passou a apontar para um endereço interno depois da criação da assinatura.
+var destinoWebhookPermitido = alertas.DestinoPermitido
+
+// transporteWebhook não usa proxy: por ele, a conferência veria o endereço do proxy, e não o do destino.
+func transporteWebhook(timeout time.Duration) http.RoundTripper {
+	if transporte, ok := transportesWebhook.Load(timeout); ok {
+		return transporte.(http.RoundTripper)
+	}
+
+	transporte := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+			Control: func(_, endereco string, _ syscall.RawConn) error {
+				destino, err := netip.ParseAddrPort(endereco)
+				if err != nil || !destinoWebhookPermitido(destino.Addr()) {
+					return fmt.Errorf("%w: destino %s não permitido", erros.ErrWebhookRejected, endereco)
+				}
+				return nil
+			},
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          10,
+		MaxIdleConnsPerHost:   10,
+	}
+
+	atual, _ := transportesWebhook.LoadOrStore(timeout, transporte)
+	return atual.(http.RoundTripper)
+}
+
+// Entrega faz uma tentativa de POST do corpo em url. Respostas 2xx são sucesso. Os redirecionamentos, os
+// destinos internos e os demais status 4xx, exceto 408 e 429, retornam erros.ErrWebhookRejected, que não
+// deve ser tentado de novo.
+func (c *WebhookClient) Entrega(ctx context.Context, url, segredo, eventoID string, corpo []byte) error {
+	ctx, span := otel.StartSpan(ctx, c.tracer, "EntregaWebhook")
+	defer span.End()
+	span.SetAttributes(attribute.String("webhook.evento_id", eventoID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(corpo))
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return fmt.Errorf("%w: %v", erros.ErrWebhookRejected, err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, eventoID)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookAssinatura, AssinaWebhook(segredo, timestamp, corpo))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("webhook respondeu %d", resp.StatusCode)
+	if resp.StatusCode >= 300 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		err = fmt.Errorf("%w: status %d", erros.ErrWebhookRejected, resp.StatusCode)
+	}
+	otel.RecordSpanError(span, err)
+	return err
+}
+
+// AssinaWebhook calcula o valor do header X-Webhook-Signature, que o destino recalcula para conferir a
+// origem e a integridade da entrega.
+func AssinaWebhook(segredo, timestamp string, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
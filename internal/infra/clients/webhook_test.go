@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type WebhookClientTestSuite struct {
+	suite.Suite
+	status   int
+	recebida *http.Request
+	corpo    []byte
+	destino  *httptest.Server
+	client   *WebhookClient
+}
+
+func TestWebhookClientSuite(t *testing.T) {
+	suite.Run(t, new(WebhookClientTestSuite))
+}
+
+func (s *WebhookClientTestSuite) SetupTest() {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	s.status = http.StatusNoContent
+	s.recebida = nil
+	s.destino = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.recebida = r
+		s.corpo, _ = io.ReadAll(r.Body)
+		w.WriteHeader(s.status)
+	}))
+	tracer := sdktrace.NewTracerProvider().Tracer("teste")
+	s.client = NewWebhookClient(tracer, config.AlertasConfig{Timeout: time.Second})
+	// O destino dos testes escuta em 127.0.0.1, que as entregas reais recusam.
+	destinoWebhookPermitido = func(netip.Addr) bool { return true }
+}
+
+func (s *WebhookClientTestSuite) TearDownTest() {
+	destinoWebhookPermitido = alertas.DestinoPermitido
+	s.destino.Close()
+}
+
+func (s *WebhookClientTestSuite) TestEntregaAssinadaNoTraceDaAvaliacao() {
+	// Arrange
+	ctx, avaliacao := sdktrace.NewTracerProvider().Tracer("teste").Start(context.Background(), "AvaliaCep")
+	defer avaliacao.End()
+	corpo := []byte(`{"id":"e1"}`)
+
+	// Act
+	err := s.client.Entrega(ctx, s.destino.URL, "segredo-com-16-caracteres", "e1", corpo)
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(corpo, s.corpo)
+	s.Equal("e1", s.recebida.Header.Get(HeaderWebhookID))
+	timestamp := s.recebida.Header.Get(HeaderWebhookTimestamp)
+	s.NotEmpty(timestamp)
+	s.Equal(AssinaWebhook("segredo-com-16-caracteres", timestamp, corpo), s.recebida.Header.Get(HeaderWebhookAssinatura))
+	s.True(strings.HasPrefix(s.recebida.Header.Get(HeaderWebhookAssinatura), "sha256="))
+	s.Contains(s.recebida.Header.Get("traceparent"), avaliacao.SpanContext().TraceID().String())
+}
+
+func (s *WebhookClientTestSuite) TestStatusRecusadoNaoDeveSerRepetido() {
+	cenarios := []struct {
+		status   int
+		recusado bool
+	}{
+		{http.StatusFound, true},
+		{http.StatusBadRequest, true},
+		{http.StatusGone, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusRequestTimeout, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(http.StatusText(cenario.status), func() {
+			// Arrange
+			s.status = cenario.status
+
+			// Act
+			err := s.client.Entrega(context.Background(), s.destino.URL, "segredo", "e1", []byte(`{}`))
+
+			// Assert
+			s.Require().Error(err)
+			s.Equal(cenario.recusado, errors.Is(err, erros.ErrWebhookRejected))
+		})
+	}
+}
+
+func (s *WebhookClientTestSuite) TestRecusaDestinoInternoAoConectar() {
+	// Arrange
+	destinoWebhookPermitido = alertas.DestinoPermitido
+
+	// Act
+	err := s.client.Entrega(context.Background(), s.destino.URL, "segredo", "e1", []byte(`{}`))
+
+	// Assert
+	s.ErrorIs(err, erros.ErrWebhookRejected)
+	s.Nil(s.recebida)
+}
+
+func (s *WebhookClientTestSuite) TestNaoSegueRedirecionamento() {
+	// Arrange
+	redireciona := httptest.NewServer(http.RedirectHandler(s.destino.URL, http.StatusTemporaryRedirect))
+	defer redireciona.Close()
+
+	// Act
+	err := s.client.Entrega(context.Background(), redireciona.URL, "segredo", "e1", []byte(`{}`))
+
+	// Assert
+	s.ErrorIs(err, erros.ErrWebhookRejected)
+	s.Nil(s.recebida)
+}
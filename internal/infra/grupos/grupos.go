@@ -0,0 +1,34 @@
+package grupos
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+)
+
+// Grupo é uma lista nomeada de CEPs de um cliente da API. O mesmo nome pode existir para clientes
+// diferentes. Sem autenticação, todos os grupos pertencem ao cliente vazio.
+type Grupo struct {
+	Cliente      string
+	Nome         string
+	Ceps         []string
+	CriadoEm     time.Time
+	AtualizadoEm time.Time
+}
+
+type Repositorio interface {
+	Salva(ctx context.Context, grupo Grupo) error
+	Busca(ctx context.Context, cliente, nome string) (Grupo, error)
+	Lista(ctx context.Context, cliente string) ([]Grupo, error)
+	Remove(ctx context.Context, cliente, nome string) error
+	Fecha() error
+}
+
+// Abre o repositório da configuração: SQLite em grupos.arquivo ou, sem arquivo, em memória.
+func Abre(cfg config.GruposConfig) (Repositorio, error) {
+	if cfg.Arquivo == "" {
+		return NewRepositorioMemoria(), nil
+	}
+	return NewRepositorioSQLite(cfg.Arquivo)
+}
@@ -0,0 +1,102 @@
+package grupos
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositorioTestSuite struct {
+	suite.Suite
+	novo        func() Repositorio
+	repositorio Repositorio
+	grupo       Grupo
+}
+
+func TestRepositorioMemoriaSuite(t *testing.T) {
+	suite.Run(t, &RepositorioTestSuite{novo: func() Repositorio { return NewRepositorioMemoria() }})
+}
+
+func TestRepositorioSQLiteSuite(t *testing.T) {
+	s := &RepositorioTestSuite{}
+	s.novo = func() Repositorio {
+		repositorio, err := NewRepositorioSQLite(filepath.Join(s.T().TempDir(), "dados", "grupos.db"))
+		s.Require().NoError(err)
+		return repositorio
+	}
+	suite.Run(t, s)
+}
+
+func (s *RepositorioTestSuite) SetupTest() {
+	s.repositorio = s.novo()
+	criadoEm := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	s.grupo = Grupo{
+		Cliente:      "cliente-a",
+		Nome:         "armazens-sp",
+		Ceps:         []string{"20040002", "01001000"},
+		CriadoEm:     criadoEm,
+		AtualizadoEm: criadoEm,
+	}
+	s.Require().NoError(s.repositorio.Salva(context.Background(), s.grupo))
+}
+
+func (s *RepositorioTestSuite) TearDownTest() {
+	s.NoError(s.repositorio.Fecha())
+}
+
+func (s *RepositorioTestSuite) TestGruposSaoSeparadosPorCliente() {
+	// Arrange
+	outro := s.grupo
+	outro.Cliente = "cliente-b"
+	outro.Ceps = []string{"01310100"}
+	segundo := s.grupo
+	segundo.Nome = "armazens-rj"
+	s.Require().NoError(s.repositorio.Salva(context.Background(), outro))
+	s.Require().NoError(s.repositorio.Salva(context.Background(), segundo))
+
+	// Act
+	encontrado, err := s.repositorio.Busca(context.Background(), "cliente-a", "armazens-sp")
+	s.Require().NoError(err)
+	doCliente, err := s.repositorio.Lista(context.Background(), "cliente-a")
+	s.Require().NoError(err)
+	_, errOutroCliente := s.repositorio.Busca(context.Background(), "cliente-c", "armazens-sp")
+
+	// Assert
+	s.Equal(s.grupo, encontrado, "os CEPs mantêm a ordem do grupo")
+	s.Equal([]Grupo{segundo, s.grupo}, doCliente)
+	s.ErrorIs(errOutroCliente, erros.ErrGroupNotFound)
+}
+
+func (s *RepositorioTestSuite) TestSalvaSubstituiMantendoACriacao() {
+	// Arrange
+	alterado := s.grupo
+	alterado.Ceps = []string{"01001000"}
+	alterado.CriadoEm = s.grupo.CriadoEm.Add(time.Hour)
+	alterado.AtualizadoEm = s.grupo.CriadoEm.Add(time.Hour)
+
+	// Act
+	s.Require().NoError(s.repositorio.Salva(context.Background(), alterado))
+	encontrado, err := s.repositorio.Busca(context.Background(), "cliente-a", "armazens-sp")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal([]string{"01001000"}, encontrado.Ceps)
+	s.Equal(s.grupo.CriadoEm, encontrado.CriadoEm)
+	s.Equal(alterado.AtualizadoEm, encontrado.AtualizadoEm)
+}
+
+func (s *RepositorioTestSuite) TestRemove() {
+	// Act
+	err := s.repositorio.Remove(context.Background(), "cliente-a", "armazens-sp")
+	lista, errLista := s.repositorio.Lista(context.Background(), "cliente-a")
+
+	// Assert
+	s.Require().NoError(err)
+	s.Require().NoError(errLista)
+	s.Empty(lista)
+	s.ErrorIs(s.repositorio.Remove(context.Background(), "cliente-a", "armazens-sp"), erros.ErrGroupNotFound)
+}
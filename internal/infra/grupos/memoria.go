@@ -0,0 +1,82 @@
+package grupos
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+)
+
+// RepositorioMemoria guarda os grupos enquanto o processo estiver de pé. É usado nos testes e quando
+// grupos.arquivo não está definido.
+type RepositorioMemoria struct {
+	mu     sync.RWMutex
+	grupos map[chave]Grupo
+}
+
+type chave struct {
+	cliente string
+	nome    string
+}
+
+func NewRepositorioMemoria() *RepositorioMemoria {
+	return &RepositorioMemoria{grupos: map[chave]Grupo{}}
+}
+
+func (r *RepositorioMemoria) Salva(_ context.Context, grupo Grupo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Como no SQLite, a substituição mantém a data de criação.
+	if atual, ok := r.grupos[chave{grupo.Cliente, grupo.Nome}]; ok {
+		grupo.CriadoEm = atual.CriadoEm
+	}
+	grupo.Ceps = slices.Clone(grupo.Ceps)
+	r.grupos[chave{grupo.Cliente, grupo.Nome}] = grupo
+	return nil
+}
+
+func (r *RepositorioMemoria) Busca(_ context.Context, cliente, nome string) (Grupo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	grupo, ok := r.grupos[chave{cliente, nome}]
+	if !ok {
+		return Grupo{}, erros.ErrGroupNotFound
+	}
+	grupo.Ceps = slices.Clone(grupo.Ceps)
+	return grupo, nil
+}
+
+func (r *RepositorioMemoria) Lista(_ context.Context, cliente string) ([]Grupo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	grupos := []Grupo{}
+	for _, grupo := range r.grupos {
+		if grupo.Cliente == cliente {
+			grupo.Ceps = slices.Clone(grupo.Ceps)
+			grupos = append(grupos, grupo)
+		}
+	}
+	slices.SortFunc(grupos, func(a, b Grupo) int { return strings.Compare(a.Nome, b.Nome) })
+
+	return grupos, nil
+}
+
+func (r *RepositorioMemoria) Remove(_ context.Context, cliente, nome string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.grupos[chave{cliente, nome}]; !ok {
+		return erros.ErrGroupNotFound
+	}
+	delete(r.grupos, chave{cliente, nome})
+	return nil
+}
+
+func (r *RepositorioMemoria) Fecha() error {
+	return nil
+}
@@ -0,0 +1,119 @@
+package grupos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	_ "modernc.org/sqlite"
+)
+
+// esquema cria a tabela na primeira abertura. Os CEPs ficam separados por vírgula, na ordem do grupo, e
+// os instantes em nanossegundos desde a época (UTC), como no histórico.
+const esquema = `
+CREATE TABLE IF NOT EXISTS grupos (
+	cliente       TEXT    NOT NULL,
+	nome          TEXT    NOT NULL,
+	ceps          TEXT    NOT NULL,
+	criado_em     INTEGER NOT NULL,
+	atualizado_em INTEGER NOT NULL,
+	PRIMARY KEY (cliente, nome)
+);
+`
+
+// RepositorioSQLite grava os grupos em um arquivo SQLite embarcado.
+type RepositorioSQLite struct {
+	db *sql.DB
+}
+
+func NewRepositorioSQLite(arquivo string) (*RepositorioSQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(arquivo), 0o755); err != nil {
+		return nil, fmt.Errorf("falha ao criar o diretório dos grupos: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+arquivo+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir os grupos %s: %w", arquivo, err)
+	}
+	if _, err := db.Exec(esquema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao preparar os grupos %s: %w", arquivo, err)
+	}
+
+	return &RepositorioSQLite{db: db}, nil
+}
+
+// Salva cria o grupo ou substitui o de mesmo cliente e nome, preservando a data de criação.
+func (r *RepositorioSQLite) Salva(ctx context.Context, grupo Grupo) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO grupos (cliente, nome, ceps, criado_em, atualizado_em) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (cliente, nome) DO UPDATE SET ceps = excluded.ceps, atualizado_em = excluded.atualizado_em`,
+		grupo.Cliente, grupo.Nome, strings.Join(grupo.Ceps, ","), grupo.CriadoEm.UnixNano(), grupo.AtualizadoEm.UnixNano(),
+	)
+	return err
+}
+
+func (r *RepositorioSQLite) Busca(ctx context.Context, cliente, nome string) (Grupo, error) {
+	linha := r.db.QueryRowContext(ctx, colunas+` WHERE cliente = ? AND nome = ?`, cliente, nome)
+	grupo, err := leGrupo(linha)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Grupo{}, erros.ErrGroupNotFound
+	}
+	return grupo, err
+}
+
+func (r *RepositorioSQLite) Lista(ctx context.Context, cliente string) ([]Grupo, error) {
+	linhas, err := r.db.QueryContext(ctx, colunas+` WHERE cliente = ? ORDER BY nome`, cliente)
+	if err != nil {
+		return nil, err
+	}
+	defer linhas.Close()
+
+	grupos := []Grupo{}
+	for linhas.Next() {
+		grupo, err := leGrupo(linhas)
+		if err != nil {
+			return nil, err
+		}
+		grupos = append(grupos, grupo)
+	}
+	return grupos, linhas.Err()
+}
+
+func (r *RepositorioSQLite) Remove(ctx context.Context, cliente, nome string) error {
+	resultado, err := r.db.ExecContext(ctx, `DELETE FROM grupos WHERE cliente = ? AND nome = ?`, cliente, nome)
+	if err != nil {
+		return err
+	}
+	if afetadas, err := resultado.RowsAffected(); err != nil {
+		return err
+	} else if afetadas == 0 {
+		return erros.ErrGroupNotFound
+	}
+	return nil
+}
+
+func (r *RepositorioSQLite) Fecha() error {
+	return r.db.Close()
+}
+
+const colunas = `SELECT cliente, nome, ceps, criado_em, atualizado_em FROM grupos`
+
+func leGrupo(linha interface{ Scan(...any) error }) (Grupo, error) {
+	var grupo Grupo
+	var ceps string
+	var criadoEm, atualizadoEm int64
+	if err := linha.Scan(&grupo.Cliente, &grupo.Nome, &ceps, &criadoEm, &atualizadoEm); err != nil {
+		return Grupo{}, err
+	}
+	grupo.Ceps = strings.Split(ceps, ",")
+	grupo.CriadoEm = time.Unix(0, criadoEm).UTC()
+	grupo.AtualizadoEm = time.Unix(0, atualizadoEm).UTC()
+	return grupo, nil
+}
@@ -0,0 +1,80 @@
+package historico
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Intervalo é o tamanho dos baldes da série, sempre alinhados ao início da hora ou do dia em UTC.
+type Intervalo string
+
+const (
+	IntervaloHora Intervalo = "hora"
+	IntervaloDia  Intervalo = "dia"
+)
+
+func (i Intervalo) Duracao() time.Duration {
+	if i == IntervaloDia {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// Estatistica resume, em Celsius, as leituras consultadas em [Inicio, Fim). Percentis segue a ordem dos
+// percentis pedidos a Agrega.
+type Estatistica struct {
+	Inicio    time.Time
+	Fim       time.Time
+	Leituras  int
+	Minima    float64
+	Maxima    float64
+	Media     float64
+	Percentis []float64
+}
+
+// Agrega agrupa as consultas pelo horário da consulta e calcula mínima, máxima, média e os percentis
+// (de 0 a 100) de cada balde. Baldes sem leituras ficam fora da série, que vem em ordem cronológica.
+func Agrega(consultas []Consulta, intervalo Intervalo, percentis []float64) []Estatistica {
+	porBalde := map[time.Time][]float64{}
+	for _, consulta := range consultas {
+		inicio := consulta.ConsultadaEm.UTC().Truncate(intervalo.Duracao())
+		porBalde[inicio] = append(porBalde[inicio], consulta.Celsius)
+	}
+
+	serie := make([]Estatistica, 0, len(porBalde))
+	for inicio, leituras := range porBalde {
+		slices.Sort(leituras)
+
+		soma := 0.0
+		for _, leitura := range leituras {
+			soma += leitura
+		}
+		estatistica := Estatistica{
+			Inicio:    inicio,
+			Fim:       inicio.Add(intervalo.Duracao()),
+			Leituras:  len(leituras),
+			Minima:    leituras[0],
+			Maxima:    leituras[len(leituras)-1],
+			Media:     soma / float64(len(leituras)),
+			Percentis: make([]float64, 0, len(percentis)),
+		}
+		for _, p := range percentis {
+			estatistica.Percentis = append(estatistica.Percentis, percentil(leituras, p))
+		}
+		serie = append(serie, estatistica)
+	}
+	slices.SortFunc(serie, func(a, b Estatistica) int { return a.Inicio.Compare(b.Inicio) })
+
+	return serie
+}
+
+// percentil interpola linearmente entre as duas leituras mais próximas da posição p, como o
+// PERCENTILE_CONT do SQL. As leituras precisam estar ordenadas.
+func percentil(ordenadas []float64, p float64) float64 {
+	posicao := p / 100 * float64(len(ordenadas)-1)
+	abaixo := int(math.Floor(posicao))
+	acima := int(math.Ceil(posicao))
+
+	return ordenadas[abaixo] + (ordenadas[acima]-ordenadas[abaixo])*(posicao-float64(abaixo))
+}
@@ -0,0 +1,76 @@
+package historico
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// tempoGravacao limita cada gravação, para que um disco travado não segure a fila indefinidamente.
+const tempoGravacao = 5 * time.Second
+
+// Gravador recebe as consultas do handler em uma fila e as grava no repositório em segundo plano, de
+// modo que o histórico não aumenta a latência da resposta. Com a fila cheia, a consulta é descartada.
+type Gravador struct {
+	repositorio Repositorio
+
+	mu        sync.RWMutex
+	fila      chan Consulta
+	encerrado bool
+	gravados  chan struct{}
+}
+
+func NewGravador(repositorio Repositorio, capacidade int) *Gravador {
+	g := &Gravador{
+		repositorio: repositorio,
+		fila:        make(chan Consulta, capacidade),
+		gravados:    make(chan struct{}),
+	}
+	go g.grava()
+	return g
+}
+
+func (g *Gravador) Registra(consulta Consulta) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.encerrado {
+		return
+	}
+
+	select {
+	case g.fila <- consulta:
+	default:
+		slog.Warn("fila do histórico cheia, consulta descartada", "cep", consulta.Cep, "trace_id", consulta.TraceID)
+	}
+}
+
+// Encerra para de aceitar consultas e espera a gravação das que estão na fila, até o fim do contexto.
+func (g *Gravador) Encerra(ctx context.Context) error {
+	g.mu.Lock()
+	if !g.encerrado {
+		g.encerrado = true
+		close(g.fila)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-g.gravados:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *Gravador) grava() {
+	defer close(g.gravados)
+
+	for consulta := range g.fila {
+		ctx, cancel := context.WithTimeout(context.Background(), tempoGravacao)
+		if err := g.repositorio.Salva(ctx, consulta); err != nil {
+			slog.Error("falha ao gravar a consulta no histórico", "cep", consulta.Cep, "trace_id", consulta.TraceID, "erro", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,88 @@
+package historico
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+)
+
+// Consulta é uma resposta de temperatura atendida pelo Serviço B. A temperatura é guardada em Celsius,
+// sem arredondamento, para que possa ser apresentada em qualquer unidade.
+type Consulta struct {
+	ID           int64
+	Cep          string
+	Cidade       string
+	Uf           string
+	Celsius      float64
+	Provedor     string
+	TraceID      string
+	ObservadaEm  time.Time
+	ConsultadaEm time.Time
+}
+
+// Filtro seleciona as consultas de um CEP ou de uma cidade e de um período, com De e Ate inclusivos.
+// Cidade não diferencia maiúsculas, acentos nem espaços repetidos (domain.ChaveCidade) e Uf não diferencia
+// maiúsculas. Campos vazios não filtram. Pagina começa em 1.
+type Filtro struct {
+	Cep     string
+	Cidade  string
+	Uf      string
+	De      time.Time
+	Ate     time.Time
+	Pagina  int
+	Tamanho int
+}
+
+// Pagina traz as consultas da página pedida, das mais recentes para as mais antigas, e o total de
+// consultas que atendem ao filtro.
+type Pagina struct {
+	Consultas []Consulta
+	Total     int
+}
+
+type Repositorio interface {
+	Salva(ctx context.Context, consulta Consulta) error
+	Busca(ctx context.Context, filtro Filtro) (Pagina, error)
+	// Lista traz todas as consultas do filtro, das mais antigas para as mais recentes, sem paginação.
+	Lista(ctx context.Context, filtro Filtro) ([]Consulta, error)
+	Fecha() error
+}
+
+// Registrador recebe as consultas atendidas sem atrasar a resposta.
+type Registrador interface {
+	Registra(consulta Consulta)
+}
+
+// Abre o repositório da configuração: SQLite em historico.arquivo ou, sem arquivo, em memória.
+func Abre(cfg config.HistoricoConfig) (Repositorio, error) {
+	if cfg.Arquivo == "" {
+		return NewRepositorioMemoria(), nil
+	}
+	return NewRepositorioSQLite(cfg.Arquivo)
+}
+
+func (f Filtro) atende(consulta Consulta) bool {
+	if f.Cep != "" && consulta.Cep != f.Cep {
+		return false
+	}
+	if f.Cidade != "" && domain.ChaveCidade(consulta.Cidade) != domain.ChaveCidade(f.Cidade) {
+		return false
+	}
+	if f.Uf != "" && !strings.EqualFold(consulta.Uf, f.Uf) {
+		return false
+	}
+	if !f.De.IsZero() && consulta.ConsultadaEm.Before(f.De) {
+		return false
+	}
+	if !f.Ate.IsZero() && consulta.ConsultadaEm.After(f.Ate) {
+		return false
+	}
+	return true
+}
+
+func (f Filtro) deslocamento() int {
+	return (max(f.Pagina, 1) - 1) * f.Tamanho
+}
@@ -0,0 +1,243 @@
+package historico
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositorioTestSuite struct {
+	suite.Suite
+	novo        func() Repositorio
+	repositorio Repositorio
+	inicio      time.Time
+}
+
+func TestRepositorioMemoriaSuite(t *testing.T) {
+	suite.Run(t, &RepositorioTestSuite{novo: func() Repositorio { return NewRepositorioMemoria() }})
+}
+
+func TestRepositorioSQLiteSuite(t *testing.T) {
+	s := &RepositorioTestSuite{}
+	s.novo = func() Repositorio {
+		repositorio, err := NewRepositorioSQLite(filepath.Join(s.T().TempDir(), "dados", "historico.db"))
+		s.Require().NoError(err)
+		return repositorio
+	}
+	suite.Run(t, s)
+}
+
+// SetupTest grava uma consulta por hora a partir de 10/06/2025, alternando dois CEPs.
+func (s *RepositorioTestSuite) SetupTest() {
+	s.repositorio = s.novo()
+	s.inicio = time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	for i, cep := range []string{"01001000", "20040002", "01001000", "20040002", "01001000"} {
+		s.Require().NoError(s.repositorio.Salva(context.Background(), Consulta{
+			Cep:          cep,
+			Cidade:       "Cidade " + cep,
+			Uf:           "SP",
+			Celsius:      20.5 + float64(i),
+			Provedor:     "weatherapi",
+			TraceID:      "trace",
+			ObservadaEm:  s.inicio.Add(time.Duration(i) * time.Hour).Add(-time.Minute),
+			ConsultadaEm: s.inicio.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+}
+
+func (s *RepositorioTestSuite) TearDownTest() {
+	s.NoError(s.repositorio.Fecha())
+}
+
+func (s *RepositorioTestSuite) TestBuscaPorCepDoMaisRecente() {
+	// Act
+	pagina, err := s.repositorio.Busca(context.Background(), Filtro{Cep: "01001000", Pagina: 1, Tamanho: 10})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(3, pagina.Total)
+	s.Require().Len(pagina.Consultas, 3)
+	s.Equal(s.inicio.Add(4*time.Hour), pagina.Consultas[0].ConsultadaEm)
+	s.Equal(s.inicio.Add(4*time.Hour-time.Minute), pagina.Consultas[0].ObservadaEm)
+	s.Equal(24.5, pagina.Consultas[0].Celsius)
+	s.Equal("Cidade 01001000", pagina.Consultas[0].Cidade)
+	s.Equal(s.inicio, pagina.Consultas[2].ConsultadaEm)
+}
+
+func (s *RepositorioTestSuite) TestBuscaPorPeriodoInclusivo() {
+	// Act
+	pagina, err := s.repositorio.Busca(context.Background(), Filtro{
+		De:      s.inicio.Add(time.Hour),
+		Ate:     s.inicio.Add(3 * time.Hour),
+		Pagina:  1,
+		Tamanho: 10,
+	})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Equal(3, pagina.Total)
+	s.Len(pagina.Consultas, 3)
+}
+
+func (s *RepositorioTestSuite) TestPaginacao() {
+	// Act
+	segunda, err := s.repositorio.Busca(context.Background(), Filtro{Pagina: 2, Tamanho: 2})
+	s.Require().NoError(err)
+	alemDaUltima, err := s.repositorio.Busca(context.Background(), Filtro{Pagina: 4, Tamanho: 2})
+	s.Require().NoError(err)
+
+	// Assert
+	s.Equal(5, segunda.Total)
+	s.Require().Len(segunda.Consultas, 2)
+	s.Equal(s.inicio.Add(2*time.Hour), segunda.Consultas[0].ConsultadaEm)
+	s.Equal(5, alemDaUltima.Total)
+	s.NotNil(alemDaUltima.Consultas)
+	s.Empty(alemDaUltima.Consultas)
+}
+
+func (s *RepositorioTestSuite) TestGravadorGravaEmSegundoPlano() {
+	// Arrange
+	gravador := NewGravador(s.repositorio, 10)
+
+	// Act
+	gravador.Registra(Consulta{Cep: "30130010", Cidade: "Belo Horizonte", Uf: "MG", ConsultadaEm: s.inicio.Add(time.Minute)})
+	s.Require().NoError(gravador.Encerra(context.Background()))
+	gravador.Registra(Consulta{Cep: "30130010", ConsultadaEm: s.inicio})
+
+	// Assert
+	pagina, err := s.repositorio.Busca(context.Background(), Filtro{Cep: "30130010", Pagina: 1, Tamanho: 10})
+	s.Require().NoError(err)
+	s.Equal(1, pagina.Total)
+	s.Equal("Belo Horizonte", pagina.Consultas[0].Cidade)
+}
+
+// repositorioRetido segura as gravações até que liberado seja fechado.
+type repositorioRetido struct {
+	Repositorio
+	liberado chan struct{}
+}
+
+func (r *repositorioRetido) Salva(ctx context.Context, consulta Consulta) error {
+	<-r.liberado
+	return r.Repositorio.Salva(ctx, consulta)
+}
+
+func (s *RepositorioTestSuite) TestEncerraGravaAsConsultasQueEstaoNaFila() {
+	// Arrange
+	retido := &repositorioRetido{Repositorio: s.repositorio, liberado: make(chan struct{})}
+	gravador := NewGravador(retido, 10)
+	for i := range 3 {
+		gravador.Registra(Consulta{Cep: "30130010", Cidade: "Belo Horizonte", Uf: "MG", ConsultadaEm: s.inicio.Add(time.Duration(i) * time.Minute)})
+	}
+
+	// Act
+	close(retido.liberado)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := gravador.Encerra(ctx)
+
+	// Assert
+	s.Require().NoError(err)
+	pagina, err := s.repositorio.Busca(context.Background(), Filtro{Cep: "30130010", Pagina: 1, Tamanho: 10})
+	s.Require().NoError(err)
+	s.Equal(3, pagina.Total)
+}
+
+func (s *RepositorioTestSuite) TestListaPorCidadeEmOrdemCronologica() {
+	// Act
+	consultas, err := s.repositorio.Lista(context.Background(), Filtro{Cidade: "cidade 20040002", Uf: "sp"})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Require().Len(consultas, 2)
+	s.Equal(s.inicio.Add(time.Hour), consultas[0].ConsultadaEm)
+	s.Equal(s.inicio.Add(3*time.Hour), consultas[1].ConsultadaEm)
+}
+
+func (s *RepositorioTestSuite) TestFiltroPorCidadeIgnoraAcentosEMaiusculas() {
+	// Arrange
+	s.Require().NoError(s.repositorio.Salva(context.Background(), Consulta{Cep: "01310100", Cidade: "São Paulo", Uf: "SP", ConsultadaEm: s.inicio}))
+
+	for _, cidade := range []string{"SÃO PAULO", "sao  paulo", "São Paulo"} {
+		// Act
+		consultas, err := s.repositorio.Lista(context.Background(), Filtro{Cidade: cidade, Uf: "sp"})
+
+		// Assert
+		s.Require().NoError(err)
+		s.Require().Len(consultas, 1, cidade)
+		s.Equal("São Paulo", consultas[0].Cidade)
+	}
+}
+
+func (s *RepositorioTestSuite) TestAgregaPorHoraEPorDia() {
+	// Arrange
+	consultas, err := s.repositorio.Lista(context.Background(), Filtro{})
+	s.Require().NoError(err)
+
+	// Act
+	porHora := Agrega(consultas, IntervaloHora, []float64{50})
+	porDia := Agrega(consultas, IntervaloDia, []float64{0, 50, 90, 100})
+
+	// Assert
+	s.Require().Len(porHora, 5)
+	s.Equal(s.inicio.Add(2*time.Hour), porHora[2].Inicio)
+	s.Equal(s.inicio.Add(3*time.Hour), porHora[2].Fim)
+	s.Equal([]float64{22.5}, porHora[2].Percentis)
+
+	s.Require().Len(porDia, 1)
+	s.Equal(s.inicio, porDia[0].Inicio)
+	s.Equal(s.inicio.AddDate(0, 0, 1), porDia[0].Fim)
+	s.Equal(5, porDia[0].Leituras)
+	s.Equal(20.5, porDia[0].Minima)
+	s.Equal(24.5, porDia[0].Maxima)
+	s.InDelta(22.5, porDia[0].Media, 1e-9)
+	s.InDeltaSlice([]float64{20.5, 22.5, 24.1, 24.5}, porDia[0].Percentis, 1e-9)
+}
+
+func TestRepositorioSQLiteMigraArquivoAnterior(t *testing.T) {
+	suite.Run(t, new(MigracaoSQLiteTestSuite))
+}
+
+type MigracaoSQLiteTestSuite struct {
+	suite.Suite
+}
+
+func (s *MigracaoSQLiteTestSuite) TestPreencheAChaveDasCidadesGravadas() {
+	// Arrange
+	arquivo := filepath.Join(s.T().TempDir(), "historico.db")
+	db, err := sql.Open("sqlite", "file:"+arquivo)
+	s.Require().NoError(err)
+	_, err = db.Exec(`
+CREATE TABLE consultas (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	cep           TEXT    NOT NULL,
+	cidade        TEXT    NOT NULL,
+	uf            TEXT    NOT NULL,
+	celsius       REAL    NOT NULL,
+	provedor      TEXT    NOT NULL,
+	trace_id      TEXT    NOT NULL,
+	observada_em  INTEGER NOT NULL,
+	consultada_em INTEGER NOT NULL
+);
+CREATE INDEX consultas_cidade_consultada_em ON consultas (cidade COLLATE NOCASE, consultada_em);
+INSERT INTO consultas (cep, cidade, uf, celsius, provedor, trace_id, observada_em, consultada_em)
+VALUES ('01310100', 'São Paulo', 'SP', 21.5, 'weatherapi', 'trace', 0, 1);`)
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+
+	// Act
+	repositorio, err := NewRepositorioSQLite(arquivo)
+	s.Require().NoError(err)
+	defer repositorio.Fecha()
+	consultas, err := repositorio.Lista(context.Background(), Filtro{Cidade: "SÃO PAULO"})
+
+	// Assert
+	s.Require().NoError(err)
+	s.Require().Len(consultas, 1)
+	s.Equal(21.5, consultas[0].Celsius)
+}
@@ -0,0 +1,64 @@
+package historico
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+// RepositorioMemoria guarda as consultas enquanto o processo estiver de pé. É usado nos testes e quando
+// historico.arquivo não está definido.
+type RepositorioMemoria struct {
+	mu        sync.RWMutex
+	consultas []Consulta
+}
+
+func NewRepositorioMemoria() *RepositorioMemoria {
+	return &RepositorioMemoria{}
+}
+
+func (r *RepositorioMemoria) Salva(_ context.Context, consulta Consulta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	consulta.ID = int64(len(r.consultas) + 1)
+	r.consultas = append(r.consultas, consulta)
+	return nil
+}
+
+func (r *RepositorioMemoria) Busca(ctx context.Context, filtro Filtro) (Pagina, error) {
+	encontradas, _ := r.Lista(ctx, filtro)
+	// Mesma ordem do SQLite: mais recentes primeiro e, no mesmo instante, a última gravada.
+	slices.Reverse(encontradas)
+
+	inicio := min(filtro.deslocamento(), len(encontradas))
+	fim := min(inicio+filtro.Tamanho, len(encontradas))
+	pagina := Pagina{Consultas: encontradas[inicio:fim], Total: len(encontradas)}
+
+	return pagina, nil
+}
+
+func (r *RepositorioMemoria) Lista(_ context.Context, filtro Filtro) ([]Consulta, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encontradas := []Consulta{}
+	for _, consulta := range r.consultas {
+		if filtro.atende(consulta) {
+			encontradas = append(encontradas, consulta)
+		}
+	}
+	slices.SortFunc(encontradas, func(a, b Consulta) int {
+		if c := a.ConsultadaEm.Compare(b.ConsultadaEm); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return encontradas, nil
+}
+
+func (r *RepositorioMemoria) Fecha() error {
+	return nil
+}
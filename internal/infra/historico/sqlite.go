@@ -0,0 +1,225 @@
+package historico
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	_ "modernc.org/sqlite"
+)
+
+// esquema cria a tabela na primeira abertura. Os instantes são gravados em nanossegundos desde a época
+// (UTC), o que mantém a ordenação e os filtros por período no índice.
+const esquema = `
+CREATE TABLE IF NOT EXISTS consultas (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	cep           TEXT    NOT NULL,
+	cidade        TEXT    NOT NULL,
+	chave_cidade  TEXT    NOT NULL DEFAULT '',
+	uf            TEXT    NOT NULL,
+	celsius       REAL    NOT NULL,
+	provedor      TEXT    NOT NULL,
+	trace_id      TEXT    NOT NULL,
+	observada_em  INTEGER NOT NULL,
+	consultada_em INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS consultas_consultada_em ON consultas (consultada_em);
+CREATE INDEX IF NOT EXISTS consultas_cep_consultada_em ON consultas (cep, consultada_em);
+`
+
+// migracaoChaveCidade leva um arquivo gravado antes da coluna chave_cidade ao esquema atual. O filtro por
+// cidade usa a chave_cidade, a domain.ChaveCidade do nome, porque o NOCASE do SQLite só ignora maiúsculas
+// em ASCII e não ignora acentos.
+const migracaoChaveCidade = `
+ALTER TABLE consultas ADD COLUMN chave_cidade TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS consultas_cidade_consultada_em;
+`
+
+const indiceChaveCidade = `CREATE INDEX IF NOT EXISTS consultas_chave_cidade_consultada_em ON consultas (chave_cidade, consultada_em)`
+
+// RepositorioSQLite grava as consultas em um arquivo SQLite embarcado, sem depender de CGO.
+type RepositorioSQLite struct {
+	db *sql.DB
+}
+
+func NewRepositorioSQLite(arquivo string) (*RepositorioSQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(arquivo), 0o755); err != nil {
+		return nil, fmt.Errorf("falha ao criar o diretório do histórico: %w", err)
+	}
+
+	// WAL deixa as leituras do /historico seguirem enquanto o gravador escreve.
+	db, err := sql.Open("sqlite", "file:"+arquivo+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir o histórico %s: %w", arquivo, err)
+	}
+	if err := migra(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao preparar o histórico %s: %w", arquivo, err)
+	}
+
+	return &RepositorioSQLite{db: db}, nil
+}
+
+func migra(db *sql.DB) error {
+	if _, err := db.Exec(esquema); err != nil {
+		return err
+	}
+
+	var colunaChaveCidade int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('consultas') WHERE name = 'chave_cidade'`).Scan(&colunaChaveCidade); err != nil {
+		return err
+	}
+	if colunaChaveCidade == 0 {
+		if _, err := db.Exec(migracaoChaveCidade); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec(indiceChaveCidade); err != nil {
+		return err
+	}
+	return preencheChaveCidade(db)
+}
+
+// preencheChaveCidade calcula a chave das cidades gravadas antes da coluna chave_cidade existir.
+func preencheChaveCidade(db *sql.DB) error {
+	linhas, err := db.Query(`SELECT DISTINCT cidade FROM consultas WHERE chave_cidade = '' AND cidade <> ''`)
+	if err != nil {
+		return err
+	}
+	cidades := []string{}
+	for linhas.Next() {
+		var cidade string
+		if err := linhas.Scan(&cidade); err != nil {
+			linhas.Close()
+			return err
+		}
+		cidades = append(cidades, cidade)
+	}
+	linhas.Close()
+	if err := linhas.Err(); err != nil {
+		return err
+	}
+
+	for _, cidade := range cidades {
+		_, err := db.Exec(`UPDATE consultas SET chave_cidade = ? WHERE cidade = ? AND chave_cidade = ''`, domain.ChaveCidade(cidade), cidade)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RepositorioSQLite) Salva(ctx context.Context, consulta Consulta) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO consultas (cep, cidade, chave_cidade, uf, celsius, provedor, trace_id, observada_em, consultada_em)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		consulta.Cep, consulta.Cidade, domain.ChaveCidade(consulta.Cidade), consulta.Uf, consulta.Celsius, consulta.Provedor, consulta.TraceID,
+		instante(consulta.ObservadaEm), instante(consulta.ConsultadaEm),
+	)
+	return err
+}
+
+func (r *RepositorioSQLite) Busca(ctx context.Context, filtro Filtro) (Pagina, error) {
+	where, argumentos := condicoes(filtro)
+
+	pagina := Pagina{}
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM consultas"+where, argumentos...).Scan(&pagina.Total); err != nil {
+		return Pagina{}, err
+	}
+
+	linhas, err := r.db.QueryContext(ctx,
+		colunas+where+` ORDER BY consultada_em DESC, id DESC LIMIT ? OFFSET ?`,
+		append(argumentos, filtro.Tamanho, filtro.deslocamento())...,
+	)
+	if err != nil {
+		return Pagina{}, err
+	}
+
+	pagina.Consultas, err = leConsultas(linhas)
+	if err != nil {
+		return Pagina{}, err
+	}
+	return pagina, nil
+}
+
+func (r *RepositorioSQLite) Lista(ctx context.Context, filtro Filtro) ([]Consulta, error) {
+	where, argumentos := condicoes(filtro)
+
+	linhas, err := r.db.QueryContext(ctx, colunas+where+` ORDER BY consultada_em, id`, argumentos...)
+	if err != nil {
+		return nil, err
+	}
+	return leConsultas(linhas)
+}
+
+const colunas = `SELECT id, cep, cidade, uf, celsius, provedor, trace_id, observada_em, consultada_em FROM consultas`
+
+func condicoes(filtro Filtro) (string, []any) {
+	condicoes := []string{"1 = 1"}
+	argumentos := []any{}
+	if filtro.Cep != "" {
+		condicoes = append(condicoes, "cep = ?")
+		argumentos = append(argumentos, filtro.Cep)
+	}
+	if filtro.Cidade != "" {
+		condicoes = append(condicoes, "chave_cidade = ?")
+		argumentos = append(argumentos, domain.ChaveCidade(filtro.Cidade))
+	}
+	if filtro.Uf != "" {
+		condicoes = append(condicoes, "uf = ? COLLATE NOCASE")
+		argumentos = append(argumentos, filtro.Uf)
+	}
+	if !filtro.De.IsZero() {
+		condicoes = append(condicoes, "consultada_em >= ?")
+		argumentos = append(argumentos, instante(filtro.De))
+	}
+	if !filtro.Ate.IsZero() {
+		condicoes = append(condicoes, "consultada_em <= ?")
+		argumentos = append(argumentos, instante(filtro.Ate))
+	}
+	return " WHERE " + strings.Join(condicoes, " AND "), argumentos
+}
+
+func leConsultas(linhas *sql.Rows) ([]Consulta, error) {
+	defer linhas.Close()
+
+	consultas := []Consulta{}
+	for linhas.Next() {
+		var consulta Consulta
+		var observadaEm, consultadaEm int64
+		err := linhas.Scan(&consulta.ID, &consulta.Cep, &consulta.Cidade, &consulta.Uf, &consulta.Celsius,
+			&consulta.Provedor, &consulta.TraceID, &observadaEm, &consultadaEm)
+		if err != nil {
+			return nil, err
+		}
+		consulta.ObservadaEm = deInstante(observadaEm)
+		consulta.ConsultadaEm = deInstante(consultadaEm)
+		consultas = append(consultas, consulta)
+	}
+
+	return consultas, linhas.Err()
+}
+
+func (r *RepositorioSQLite) Fecha() error {
+	return r.db.Close()
+}
+
+// instante grava o horário zero como 0, e não como o valor negativo de UnixNano para o ano 1.
+func instante(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func deInstante(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
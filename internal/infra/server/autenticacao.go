@@ -0,0 +1,234 @@
+package server
+
+import (
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/cliente"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderApiKey é o header aceito para a chave de API, além de Authorization: Bearer.
+const HeaderApiKey = "X-API-Key"
+
+// Autenticacao exige uma chave de API válida quando autenticacao.habilitada está ligada e aplica a cada
+// cliente um token bucket e uma cota diária (UTC). Os caminhos em publicos não são verificados.
+// A configuração é lida a cada requisição, então chaves e limites podem ser recarregados.
+func Autenticacao(publicos ...string) Middleware {
+	return novoControleAcesso(time.Now).middleware(publicos)
+}
+
+type controleAcesso struct {
+	relogio func() time.Time
+
+	mu       sync.Mutex
+	chaves   string
+	clientes map[string]string
+	limites  map[string]*limiteCliente
+}
+
+func novoControleAcesso(relogio func() time.Time) *controleAcesso {
+	return &controleAcesso{relogio: relogio, limites: map[string]*limiteCliente{}}
+}
+
+func (c *controleAcesso) middleware(publicos []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Só a chave de API identifica o cliente; o cliente.id recebido no baggage não segue adiante.
+			r = r.WithContext(cliente.SemCliente(r.Context()))
+
+			cfg := config.Get().GetAutenticacao()
+			if !cfg.Habilitada || slices.Contains(publicos, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			span := trace.SpanFromContext(r.Context())
+
+			identificado, ok := c.identifica(cfg, chaveApi(r))
+			if !ok {
+				otel.AddSpanEvent(span, "Chave de API ausente ou inválida", nil)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="temperaturas"`)
+				i18n.Error(w, r, erros.ErrInvalidApiKey, http.StatusUnauthorized)
+				return
+			}
+
+			span.SetAttributes(semconv.EnduserID(identificado))
+			if info, ok := r.Context().Value(chaveInfoRequisicao{}).(*infoRequisicao); ok {
+				info.cliente = identificado
+			}
+
+			consumo := c.consome(identificado, cfg.LimiteDo(identificado))
+			consumo.escreveHeaders(w.Header())
+			if consumo.erro != nil {
+				otel.AddSpanEvent(span, "Requisição limitada", map[string]interface{}{
+					"motivo":      consumo.erro.Error(),
+					"retry_after": consumo.retryAfter.String(),
+				})
+				w.Header().Set("Retry-After", strconv.Itoa(segundos(consumo.retryAfter)))
+				i18n.Error(w, r, consumo.erro, http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(cliente.NovoContexto(r.Context(), identificado)))
+		})
+	}
+}
+
+// ClienteDoServicoA aceita, no Serviço B, o cliente que o Serviço A autenticou e enviou no baggage, mas só
+// quando a requisição chegou com um certificado de cliente verificado (mTLS entre os serviços). Fora desse
+// salto confiável, o cliente do baggage é descartado e não vira enduser.id nem cliente_id.
+func ClienteDoServicoA() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := cliente.DoContexto(r.Context())
+			if id == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				next.ServeHTTP(w, r.WithContext(cliente.SemCliente(r.Context())))
+				return
+			}
+
+			trace.SpanFromContext(r.Context()).SetAttributes(semconv.EnduserID(id))
+			if info, ok := r.Context().Value(chaveInfoRequisicao{}).(*infoRequisicao); ok {
+				info.cliente = id
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// chaveApi lê a chave do header X-API-Key ou de Authorization: Bearer.
+func chaveApi(r *http.Request) string {
+	if chave := r.Header.Get(HeaderApiKey); chave != "" {
+		return chave
+	}
+
+	esquema, chave, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(esquema, "Bearer") {
+		return strings.TrimSpace(chave)
+	}
+	return ""
+}
+
+// identifica compara a chave recebida com todas as configuradas em tempo constante.
+func (c *controleAcesso) identifica(cfg config.AutenticacaoConfig, recebida string) (string, bool) {
+	if recebida == "" {
+		return "", false
+	}
+
+	c.mu.Lock()
+	if c.clientes == nil || c.chaves != cfg.Chaves {
+		// A configuração já foi validada na carga e na recarga; uma entrada inválida não autentica ninguém.
+		c.clientes, _ = cfg.ClientesPorChave()
+		c.chaves = cfg.Chaves
+	}
+	clientes := c.clientes
+	c.mu.Unlock()
+
+	encontrado := ""
+	for chave, cliente := range clientes {
+		if subtle.ConstantTimeCompare([]byte(chave), []byte(recebida)) == 1 {
+			encontrado = cliente
+		}
+	}
+
+	return encontrado, encontrado != ""
+}
+
+// limiteCliente guarda o token bucket e o uso do dia de um cliente.
+type limiteCliente struct {
+	limite     config.LimiteConfig
+	tokens     float64
+	atualizado time.Time
+	dia        string
+	usadas     int
+}
+
+type consumo struct {
+	erro       error
+	limite     int
+	restantes  int
+	renovacao  time.Duration
+	retryAfter time.Duration
+}
+
+func (c *controleAcesso) consome(cliente string, limite config.LimiteConfig) consumo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agora := c.relogio().UTC()
+	estado, ok := c.limites[cliente]
+	if !ok {
+		estado = &limiteCliente{limite: limite, tokens: float64(limite.Rajada), atualizado: agora}
+		c.limites[cliente] = estado
+	}
+	if estado.limite != limite {
+		estado.limite = limite
+		estado.tokens = math.Min(estado.tokens, float64(limite.Rajada))
+	}
+
+	if dia := agora.Format(time.DateOnly); estado.dia != dia {
+		estado.dia = dia
+		estado.usadas = 0
+	}
+
+	estado.tokens = math.Min(float64(limite.Rajada), estado.tokens+agora.Sub(estado.atualizado).Seconds()*limite.RequisicoesPorSegundo)
+	estado.atualizado = agora
+
+	resultado := estado.situacao(agora)
+
+	if limite.CotaDiaria > 0 && estado.usadas >= limite.CotaDiaria {
+		resultado.erro = erros.ErrDailyQuotaExceeded
+		resultado.retryAfter = resultado.renovacao
+		return resultado
+	}
+	if estado.tokens < 1 {
+		resultado.erro = erros.ErrRateLimitExceeded
+		resultado.retryAfter = time.Duration((1 - estado.tokens) / limite.RequisicoesPorSegundo * float64(time.Second))
+		return resultado
+	}
+
+	estado.tokens--
+	estado.usadas++
+	return estado.situacao(agora)
+}
+
+// situacao descreve a cota diária quando ela existe; sem cota, descreve a rajada do token bucket.
+func (l *limiteCliente) situacao(agora time.Time) consumo {
+	if l.limite.CotaDiaria > 0 {
+		amanha := time.Date(agora.Year(), agora.Month(), agora.Day()+1, 0, 0, 0, 0, time.UTC)
+		return consumo{limite: l.limite.CotaDiaria, restantes: l.limite.CotaDiaria - l.usadas, renovacao: amanha.Sub(agora)}
+	}
+
+	faltantes := float64(l.limite.Rajada) - l.tokens
+	return consumo{
+		limite:    l.limite.Rajada,
+		restantes: int(l.tokens),
+		renovacao: time.Duration(faltantes / l.limite.RequisicoesPorSegundo * float64(time.Second)),
+	}
+}
+
+func (c consumo) escreveHeaders(header http.Header) {
+	header.Set("X-RateLimit-Limit", strconv.Itoa(c.limite))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(max(c.restantes, 0)))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(segundos(c.renovacao)))
+}
+
+// segundos arredonda para cima, para que o cliente não tente de novo antes da hora.
+func segundos(duracao time.Duration) int {
+	return int(math.Ceil(duracao.Seconds()))
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/cliente"
+	"github.com/stretchr/testify/suite"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+type AutenticacaoTestSuite struct {
+	suite.Suite
+	agora           time.Time
+	clienteRecebido string
+	handler         http.Handler
+}
+
+func TestAutenticacaoSuite(t *testing.T) {
+	suite.Run(t, new(AutenticacaoTestSuite))
+}
+
+func (s *AutenticacaoTestSuite) SetupTest() {
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.T().Setenv("AUTENTICACAO_HABILITADA", "true")
+	s.T().Setenv("AUTENTICACAO_CHAVES", "cliente-a:chave-a,cliente-b:chave-b")
+	s.T().Setenv("AUTENTICACAO_LIMITE_REQUISICOES_POR_SEGUNDO", "1")
+	s.T().Setenv("AUTENTICACAO_LIMITE_RAJADA", "2")
+	s.T().Setenv("AUTENTICACAO_LIMITE_COTA_DIARIA", "0")
+	s.Require().NoError(config.LoadConfig(s.T().TempDir()))
+
+	s.agora = time.Date(2025, 6, 10, 23, 59, 0, 0, time.UTC)
+	s.clienteRecebido = ""
+	controle := novoControleAcesso(func() time.Time { return s.agora })
+	s.handler = controle.middleware([]string{"/docs"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.clienteRecebido = cliente.DoContexto(r.Context())
+	}))
+}
+
+func (s *AutenticacaoTestSuite) requisita(caminho string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, caminho, nil)
+	for nome, valor := range headers {
+		req.Header.Set(nome, valor)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *AutenticacaoTestSuite) TestRecusaRequisicaoSemChave() {
+	// Act
+	rec := s.requisita("/temperaturas/01001000", nil)
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, rec.Code)
+	s.NotEmpty(rec.Header().Get("WWW-Authenticate"))
+	s.Empty(s.clienteRecebido)
+}
+
+func (s *AutenticacaoTestSuite) TestRecusaChaveInvalida() {
+	// Act
+	rec := s.requisita("/temperaturas/01001000", map[string]string{HeaderApiKey: "chave-c"})
+
+	// Assert
+	s.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (s *AutenticacaoTestSuite) TestAceitaBearerEPropagaCliente() {
+	// Act
+	rec := s.requisita("/temperaturas/01001000", map[string]string{"Authorization": "Bearer chave-b"})
+
+	// Assert
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("cliente-b", s.clienteRecebido)
+	s.Equal("2", rec.Header().Get("X-RateLimit-Limit"))
+	s.Equal("1", rec.Header().Get("X-RateLimit-Remaining"))
+	s.Equal("1", rec.Header().Get("X-RateLimit-Reset"))
+}
+
+func (s *AutenticacaoTestSuite) TestLiberaCaminhoPublico() {
+	// Act
+	rec := s.requisita("/docs", nil)
+
+	// Assert
+	s.Equal(http.StatusOK, rec.Code)
+}
+
+func (s *AutenticacaoTestSuite) TestDescartaClienteRecebidoNoBaggage() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req = req.WithContext(cliente.NovoContexto(req.Context(), "cliente-a"))
+
+	// Act
+	s.handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	s.Empty(s.clienteRecebido)
+}
+
+func (s *AutenticacaoTestSuite) TestClienteDoServicoASoComCertificadoVerificado() {
+	cenarios := []struct {
+		nome            string
+		tls             *tls.ConnectionState
+		expectedCliente string
+	}{
+		{"sem tls", nil, ""},
+		{"tls sem certificado de cliente", &tls.ConnectionState{}, ""},
+		{"mtls", &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}, "cliente-a"},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			spans := tracetest.NewSpanRecorder()
+			ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("teste").Start(context.Background(), "servidor")
+			req := httptest.NewRequest(http.MethodGet, "/cidades/01001000/temperaturas", nil)
+			req = req.WithContext(cliente.NovoContexto(ctx, "cliente-a"))
+			req.TLS = cenario.tls
+			recebido := "nenhum"
+
+			// Act
+			ClienteDoServicoA()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				recebido = cliente.DoContexto(r.Context())
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			span.End()
+
+			// Assert
+			s.Equal(cenario.expectedCliente, recebido)
+			enduser := ""
+			for _, atributo := range spans.Ended()[0].Attributes() {
+				if atributo.Key == semconv.EnduserIDKey {
+					enduser = atributo.Value.AsString()
+				}
+			}
+			s.Equal(cenario.expectedCliente, enduser)
+		})
+	}
+}
+
+func (s *AutenticacaoTestSuite) TestLimitaRajadaPorCliente() {
+	// Arrange
+	headers := map[string]string{HeaderApiKey: "chave-a"}
+	s.requisita("/temperaturas/01001000", headers)
+	s.requisita("/temperaturas/01001000", headers)
+
+	// Act
+	limitada := s.requisita("/temperaturas/01001000", headers)
+	outroCliente := s.requisita("/temperaturas/01001000", map[string]string{HeaderApiKey: "chave-b"})
+	s.agora = s.agora.Add(time.Second)
+	depoisDeUmSegundo := s.requisita("/temperaturas/01001000", headers)
+
+	// Assert
+	s.Equal(http.StatusTooManyRequests, limitada.Code)
+	s.Equal("1", limitada.Header().Get("Retry-After"))
+	s.Equal("0", limitada.Header().Get("X-RateLimit-Remaining"))
+	s.Equal(http.StatusOK, outroCliente.Code)
+	s.Equal(http.StatusOK, depoisDeUmSegundo.Code)
+}
+
+func (s *AutenticacaoTestSuite) TestLimitaCotaDiaria() {
+	// Arrange
+	s.T().Setenv("AUTENTICACAO_LIMITE_COTA_DIARIA", "1")
+	s.Require().NoError(config.LoadConfig(s.T().TempDir()))
+	headers := map[string]string{HeaderApiKey: "chave-a"}
+	primeira := s.requisita("/temperaturas/01001000", headers)
+
+	// Act
+	esgotada := s.requisita("/temperaturas/01001000", headers)
+	s.agora = s.agora.Add(time.Minute)
+	diaSeguinte := s.requisita("/temperaturas/01001000", headers)
+
+	// Assert
+	s.Equal(http.StatusOK, primeira.Code)
+	s.Equal("0", primeira.Header().Get("X-RateLimit-Remaining"))
+	s.Equal(http.StatusTooManyRequests, esgotada.Code)
+	s.Equal("60", esgotada.Header().Get("Retry-After"))
+	s.Equal("1", esgotada.Header().Get("X-RateLimit-Limit"))
+	s.Equal(http.StatusOK, diaSeguinte.Code)
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/requestid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware envolve um handler com um comportamento comum a todas as rotas.
+type Middleware func(http.Handler) http.Handler
+
+// Encadeia aplica os middlewares na ordem em que aparecem: o primeiro é o mais externo.
+func Encadeia(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Telemetria cria o span de servidor de cada requisição. O nome provisório "HTTP <método>" é trocado
+// pela rota do ServeMux (por exemplo "GET /temperaturas/{cep}") quando ela é conhecida. O enduser.id só é
+// definido depois que o cliente é identificado, pela Autenticacao ou pelo ClienteDoServicoA.
+func Telemetria(serviceName string, opcoes ...otelhttp.Option) Middleware {
+	opcoes = append([]otelhttp.Option{
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method
+		}),
+	}, opcoes...)
+
+	return func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(next, serviceName, opcoes...)
+	}
+}
+
+// RequestID reaproveita o X-Request-ID recebido, quando válido, ou gera um novo. O identificador volta
+// na resposta, fica no contexto para ser propagado ao Serviço B e é registrado no span.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valido(id) {
+				id = requestid.Gera()
+			}
+
+			w.Header().Set(requestid.Header, id)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", id))
+
+			next.ServeHTTP(w, r.WithContext(requestid.NovoContexto(r.Context(), id)))
+		})
+	}
+}
+
+// Idioma escolhe o idioma das mensagens pelo Accept-Language, com o padrão da configuração quando nenhum
+// idioma do catálogo é aceito. O idioma fica no contexto e volta em Content-Language.
+func Idioma(padrao func() i18n.Idioma) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idioma := i18n.Negocia(r.Header.Get("Accept-Language"), padrao())
+
+			w.Header().Set("Content-Language", string(idioma))
+			w.Header().Add("Vary", "Accept-Language")
+
+			next.ServeHTTP(w, r.WithContext(i18n.NovoContexto(r.Context(), idioma)))
+		})
+	}
+}
+
+// AccessLog registra uma linha estruturada por requisição, com rota, status, tamanho e latência.
+func AccessLog() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inicio := time.Now()
+			info := &infoRequisicao{}
+			registro := registraResposta(w)
+
+			next.ServeHTTP(registro, r.WithContext(context.WithValue(r.Context(), chaveInfoRequisicao{}, info)))
+
+			slog.InfoContext(r.Context(), "requisição http",
+				"metodo", r.Method,
+				"caminho", r.URL.Path,
+				"rota", info.rota,
+				"status", registro.status,
+				"bytes", registro.bytes,
+				"latencia_ms", float64(time.Since(inicio).Microseconds())/1000,
+				"request_id", requestid.DoContexto(r.Context()),
+				"cliente_id", info.cliente,
+				"trace_id", trace.SpanFromContext(r.Context()).SpanContext().TraceID().String(),
+			)
+		})
+	}
+}
+
+// Recuperacao transforma um panic do handler em 500, registrando o valor e a pilha no span.
+func Recuperacao() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			registro := registraResposta(w)
+
+			defer func() {
+				recuperado := recover()
+				if recuperado == nil {
+					return
+				}
+				if recuperado == http.ErrAbortHandler {
+					panic(recuperado)
+				}
+
+				err := fmt.Errorf("panic: %v", recuperado)
+				pilha := string(debug.Stack())
+				span := trace.SpanFromContext(r.Context())
+				otel.AddSpanEvent(span, "Panic recuperado", map[string]interface{}{"pilha": pilha})
+				otel.RecordSpanError(span, err)
+				slog.ErrorContext(r.Context(), "panic no handler", "erro", err, "request_id", requestid.DoContexto(r.Context()), "pilha", pilha)
+
+				if !registro.escrito {
+					i18n.Error(registro, r, erros.ErrInternal, http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(registro, r)
+		})
+	}
+}
+
+// nomeiaSpanPelaRota usa a rota do ServeMux que vai atender a requisição para nomear o span e preencher
+// http.route. A rota é consultada antes do handler porque o ServeMux só preenche r.Pattern na cópia da
+// requisição que recebe, e o timeout do handler cria uma nova cópia.
+func nomeiaSpanPelaRota(mux *http.ServeMux) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rota := rotaDoPadrao(padraoDa(mux, r)); rota != "" {
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(r.Method + " " + rota)
+				span.SetAttributes(semconv.HTTPRoute(rota))
+
+				if info, ok := r.Context().Value(chaveInfoRequisicao{}).(*infoRequisicao); ok {
+					info.rota = rota
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitaTempo cancela o contexto da requisição quando o handler passa do tempo definido para a rota e
+// responde 503. Um timeout zero deixa a rota sem limite.
+func LimitaTempo(mux *http.ServeMux, timeoutDa func(padrao string) time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := timeoutDa(padraoDa(mux, r))
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mensagem := i18n.Mensagem(i18n.DoContexto(r.Context()), erros.ErrRequestTimeout)
+			http.TimeoutHandler(next, timeout, mensagem).ServeHTTP(w, r)
+		})
+	}
+}
+
+// padraoDa devolve o padrão com que a rota foi registrada, como "GET /temperaturas/{cep}", ou vazio
+// quando nenhuma rota atende a requisição.
+func padraoDa(mux *http.ServeMux, r *http.Request) string {
+	_, padrao := mux.Handler(r)
+	return padrao
+}
+
+func rotaDoPadrao(padrao string) string {
+	if i := strings.IndexByte(padrao, ' '); i >= 0 {
+		return padrao[i+1:]
+	}
+	return padrao
+}
+
+type chaveInfoRequisicao struct{}
+
+// infoRequisicao é preenchida pelos middlewares internos e lida pelo log de acesso, que não recebe
+// o contexto nem a requisição repassados adiante.
+type infoRequisicao struct {
+	rota    string
+	cliente string
+}
+
+// respostaRegistrada guarda o status e o total de bytes escritos pelo handler.
+type respostaRegistrada struct {
+	http.ResponseWriter
+	status  int
+	bytes   int
+	escrito bool
+}
+
+func registraResposta(w http.ResponseWriter) *respostaRegistrada {
+	if registro, ok := w.(*respostaRegistrada); ok {
+		return registro
+	}
+	return &respostaRegistrada{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *respostaRegistrada) WriteHeader(status int) {
+	if !r.escrito {
+		r.status = status
+		r.escrito = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *respostaRegistrada) Write(b []byte) (int, error) {
+	r.escrito = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *respostaRegistrada) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *respostaRegistrada) Flush() {
+	http.NewResponseController(r.ResponseWriter).Flush()
+}
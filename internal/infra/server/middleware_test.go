@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/cliente"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/requestid"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	spans      *tracetest.SpanRecorder
+	idRecebido string
+	handler    http.Handler
+}
+
+func TestMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
+
+func (s *MiddlewareTestSuite) SetupTest() {
+	s.spans = tracetest.NewSpanRecorder()
+	s.idRecebido = ""
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /temperaturas/{cep}", func(w http.ResponseWriter, r *http.Request) {
+		s.idRecebido = requestid.DoContexto(r.Context())
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /panico", func(w http.ResponseWriter, r *http.Request) {
+		panic("falha inesperada")
+	})
+
+	s.handler = Encadeia(mux,
+		Telemetria("teste", otelhttp.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans)))),
+		RequestID(),
+		Idioma(func() i18n.Idioma { return i18n.Ingles }),
+		AccessLog(),
+		Recuperacao(),
+		nomeiaSpanPelaRota(mux),
+	)
+}
+
+func (s *MiddlewareTestSuite) atributo(span sdktrace.ReadOnlySpan, chave attribute.Key) string {
+	for _, atributo := range span.Attributes() {
+		if atributo.Key == chave {
+			return atributo.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func (s *MiddlewareTestSuite) TestNaoUsaClienteDoBaggageAntesDaAutenticacao() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/temperaturas/01001000", nil)
+	req = req.WithContext(cliente.NovoContexto(req.Context(), "cliente-a"))
+
+	// Act
+	s.handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 1)
+	s.Empty(s.atributo(spans[0], semconv.EnduserIDKey))
+}
+
+func (s *MiddlewareTestSuite) TestGeraRequestIDENomeiaSpanPelaRota() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/temperaturas/01001000", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.handler.ServeHTTP(rec, req)
+
+	// Assert
+	id := rec.Header().Get(requestid.Header)
+	s.Equal(http.StatusOK, rec.Code)
+	s.Len(id, 32)
+	s.Equal(id, s.idRecebido)
+
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("GET /temperaturas/{cep}", spans[0].Name())
+	s.Equal("/temperaturas/{cep}", s.atributo(spans[0], "http.route"))
+	s.Equal(id, s.atributo(spans[0], "http.request.id"))
+}
+
+func (s *MiddlewareTestSuite) TestReaproveitaRequestIDValido() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/temperaturas/01001000", nil)
+	req.Header.Set(requestid.Header, "abc-123")
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.handler.ServeHTTP(rec, req)
+
+	// Assert
+	s.Equal("abc-123", rec.Header().Get(requestid.Header))
+	s.Equal("abc-123", s.idRecebido)
+}
+
+func (s *MiddlewareTestSuite) TestDescartaRequestIDInvalido() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/temperaturas/01001000", nil)
+	req.Header.Set(requestid.Header, "id com espaços\n")
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.handler.ServeHTTP(rec, req)
+
+	// Assert
+	s.NotEqual("id com espaços\n", s.idRecebido)
+	s.Len(s.idRecebido, 32)
+}
+
+func (s *MiddlewareTestSuite) TestRecuperaPanicComErro500() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/panico", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.handler.ServeHTTP(rec, req)
+
+	// Assert
+	s.Equal(http.StatusInternalServerError, rec.Code)
+	s.NotEmpty(rec.Header().Get(requestid.Header))
+
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("GET /panico", spans[0].Name())
+
+	eventos := []string{}
+	for _, evento := range spans[0].Events() {
+		eventos = append(eventos, evento.Name)
+	}
+	s.Contains(eventos, "Panic recuperado")
+}
+
+func (s *MiddlewareTestSuite) TestTraduzErroPeloAcceptLanguage() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/panico", nil)
+	req.Header.Set("Accept-Language", "pt-PT, en;q=0.5")
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.handler.ServeHTTP(rec, req)
+
+	// Assert
+	s.Equal(http.StatusInternalServerError, rec.Code)
+	s.Equal("pt-BR", rec.Header().Get("Content-Language"))
+	s.Equal("Accept-Language", rec.Header().Get("Vary"))
+	s.Equal("Erro interno do servidor\n", rec.Body.String())
+}
+
+func (s *MiddlewareTestSuite) TestRepassaErrAbortHandler() {
+	// Arrange
+	handler := Recuperacao()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	// Act & Assert
+	s.PanicsWithValue(http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/logger"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ObservaConfiguracao habilita a recarga a quente dos arquivos de configuração de path, iniciada em Run.
+func (s *server) ObservaConfiguracao(path string, servico config.Servico) {
+	s.configPath = path
+	s.servico = servico
+}
+
+func (s *server) observaConfiguracao() {
+	if s.servico == 0 {
+		return
+	}
+
+	arquivos := config.Observa(s.configPath, s.servico, AplicaRecarga(otel.GetTracer(s.serviceName)))
+	if len(arquivos) == 0 {
+		slog.Info("nenhum arquivo de configuração encontrado, recarga a quente desabilitada", "diretorio", s.configPath)
+		return
+	}
+	slog.Info("observando arquivos de configuração", "arquivos", arquivos)
+}
+
+// AplicaRecarga propaga a configuração recarregada para o logger, a amostragem e os transports dos
+// clients, e registra o que mudou em log e em um span próprio.
+func AplicaRecarga(tracer trace.Tracer) func(config.Recarga) {
+	return func(recarga config.Recarga) {
+		_, span := otel.StartSpan(context.Background(), tracer, "RecarregaConfiguracao")
+		defer span.End()
+
+		if recarga.Erro != nil {
+			otel.RecordSpanError(span, recarga.Erro)
+			slog.Error("configuração recarregada inválida, mantendo a anterior", "erro", recarga.Erro)
+			return
+		}
+
+		atual := recarga.Atual
+		if nivel, err := atual.GetLog().NivelSlog(); err == nil {
+			logger.DefineNivel(nivel)
+		}
+		otel.DefineTaxaAmostragem(atual.GetTelemetria().Amostragem)
+		clients.LiberaTransportes(
+			atual.GetViaCep(),
+			atual.GetWeatherApi(),
+			atual.GetOpenMeteo(),
+			atual.GetOpenMeteoGeocoding(),
+			atual.GetServicoB(),
+		)
+
+		for _, mudanca := range recarga.Aplicadas {
+			otel.AddSpanEvent(span, "Configuração alterada", map[string]interface{}{
+				"chave":    mudanca.Chave,
+				"anterior": mudanca.Anterior,
+				"atual":    mudanca.Atual,
+			})
+		}
+		for _, mudanca := range recarga.Ignoradas {
+			otel.AddSpanEvent(span, "Alteração ignorada, requer reinício", map[string]interface{}{"chave": mudanca.Chave})
+		}
+
+		slog.Info("configuração recarregada", "aplicadas", recarga.Aplicadas, "ignoradas_ate_reiniciar", recarga.Ignoradas)
+	}
+}
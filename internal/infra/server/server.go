@@ -2,39 +2,176 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/certificados"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
 )
 
 type server struct {
 	serviceName string
 	port        int32
+	tls         config.TLSServidorConfig
+	servidor    config.ServidorConfig
+	timeouts    map[string]time.Duration
+	telemetria  config.TelemetriaConfig
 	mux         *http.ServeMux
+	middlewares []Middleware
+
+	configPath string
+	servico    config.Servico
 }
 
-func NewServer(serviceName string, port int32) *server {
+func NewServer(servidor config.ServidorConfig, telemetria config.TelemetriaConfig) *server {
+	// A configuração já foi validada na inicialização; uma lista inválida apenas deixa de sobrepor as rotas.
+	timeouts, _ := servidor.TimeoutsPorRota()
+
 	return &server{
-		serviceName: serviceName,
-		port:        port,
+		serviceName: servidor.Nome,
+		port:        int32(servidor.Porta),
+		tls:         servidor.TLS,
+		servidor:    servidor,
+		timeouts:    timeouts,
+		telemetria:  telemetria,
 		mux:         http.NewServeMux(),
 	}
 }
 
-func (s *server) Run(serverMuxCallBack func(serverMux *http.ServeMux)) {
-	shutdown := otel.InitTracer(s.serviceName)
+// Use registra middlewares aplicados a todas as rotas, dentro da telemetria, do request ID, do log de
+// acesso e da recuperação de panics, que o servidor sempre aplica nessa ordem, e fora do timeout do handler.
+func (s *server) Use(middlewares ...Middleware) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+func (s *server) handler() http.Handler {
+	middlewares := append([]Middleware{
+		Telemetria(s.serviceName),
+		RequestID(),
+		Idioma(func() i18n.Idioma { return config.Get().GetIdioma() }),
+		AccessLog(),
+		Recuperacao(),
+	}, s.middlewares...)
+
+	return Encadeia(s.mux, append(middlewares, nomeiaSpanPelaRota(s.mux), LimitaTempo(s.mux, s.timeoutDa))...)
+}
+
+// Run atende até o fim de ctx e então encerra o servidor, esperando as requisições em andamento por até
+// shutdown_timeout antes de desligar a telemetria.
+func (s *server) Run(ctx context.Context, serverMuxCallBack func(serverMux *http.ServeMux)) {
+	endpoint, tlsColetor, err := coletor(s.telemetria)
+	if err != nil {
+		log.Fatalf("falha ao configurar o TLS do collector: %v", err)
+	}
+
+	otel.DefineTaxaAmostragem(s.telemetria.Amostragem)
+	shutdown := otel.InitTracer(s.serviceName, endpoint, tlsColetor)
 	defer func() {
 		if err := shutdown(context.Background()); err != nil {
-			log.Fatalf("falha ao desligar o provedor de tracer: %v", err)
+			log.Printf("falha ao desligar o provedor de tracer: %v", err)
+		}
+	}()
+	shutdownMetricas := otel.InitMetricas(s.serviceName, endpoint, tlsColetor)
+	defer func() {
+		if err := shutdownMetricas(context.Background()); err != nil {
+			log.Printf("falha ao desligar o provedor de métricas: %v", err)
 		}
 	}()
 
+	s.observaConfiguracao()
+
 	serverMuxCallBack(s.mux)
-	log.Printf("Servidor escutando na porta :%d", s.port)
 
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", s.port), s.mux); err != nil {
+	httpServer, err := s.servidorHTTP()
+	if err != nil {
+		log.Fatalf("falha ao configurar o TLS do servidor: %v", err)
+	}
+
+	atendimento := make(chan error, 1)
+	go func() {
+		if httpServer.TLSConfig != nil {
+			log.Printf("Servidor escutando na porta :%d com TLS", s.port)
+			atendimento <- httpServer.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("Servidor escutando na porta :%d", s.port)
+			atendimento <- httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-atendimento:
 		log.Println(err)
+		return
+	case <-ctx.Done():
+	}
+
+	log.Printf("Encerrando o servidor, aguardando as requisições em andamento por até %s", s.servidor.ShutdownTimeout)
+	encerramento, cancel := context.WithTimeout(context.Background(), s.servidor.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(encerramento); err != nil {
+		log.Printf("falha ao encerrar o servidor: %v", err)
 	}
 }
+
+// timeoutDa procura o timeout da rota pelo padrão completo e depois sem o método, usando o handler_timeout
+// quando a rota não foi configurada.
+func (s *server) timeoutDa(padrao string) time.Duration {
+	if timeout, ok := s.timeouts[padrao]; ok {
+		return timeout
+	}
+	if timeout, ok := s.timeouts[rotaDoPadrao(padrao)]; ok {
+		return timeout
+	}
+	return s.servidor.HandlerTimeout
+}
+
+// servidorHTTP monta o http.Server com a cadeia de middlewares, os limites das conexões e, quando
+// habilitados, o TLS ou o h2c.
+func (s *server) servidorHTTP() (*http.Server, error) {
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", s.port),
+		Handler:           s.handler(),
+		ReadHeaderTimeout: s.servidor.ReadHeaderTimeout,
+		ReadTimeout:       s.servidor.ReadTimeout,
+		WriteTimeout:      s.servidor.WriteTimeout,
+		IdleTimeout:       s.servidor.IdleTimeout,
+		MaxHeaderBytes:    s.servidor.MaxHeaderBytes,
+	}
+
+	if s.servidor.H2C && !s.tls.Habilitado {
+		httpServer.Protocols = new(http.Protocols)
+		httpServer.Protocols.SetHTTP1(true)
+		httpServer.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	if s.tls.Habilitado {
+		tlsConfig, err := certificados.Servidor(s.tls)
+		if err != nil {
+			return nil, err
+		}
+		httpServer.TLSConfig = tlsConfig
+	}
+
+	return httpServer, nil
+}
+
+// coletor separa o esquema do endpoint do collector: https:// liga o TLS com as opções de telemetria.tls.
+func coletor(telemetria config.TelemetriaConfig) (string, *tls.Config, error) {
+	endpoint := telemetria.CollectorEndpoint
+	if !strings.HasPrefix(endpoint, "https://") {
+		return strings.TrimPrefix(endpoint, "http://"), nil, nil
+	}
+
+	tlsConfig, err := certificados.Cliente(telemetria.TLS)
+	if tlsConfig == nil && err == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return strings.TrimPrefix(endpoint, "https://"), tlsConfig, err
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServidorTestSuite struct {
+	suite.Suite
+	servidor     *server
+	erroContexto chan error
+}
+
+func TestServidorSuite(t *testing.T) {
+	suite.Run(t, new(ServidorTestSuite))
+}
+
+func (s *ServidorTestSuite) SetupTest() {
+	s.erroContexto = make(chan error, 1)
+	s.servidor = NewServer(config.ServidorConfig{
+		Nome:              "teste",
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      time.Second,
+		MaxHeaderBytes:    8 << 10,
+		HandlerTimeout:    50 * time.Millisecond,
+		TimeoutsRotas:     "/sem-limite=0s",
+		H2C:               true,
+	}, config.TelemetriaConfig{})
+
+	s.servidor.mux.HandleFunc("GET /demorada", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			s.erroContexto <- r.Context().Err()
+		case <-time.After(time.Second):
+			s.erroContexto <- nil
+		}
+	})
+	s.servidor.mux.HandleFunc("GET /sem-limite", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(r.Proto))
+	})
+}
+
+func (s *ServidorTestSuite) TestTimeoutDoHandlerCancelaContexto() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/demorada", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.servidor.handler().ServeHTTP(rec, req)
+
+	// Assert
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+	s.Equal(erros.ErrRequestTimeout.Error(), rec.Body.String())
+	s.ErrorIs(<-s.erroContexto, context.DeadlineExceeded)
+}
+
+func (s *ServidorTestSuite) TestRotaSemLimiteDeTempo() {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/sem-limite", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	s.servidor.handler().ServeHTTP(rec, req)
+
+	// Assert
+	s.Equal(http.StatusOK, rec.Code)
+}
+
+func (s *ServidorTestSuite) TestServidorHTTPComLimitesEH2C() {
+	// Arrange
+	httpServer, err := s.servidor.servidorHTTP()
+	s.Require().NoError(err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	go httpServer.Serve(listener)
+	defer httpServer.Close()
+
+	transporte := &http.Transport{Protocols: new(http.Protocols)}
+	transporte.Protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: transporte}
+
+	// Act
+	resp, err := client.Get("http://" + listener.Addr().String() + "/sem-limite")
+
+	// Assert
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	corpo, err := io.ReadAll(resp.Body)
+	s.NoError(err)
+	s.Equal("HTTP/2.0", string(corpo))
+	s.Equal(5*time.Second, httpServer.ReadHeaderTimeout)
+	s.Equal(time.Second, httpServer.WriteTimeout)
+	s.Equal(8<<10, httpServer.MaxHeaderBytes)
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/handlers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TLSTestSuite struct {
+	suite.Suite
+	dir      string
+	ca       *x509.Certificate
+	chaveCA  *ecdsa.PrivateKey
+	poolCA   *x509.CertPool
+	spans    *tracetest.SpanRecorder
+	servicoA string
+	servicoB string
+	encerrar []func()
+}
+
+func TestTLSSuite(t *testing.T) {
+	suite.Run(t, new(TLSTestSuite))
+}
+
+// SetupTest sobe o Serviço A e o Serviço B com TLS, exigindo do Serviço A um certificado de cliente
+// para falar com o Serviço B, e registra os spans dos dois no mesmo provedor.
+func (s *TLSTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.encerrar = nil
+	s.geraCA()
+	s.geraCertificado("servidor", "localhost")
+	s.geraCertificado("cliente-a", "servico-a")
+
+	s.spans = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	viaCep := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"cep": "01001-000", "localidade": "São Paulo", "uf": "SP"}`)
+	}))
+	weatherApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"location": {"name": "Sao Paulo"}, "current": {"temp_c": 28.5, "last_updated_epoch": 1749124800}}`)
+	}))
+	s.encerrar = append(s.encerrar, viaCep.Close, weatherApi.Close)
+
+	listenerA := s.escuta()
+	listenerB := s.escuta()
+	s.servicoA = "https://localhost:" + porta(listenerA)
+	s.servicoB = "https://localhost:" + porta(listenerB)
+
+	s.T().Setenv("AMBIENTE_PUBLICACAO", "LOCAL")
+	s.T().Setenv("WEATHER_API_KEY", "chave-de-teste")
+	s.T().Setenv("VIACEP_BASE_URL", viaCep.URL+"/ws/")
+	s.T().Setenv("WEATHERAPI_BASE_URL", weatherApi.URL+"/v1/")
+	s.T().Setenv("SERVIDOR_A_TLS_HABILITADO", "true")
+	s.T().Setenv("SERVIDOR_A_TLS_CERTIFICADO", s.arquivo("servidor.pem"))
+	s.T().Setenv("SERVIDOR_A_TLS_CHAVE", s.arquivo("servidor-chave.pem"))
+	s.T().Setenv("SERVIDOR_B_TLS_HABILITADO", "true")
+	s.T().Setenv("SERVIDOR_B_TLS_CERTIFICADO", s.arquivo("servidor.pem"))
+	s.T().Setenv("SERVIDOR_B_TLS_CHAVE", s.arquivo("servidor-chave.pem"))
+	s.T().Setenv("SERVIDOR_B_TLS_CA_CLIENTES", s.arquivo("ca.pem"))
+	s.T().Setenv("SERVICO_B_BASE_URL", s.servicoB)
+	s.T().Setenv("SERVICO_B_TLS_CA", s.arquivo("ca.pem"))
+	s.T().Setenv("SERVICO_B_TLS_CERTIFICADO", s.arquivo("cliente-a.pem"))
+	s.T().Setenv("SERVICO_B_TLS_CHAVE", s.arquivo("cliente-a-chave.pem"))
+	s.Require().NoError(config.LoadConfig(s.dir))
+	cfg := config.Get()
+	s.Require().NoError(cfg.ValidaServicoA())
+	s.Require().NoError(cfg.ValidaServicoB())
+
+	servicoB := NewServer(cfg.GetServidorB(), cfg.GetTelemetria())
+	servicoB.mux.HandleFunc("GET /cidades/{cep}/temperaturas", handlers.ProcessaTemperaturasHandler(otel.Tracer("Serviço B"), historico.NewGravador(historico.NewRepositorioMemoria(), 10)))
+	s.serve(servicoB, listenerB)
+
+	servicoA := NewServer(cfg.GetServidorA(), cfg.GetTelemetria())
+	servicoA.mux.HandleFunc("POST /temperaturas", handlers.CapturaTemperaturasHandler(otel.Tracer("Serviço A")))
+	s.serve(servicoA, listenerA)
+}
+
+func (s *TLSTestSuite) TearDownTest() {
+	for _, encerra := range s.encerrar {
+		encerra()
+	}
+	otel.SetTracerProvider(noop.NewTracerProvider())
+}
+
+func (s *TLSTestSuite) TestRequisicaoRastreadaComMTLS() {
+	// Arrange
+	client := s.client(nil)
+
+	// Act
+	resp, err := client.Post(s.servicoA+"/temperaturas", "application/json", strings.NewReader(`{"cep": "01001000"}`))
+
+	// Assert
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	corpo, _ := io.ReadAll(resp.Body)
+	s.Equal(http.StatusOK, resp.StatusCode, string(corpo))
+	s.Contains(string(corpo), "São Paulo")
+
+	servidores := map[string]trace.TraceID{}
+	for _, span := range s.spans.Ended() {
+		if span.SpanKind() == trace.SpanKindServer {
+			servidores[span.Name()] = span.SpanContext().TraceID()
+		}
+	}
+	s.Require().Contains(servidores, "POST /temperaturas")
+	s.Require().Contains(servidores, "GET /cidades/{cep}/temperaturas")
+	s.Equal(servidores["POST /temperaturas"], servidores["GET /cidades/{cep}/temperaturas"])
+}
+
+func (s *TLSTestSuite) TestServicoBRecusaClienteSemCertificado() {
+	// Arrange
+	client := s.client(nil)
+
+	// Act
+	_, err := client.Get(s.servicoB + "/cidades/01001000/temperaturas")
+
+	// Assert
+	s.Error(err)
+}
+
+func (s *TLSTestSuite) serve(servidor *server, listener net.Listener) {
+	httpServer, err := servidor.servidorHTTP()
+	s.Require().NoError(err)
+	go httpServer.ServeTLS(listener, "", "")
+	s.encerrar = append(s.encerrar, func() { httpServer.Close() })
+}
+
+func (s *TLSTestSuite) escuta() net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	return listener
+}
+
+func porta(listener net.Listener) string {
+	_, porta, _ := net.SplitHostPort(listener.Addr().String())
+	return porta
+}
+
+func (s *TLSTestSuite) client(certificado *tls.Certificate) *http.Client {
+	tlsConfig := &tls.Config{RootCAs: s.poolCA}
+	if certificado != nil {
+		tlsConfig.Certificates = []tls.Certificate{*certificado}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}, Timeout: 5 * time.Second}
+}
+
+func (s *TLSTestSuite) arquivo(nome string) string {
+	return filepath.Join(s.dir, nome)
+}
+
+func (s *TLSTestSuite) geraCA() {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	modelo := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "CA de teste"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, modelo, modelo, &chave.PublicKey, chave)
+	s.Require().NoError(err)
+	s.ca, err = x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	s.chaveCA = chave
+	s.poolCA = x509.NewCertPool()
+	s.poolCA.AddCert(s.ca)
+
+	s.gravaPEM("ca.pem", "CERTIFICATE", der)
+}
+
+func (s *TLSTestSuite) geraCertificado(nome, commonName string) {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	modelo := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, modelo, s.ca, &chave.PublicKey, s.chaveCA)
+	s.Require().NoError(err)
+	chaveDer, err := x509.MarshalECPrivateKey(chave)
+	s.Require().NoError(err)
+
+	s.gravaPEM(nome+".pem", "CERTIFICATE", der)
+	s.gravaPEM(nome+"-chave.pem", "EC PRIVATE KEY", chaveDer)
+}
+
+func (s *TLSTestSuite) gravaPEM(nome, tipo string, der []byte) {
+	conteudo := pem.EncodeToMemory(&pem.Block{Type: tipo, Bytes: der})
+	s.Require().NoError(os.WriteFile(s.arquivo(nome), conteudo, 0o600))
+}
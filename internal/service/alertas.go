@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EntregadorWebhook faz uma tentativa de entrega; erros.ErrWebhookRejected indica que não adianta tentar
+// de novo.
+type EntregadorWebhook interface {
+	Entrega(ctx context.Context, url, segredo, eventoID string, corpo []byte) error
+}
+
+// EventoAlerta é o corpo do webhook enviado quando a temperatura de um CEP cruza o limite da assinatura.
+type EventoAlerta struct {
+	ID                 string    `json:"id"`
+	SubscriptionID     string    `json:"subscription_id"`
+	Cep                string    `json:"cep"`
+	City               string    `json:"city"`
+	Condition          string    `json:"condition"`
+	ThresholdCelsius   float64   `json:"threshold_celsius"`
+	TemperatureCelsius float64   `json:"temperature_celsius"`
+	ObservedAt         time.Time `json:"observed_at"`
+	TriggeredAt        time.Time `json:"triggered_at"`
+}
+
+// Avaliador confere as assinaturas de alerta a cada alertas.intervalo. Cada CEP é consultado uma vez
+// por rodada, e cada assinatura dispara ao cruzar o limite, voltando a disparar só depois que a
+// temperatura volta para o outro lado. Entregas que esgotam as tentativas vão para a lista de falhas.
+type Avaliador struct {
+	tracer      trace.Tracer
+	novoService func() *TemperaturasService
+	novoWebhook func(config.AlertasConfig) EntregadorWebhook
+	repositorio alertas.Repositorio
+}
+
+// NewAvaliador recebe fábricas do serviço e do client de webhook, chamadas a cada rodada para usar a
+// configuração atual.
+func NewAvaliador(
+	tracer trace.Tracer,
+	novoService func() *TemperaturasService,
+	novoWebhook func(config.AlertasConfig) EntregadorWebhook,
+	repositorio alertas.Repositorio,
+) *Avaliador {
+	return &Avaliador{
+		tracer:      tracer,
+		novoService: novoService,
+		novoWebhook: novoWebhook,
+		repositorio: repositorio,
+	}
+}
+
+// Executa faz uma rodada imediatamente e as seguintes a cada intervalo, até o fim do contexto.
+func (a *Avaliador) Executa(ctx context.Context) {
+	for {
+		cfg := config.Get().GetAlertas()
+		a.Avalia(ctx, cfg)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.Intervalo):
+		}
+	}
+}
+
+// Avalia confere todas as assinaturas em um trace próprio, que inclui as entregas dos webhooks. Sem
+// assinaturas, a rodada não gera trace.
+func (a *Avaliador) Avalia(ctx context.Context, cfg config.AlertasConfig) {
+	assinaturas, err := a.repositorio.Todas(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "falha ao listar as assinaturas de alerta", "erro", err)
+		return
+	}
+	if len(assinaturas) == 0 {
+		return
+	}
+
+	ctx, span := a.tracer.Start(ctx, "AvaliaAlertas", trace.WithNewRoot(), trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+	span.SetAttributes(attribute.Int("alertas.assinaturas", len(assinaturas)))
+
+	ceps := []string{}
+	porCep := map[string][]alertas.Assinatura{}
+	for _, assinatura := range assinaturas {
+		if _, ok := porCep[assinatura.Cep]; !ok {
+			ceps = append(ceps, assinatura.Cep)
+		}
+		porCep[assinatura.Cep] = append(porCep[assinatura.Cep], assinatura)
+	}
+
+	service := a.novoService()
+	webhook := a.novoWebhook(cfg)
+	for _, cep := range ceps {
+		a.avaliaCep(ctx, service, webhook, cfg, cep, porCep[cep])
+	}
+}
+
+func (a *Avaliador) avaliaCep(ctx context.Context, service *TemperaturasService, webhook EntregadorWebhook, cfg config.AlertasConfig, cep string, assinaturas []alertas.Assinatura) {
+	ctx, span := otel.StartSpan(ctx, a.tracer, "AvaliaCep")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", cep), attribute.Int("alertas.assinaturas", len(assinaturas)))
+
+	dados, err := service.Processa(ctx, cep)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		slog.WarnContext(ctx, "falha ao consultar a temperatura das assinaturas de alerta", "cep", cep, "erro", err)
+		return
+	}
+	celsius := dados.Temperatura.Celsius()
+
+	var entregas sync.WaitGroup
+	defer entregas.Wait()
+
+	for _, assinatura := range assinaturas {
+		atende := assinatura.Atende(celsius)
+		if atende == assinatura.Disparada {
+			continue
+		}
+		if err := a.repositorio.DefineDisparada(ctx, assinatura.ID, atende); err != nil {
+			slog.ErrorContext(ctx, "falha ao gravar o estado da assinatura de alerta", "assinatura_id", assinatura.ID, "erro", err)
+			continue
+		}
+		if !atende {
+			otel.AddSpanEvent(span, "Alerta normalizado", map[string]interface{}{"assinatura_id": assinatura.ID})
+			continue
+		}
+
+		evento := EventoAlerta{
+			ID:                 alertas.NovoID(16),
+			SubscriptionID:     assinatura.ID,
+			Cep:                cep,
+			City:               dados.City,
+			Condition:          string(assinatura.Condicao),
+			ThresholdCelsius:   assinatura.Limite,
+			TemperatureCelsius: celsius,
+			ObservedAt:         dados.ObservedAt,
+			TriggeredAt:        time.Now().UTC(),
+		}
+		otel.AddSpanEvent(span, "Alerta disparado", map[string]interface{}{"assinatura_id": assinatura.ID, "evento_id": evento.ID})
+
+		entregas.Add(1)
+		go func() {
+			defer entregas.Done()
+			a.entrega(ctx, webhook, cfg, assinatura, evento)
+		}()
+	}
+}
+
+// entrega tenta o webhook até cfg.Tentativas vezes, dobrando a espera entre as tentativas, e registra a
+// falha quando elas se esgotam ou o destino recusa o evento.
+func (a *Avaliador) entrega(ctx context.Context, webhook EntregadorWebhook, cfg config.AlertasConfig, assinatura alertas.Assinatura, evento EventoAlerta) {
+	corpo, err := json.Marshal(evento)
+	if err != nil {
+		slog.ErrorContext(ctx, "falha ao serializar o evento de alerta", "evento_id", evento.ID, "erro", err)
+		return
+	}
+
+	tentativas := 0
+	espera := cfg.EsperaInicial
+	for tentativas < cfg.Tentativas {
+		tentativas++
+		if err = webhook.Entrega(ctx, assinatura.URL, assinatura.Segredo, evento.ID, corpo); err == nil {
+			return
+		}
+		if errors.Is(err, erros.ErrWebhookRejected) || tentativas == cfg.Tentativas {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(espera):
+			espera *= 2
+			continue
+		}
+		break
+	}
+
+	slog.WarnContext(ctx, "entrega do alerta falhou, registrada na lista de falhas",
+		"assinatura_id", assinatura.ID, "evento_id", evento.ID, "tentativas", tentativas, "erro", err)
+	falha := alertas.Falha{
+		AssinaturaID: assinatura.ID,
+		EventoID:     evento.ID,
+		Corpo:        corpo,
+		Tentativas:   tentativas,
+		Erro:         err.Error(),
+		Em:           time.Now().UTC(),
+	}
+	if err := a.repositorio.RegistraFalha(context.WithoutCancel(ctx), falha); err != nil {
+		slog.ErrorContext(ctx, "falha ao registrar a entrega do alerta que falhou", "evento_id", evento.ID, "erro", err)
+	}
+}
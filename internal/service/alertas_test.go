@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/alertas"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/stretchr/testify/suite"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AvaliadorTestSuite struct {
+	suite.Suite
+	viacepClientMock     *ViaCepClientMock
+	weatherapiClientMock *WeatherApiClientMock
+	repositorio          *alertas.RepositorioMemoria
+	webhook              *entregadorRoteirizado
+	cfg                  config.AlertasConfig
+	avaliador            *Avaliador
+}
+
+// entregadorRoteirizado devolve os erros de respostas na ordem e registra cada tentativa.
+type entregadorRoteirizado struct {
+	mu         sync.Mutex
+	respostas  []error
+	tentativas []tentativaWebhook
+}
+
+type tentativaWebhook struct {
+	url     string
+	corpo   []byte
+	traceID trace.TraceID
+}
+
+func (e *entregadorRoteirizado) Entrega(ctx context.Context, url, _, _ string, corpo []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.tentativas = append(e.tentativas, tentativaWebhook{url: url, corpo: corpo, traceID: trace.SpanContextFromContext(ctx).TraceID()})
+	if len(e.respostas) == 0 {
+		return nil
+	}
+	resposta := e.respostas[0]
+	e.respostas = e.respostas[1:]
+	return resposta
+}
+
+func TestAvaliadorSuite(t *testing.T) {
+	suite.Run(t, new(AvaliadorTestSuite))
+}
+
+func (s *AvaliadorTestSuite) SetupTest() {
+	s.viacepClientMock = new(ViaCepClientMock)
+	s.weatherapiClientMock = new(WeatherApiClientMock)
+	s.repositorio = alertas.NewRepositorioMemoria()
+	s.webhook = &entregadorRoteirizado{}
+	s.cfg = config.AlertasConfig{Tentativas: 3, EsperaInicial: time.Millisecond, Timeout: time.Second}
+	tracer := sdktrace.NewTracerProvider().Tracer("teste")
+
+	s.avaliador = NewAvaliador(tracer, func() *TemperaturasService {
+		return NewTemperaturasService(
+			usecases.NewConsultaCepUseCase(s.viacepClientMock),
+			usecases.NewCalculaTemperaturasUseCase(s.weatherapiClientMock),
+		)
+	}, func(config.AlertasConfig) EntregadorWebhook { return s.webhook }, s.repositorio)
+
+	s.viacepClientMock.On("ConsultaCep", "01001000").Return(&clients.DadosCepResponse{Localidade: "São Paulo", Uf: "SP"}, nil)
+	s.Require().NoError(s.repositorio.Salva(context.Background(), alertas.Assinatura{
+		ID:       "galpao",
+		Cep:      "01001000",
+		Condicao: alertas.CondicaoAcima,
+		Limite:   35,
+		URL:      "https://alertas.example.com/webhook",
+		Segredo:  "segredo-com-16-caracteres",
+	}))
+}
+
+func (s *AvaliadorTestSuite) temperatura(celsius float64) {
+	s.weatherapiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(&clients.WeatherResponse{
+		Current: clients.Current{TempC: celsius, LastUpdatedEpoch: 1749513600},
+	}, nil).Once()
+}
+
+func (s *AvaliadorTestSuite) TestDisparaUmaVezPorCruzamento() {
+	// Arrange
+	s.temperatura(36.2)
+	s.temperatura(37)
+	s.temperatura(30)
+	s.temperatura(36)
+
+	// Act
+	s.avaliador.Avalia(context.Background(), s.cfg)
+	s.avaliador.Avalia(context.Background(), s.cfg)
+	s.avaliador.Avalia(context.Background(), s.cfg)
+	assinatura, _ := s.repositorio.Busca(context.Background(), "", "galpao")
+	s.False(assinatura.Disparada, "a temperatura abaixo do limite rearma o alerta")
+	s.avaliador.Avalia(context.Background(), s.cfg)
+
+	// Assert
+	s.Require().Len(s.webhook.tentativas, 2)
+	evento := EventoAlerta{}
+	s.Require().NoError(json.Unmarshal(s.webhook.tentativas[0].corpo, &evento))
+	s.Equal("galpao", evento.SubscriptionID)
+	s.Equal("São Paulo", evento.City)
+	s.Equal("above", evento.Condition)
+	s.Equal(35.0, evento.ThresholdCelsius)
+	s.Equal(36.2, evento.TemperatureCelsius)
+	s.Equal("https://alertas.example.com/webhook", s.webhook.tentativas[0].url)
+	s.True(s.webhook.tentativas[0].traceID.IsValid())
+	s.NotEqual(s.webhook.tentativas[0].traceID, s.webhook.tentativas[1].traceID, "cada rodada é um trace próprio")
+
+	assinatura, _ = s.repositorio.Busca(context.Background(), "", "galpao")
+	s.True(assinatura.Disparada)
+}
+
+func (s *AvaliadorTestSuite) TestEsgotaAsTentativasERegistraAFalha() {
+	// Arrange
+	s.temperatura(36)
+	s.webhook.respostas = []error{fmt.Errorf("webhook respondeu 503"), fmt.Errorf("webhook respondeu 503"), fmt.Errorf("webhook respondeu 502")}
+
+	// Act
+	s.avaliador.Avalia(context.Background(), s.cfg)
+
+	// Assert
+	s.Len(s.webhook.tentativas, 3)
+	falhas, err := s.repositorio.ListaFalhas(context.Background(), "galpao")
+	s.Require().NoError(err)
+	s.Require().Len(falhas, 1)
+	s.Equal(3, falhas[0].Tentativas)
+	s.Equal("webhook respondeu 502", falhas[0].Erro)
+	s.Equal(s.webhook.tentativas[0].corpo, falhas[0].Corpo)
+}
+
+func (s *AvaliadorTestSuite) TestRecusaNaoETentadaDeNovo() {
+	// Arrange
+	s.temperatura(36)
+	s.webhook.respostas = []error{fmt.Errorf("%w: status 410", erros.ErrWebhookRejected)}
+
+	// Act
+	s.avaliador.Avalia(context.Background(), s.cfg)
+
+	// Assert
+	s.Len(s.webhook.tentativas, 1)
+	falhas, err := s.repositorio.ListaFalhas(context.Background(), "galpao")
+	s.Require().NoError(err)
+	s.Require().Len(falhas, 1)
+	s.Equal(1, falhas[0].Tentativas)
+	s.Contains(falhas[0].Erro, erros.ErrWebhookRejected.Error())
+}
+
+func (s *AvaliadorTestSuite) TestSemAssinaturasNaoConsulta() {
+	// Arrange
+	s.Require().NoError(s.repositorio.Remove(context.Background(), "", "galpao"))
+
+	// Act
+	s.avaliador.Avalia(context.Background(), s.cfg)
+
+	// Assert
+	s.viacepClientMock.AssertNotCalled(s.T(), "ConsultaCep", "01001000")
+	s.Empty(s.webhook.tentativas)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Amostrador consulta os CEPs de monitoramento.ceps a cada monitoramento.intervalo e grava as leituras no
+// histórico, para que as séries tenham pontos mesmo sem tráfego. A lista e o intervalo são relidos a cada
+// rodada, então acompanham a recarga da configuração.
+type Amostrador struct {
+	tracer      trace.Tracer
+	novoService func() *TemperaturasService
+	registrador historico.Registrador
+}
+
+// NewAmostrador recebe uma fábrica do serviço, chamada a cada rodada para usar o provedor de clima da
+// configuração atual.
+func NewAmostrador(tracer trace.Tracer, novoService func() *TemperaturasService, registrador historico.Registrador) *Amostrador {
+	return &Amostrador{
+		tracer:      tracer,
+		novoService: novoService,
+		registrador: registrador,
+	}
+}
+
+// Executa faz uma rodada imediatamente e as seguintes a cada intervalo, até o fim do contexto.
+func (a *Amostrador) Executa(ctx context.Context) {
+	for {
+		monitoramento := config.Get().GetMonitoramento()
+		if ceps := monitoramento.ListaCeps(); len(ceps) > 0 {
+			a.Amostra(ctx, ceps)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(monitoramento.Intervalo):
+		}
+	}
+}
+
+// Amostra consulta os CEPs em sequência, em um trace próprio por rodada. Um CEP com erro não interrompe
+// os demais.
+func (a *Amostrador) Amostra(ctx context.Context, ceps []string) {
+	ctx, span := a.tracer.Start(ctx, "AmostraTemperaturas", trace.WithNewRoot(), trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+	span.SetAttributes(attribute.Int("monitoramento.ceps", len(ceps)))
+
+	service := a.novoService()
+	falhas := 0
+	for _, cep := range ceps {
+		if err := a.amostraCep(ctx, service, cep); err != nil {
+			falhas++
+			slog.WarnContext(ctx, "falha ao amostrar a temperatura do CEP monitorado", "cep", cep, "erro", err)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("monitoramento.falhas", falhas))
+	if falhas > 0 {
+		span.SetStatus(codes.Error, "falha ao amostrar parte dos CEPs")
+	}
+}
+
+func (a *Amostrador) amostraCep(ctx context.Context, service *TemperaturasService, cep string) error {
+	ctx, span := otel.StartSpan(ctx, a.tracer, "AmostraCep")
+	defer span.End()
+	span.SetAttributes(attribute.String("cep", cep))
+
+	dados, err := service.Processa(ctx, cep)
+	if err != nil {
+		otel.RecordSpanError(span, err)
+		return err
+	}
+
+	a.registrador.Registra(historico.Consulta{
+		Cep:          dados.Cep,
+		Cidade:       dados.City,
+		Uf:           dados.Uf,
+		Celsius:      dados.Temperatura.Celsius(),
+		Provedor:     dados.Provedor,
+		TraceID:      span.SpanContext().TraceID().String(),
+		ObservadaEm:  dados.ObservedAt,
+		ConsultadaEm: time.Now().UTC(),
+	})
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/config"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/historico"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AmostradorTestSuite struct {
+	suite.Suite
+	viacepClientMock     *ViaCepClientMock
+	weatherapiClientMock *WeatherApiClientMock
+	registrador          *registradorMemoria
+	spans                *tracetest.SpanRecorder
+	amostrador           *Amostrador
+}
+
+type registradorMemoria struct {
+	consultas []historico.Consulta
+}
+
+func (r *registradorMemoria) Registra(consulta historico.Consulta) {
+	r.consultas = append(r.consultas, consulta)
+}
+
+func TestAmostradorSuite(t *testing.T) {
+	suite.Run(t, new(AmostradorTestSuite))
+}
+
+func (s *AmostradorTestSuite) SetupTest() {
+	s.viacepClientMock = new(ViaCepClientMock)
+	s.weatherapiClientMock = new(WeatherApiClientMock)
+	s.registrador = &registradorMemoria{}
+	s.spans = tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans)).Tracer("teste")
+
+	s.amostrador = NewAmostrador(tracer, func() *TemperaturasService {
+		return NewTemperaturasService(
+			usecases.NewConsultaCepUseCase(s.viacepClientMock),
+			usecases.NewCalculaTemperaturasUseCase(s.weatherapiClientMock),
+		)
+	}, s.registrador)
+}
+
+func (s *AmostradorTestSuite) TestAmostraRegistraOsCepsEmUmTraceProprio() {
+	// Arrange
+	s.viacepClientMock.On("ConsultaCep", "01001000").Return(&clients.DadosCepResponse{Localidade: "São Paulo", Uf: "SP"}, nil)
+	s.viacepClientMock.On("ConsultaCep", "99999999").Return(nil, erros.ErrZipCodeNotFound)
+	s.weatherapiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(&clients.WeatherResponse{
+		Current:  clients.Current{TempC: 21.4, LastUpdatedEpoch: 1749513600},
+		Provedor: config.ProvedorWeatherApi,
+	}, nil)
+
+	pai, requisicao := sdktrace.NewTracerProvider().Tracer("teste").Start(context.Background(), "requisicao")
+	defer requisicao.End()
+
+	// Act
+	s.amostrador.Amostra(pai, []string{"01001000", "99999999"})
+
+	// Assert
+	s.Require().Len(s.registrador.consultas, 1)
+	consulta := s.registrador.consultas[0]
+	s.Equal("01001000", consulta.Cep)
+	s.Equal("São Paulo", consulta.Cidade)
+	s.Equal("SP", consulta.Uf)
+	s.Equal(21.4, consulta.Celsius)
+	s.Equal(config.ProvedorWeatherApi, consulta.Provedor)
+	s.False(consulta.ConsultadaEm.IsZero())
+
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 3)
+	raiz := spans[2]
+	s.Equal("AmostraTemperaturas", raiz.Name())
+	s.False(raiz.Parent().IsValid(), "a rodada não deve herdar o trace de quem a chamou")
+	s.NotEqual(requisicao.SpanContext().TraceID(), raiz.SpanContext().TraceID())
+	s.Equal(raiz.SpanContext().TraceID().String(), consulta.TraceID)
+	for _, span := range spans[:2] {
+		s.Equal("AmostraCep", span.Name())
+		s.Equal(raiz.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+	s.Equal(trace.SpanKindInternal, raiz.SpanKind())
+	s.Equal(codes.Error, raiz.Status().Code)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
 )
@@ -20,26 +21,59 @@ func NewTemperaturasService(
 	}
 }
 
-func (s *TemperaturasService) Processa(cep string) (*usecases.DadosTemperaturas, error) {
+func (s *TemperaturasService) Processa(ctx context.Context, cep string) (*usecases.DadosTemperaturas, error) {
 	cepDomain, err := domain.NewCep(cep)
 	if err != nil {
 		return nil, err
 	}
 
-	dadosCep, err := s.consutlaCepUseCase.ConsultaCep(cepDomain)
+	dadosCep, err := s.consutlaCepUseCase.ConsultaCep(ctx, cepDomain)
 	if err != nil {
 		return nil, err
 	}
 
-	localidadeDomain, err := domain.NewLocalidade(dadosCep.Localidade)
+	localidadeDomain, err := domain.NewLocalidade(dadosCep.Localidade, dadosCep.Uf)
 	if err != nil {
 		return nil, err
 	}
+	// O código IBGE só enriquece a localidade; um código inválido da ViaCEP não impede a consulta.
+	if comIbge, err := localidadeDomain.ComIbge(dadosCep.Ibge); err == nil {
+		localidadeDomain = comIbge
+	}
 
-	dadosTemperaturas, err := s.calculaTemperaturasUseCase.Execute(localidadeDomain)
+	dadosTemperaturas, err := s.calculaTemperaturasUseCase.Execute(ctx, localidadeDomain)
 	if err != nil {
 		return nil, err
 	}
+	dadosTemperaturas.Cep = cepDomain.Codigo()
+	dadosTemperaturas.Uf = dadosCep.Uf
+	dadosTemperaturas.Address = dadosCep.Endereco()
 
 	return dadosTemperaturas, nil
 }
+
+// ProcessaCidade calcula as temperaturas de uma cidade informada pelo nome e pela UF, sem consultar CEP.
+// O nome tem a caixa normalizada, mas mantém os acentos recebidos.
+func (s *TemperaturasService) ProcessaCidade(ctx context.Context, uf, cidade string) (*usecases.DadosTemperaturas, error) {
+	localidadeDomain, err := domain.NewLocalidade(domain.NormalizaNomeCidade(cidade), uf)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculaTemperaturasUseCase.Execute(ctx, localidadeDomain)
+}
+
+// Endereco consulta só o endereço do CEP, sem passar pelo provedor de clima.
+func (s *TemperaturasService) Endereco(ctx context.Context, cep string) (*usecases.Endereco, error) {
+	cepDomain, err := domain.NewCep(cep)
+	if err != nil {
+		return nil, err
+	}
+
+	dadosCep, err := s.consutlaCepUseCase.ConsultaCep(ctx, cepDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	return dadosCep.Endereco(), nil
+}
@@ -1,11 +1,10 @@
 package service
 
 import (
-	"fmt"
+	"context"
 	"testing"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
-	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/usecases"
 	"github.com/stretchr/testify/mock"
@@ -25,7 +24,7 @@ type ViaCepClientMock struct {
 	mock.Mock
 }
 
-func (m *ViaCepClientMock) ConsultaCep(cep string) (*clients.DadosCepResponse, error) {
+func (m *ViaCepClientMock) ConsultaCep(ctx context.Context, cep string) (*clients.DadosCepResponse, error) {
 	args := m.Called(cep)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +36,7 @@ type WeatherApiClientMock struct {
 	mock.Mock
 }
 
-func (m *WeatherApiClientMock) ConsultaClima(cidade string) (*clients.WeatherResponse, error) {
+func (m *WeatherApiClientMock) ConsultaClima(ctx context.Context, cidade string) (*clients.WeatherResponse, error) {
 	args := m.Called(cidade)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -58,7 +57,7 @@ func (s *TemperaturasServiceTestSuite) SetupTest() {
 	s.service = NewTemperaturasService(s.consultaCepUseCase, s.calculaTemperaturasUseCase)
 }
 
-func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValido() {
+func (s *TemperaturasServiceTestSuite) TestProcessaTemperaturasComCepValido() {
 	// Arrange
 	dadosCepResponseMock := &clients.DadosCepResponse{
 		Cep:         "01001000",
@@ -75,16 +74,16 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValido() {
 		},
 	}
 
-	expectedCelsius := fmt.Sprintf("%.1f", weatherResponseMock.Current.TempC)
-	expectedFahrenheit := fmt.Sprintf("%.1f", helpers.CelsiusToFahrenheit(weatherResponseMock.Current.TempC))
-	expectedKelvin := fmt.Sprintf("%.1f", helpers.CelsiusToKelvin(weatherResponseMock.Current.TempC))
+	expectedCelsius := "25.0"
+	expectedFahrenheit := "77.0"
+	expectedKelvin := "298.2"
 
 	// Mocking the expected behavior
 	s.viacepClientMock.On("ConsultaCep", "01001000").Return(dadosCepResponseMock, nil)
-	s.weatherapiClientMock.On("ConsultaClima", "São Paulo").Return(weatherResponseMock, nil)
+	s.weatherapiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(weatherResponseMock, nil)
 
 	// Act
-	dadosTemperaturas, err := s.service.Processa("01001000")
+	dadosTemperaturas, err := s.service.Processa(context.Background(), "01001000")
 
 	// Assert
 	s.NoError(err)
@@ -96,7 +95,7 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValido() {
 	s.weatherapiClientMock.AssertExpectations(s.T())
 }
 
-func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepInexistente() {
+func (s *TemperaturasServiceTestSuite) TestProcessaTemperaturasComCepInexistente() {
 	// Arrange
 	dadosCepResponseMock := &clients.DadosCepResponse{
 		Erro: "true",
@@ -105,10 +104,10 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepInexistente() {
 	expectedErrDadosCepResponse := erros.ErrZipCodeNotFound
 
 	// Mocking the expected behavior
-	s.viacepClientMock.On("ConsultaCep", "00000000").Return(dadosCepResponseMock, expectedErrDadosCepResponse)
+	s.viacepClientMock.On("ConsultaCep", "99999999").Return(dadosCepResponseMock, expectedErrDadosCepResponse)
 
 	// Act
-	_, err := s.service.Processa("00000000")
+	_, err := s.service.Processa(context.Background(), "99999999")
 
 	// Assert
 	s.Error(err)
@@ -119,12 +118,12 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepInexistente() {
 	s.weatherapiClientMock.AssertNotCalled(s.T(), "ConsultaClima", mock.Anything)
 }
 
-func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepInvalido() {
+func (s *TemperaturasServiceTestSuite) TestProcessaTemperaturasComCepInvalido() {
 	// Arrange
-	expectedErr := erros.ErrZipCodeNotFound
+	expectedErr := erros.ErrInvalidZipCode
 
 	// Act
-	_, err := s.service.Processa("08931a30")
+	_, err := s.service.Processa(context.Background(), "08931a30")
 
 	// Assert
 	s.Error(err)
@@ -135,14 +134,14 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepInvalido() {
 	s.weatherapiClientMock.AssertNotCalled(s.T(), "ConsultaClima", mock.Anything)
 }
 
-func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValidoLocalidadeInvalida() {
+func (s *TemperaturasServiceTestSuite) TestProcessaTemperaturasComCepValidoLocalidadeInvalida() {
 	// Arrange
 	dadosCepResponseMock := &clients.DadosCepResponse{
 		Cep:         "01001000",
-		Logradouro:  "",
+		Logradouro:  "Praça da Sé",
 		Complemento: "lado ímpar",
 		Bairro:      "Sé",
-		Localidade:  "São Paulo",
+		Localidade:  "",
 		Uf:          "SP",
 	}
 
@@ -152,39 +151,35 @@ func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValidoLocalidad
 	s.viacepClientMock.On("ConsultaCep", "01001000").Return(dadosCepResponseMock, nil)
 
 	// Act
-	_, err := s.service.Processa("01001000")
+	_, err := s.service.Processa(context.Background(), "01001000")
 
 	// Assert
 	s.Error(err)
-	s.ErrorIs(err, erros.ErrInvalidZipCode)
-	s.Equal(expectedErr.Error(), err.Error())
+	s.ErrorIs(err, expectedErr)
 
 	s.viacepClientMock.AssertExpectations(s.T())
 	s.weatherapiClientMock.AssertNotCalled(s.T(), "ConsultaClima", mock.Anything)
 }
 
-func (s *TemperaturasServiceTestSuite) ProcessaTemperaturasComCepValidoLocalidadeInexistente() {
+func (s *TemperaturasServiceTestSuite) TestProcessaTemperaturasComCepValidoLocalidadeInexistente() {
 	// Arrange
 	dadosCepResponseMock := &clients.DadosCepResponse{
 		Cep:         "01001000",
-		Logradouro:  "XX",
+		Logradouro:  "Praça da Sé",
 		Complemento: "lado ímpar",
 		Bairro:      "Sé",
 		Localidade:  "São Paulo",
 		Uf:          "SP",
 	}
 
-	//expected WeatherResponse with only TempC
-	weatherResponseMock := &clients.WeatherResponse{}
-
 	expectedErr := erros.ErrCityNotFound
 
 	// Mocking the expected behavior
 	s.viacepClientMock.On("ConsultaCep", "01001000").Return(dadosCepResponseMock, nil)
-	s.weatherapiClientMock.On("ConsultaClima", "XX").Return(weatherResponseMock, erros.ErrCityNotFound)
+	s.weatherapiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(nil, erros.ErrCityNotFound)
 
 	// Act
-	_, err := s.service.Processa("01001000")
+	_, err := s.service.Processa(context.Background(), "01001000")
 
 	// Assert
 	s.Error(err)
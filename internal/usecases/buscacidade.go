@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+)
+
+// LogradouroPadrao é pesquisado quando nenhum logradouro é informado, já que a ViaCEP não pesquisa só pela
+// cidade. Quase toda cidade tem logradouros chamados "Rua ...".
+const LogradouroPadrao = "rua"
+
+// tamanhoMinimoPesquisa é o mínimo de caracteres que a ViaCEP aceita na cidade e no logradouro.
+const tamanhoMinimoPesquisa = 3
+
+type CidadeOutput struct {
+	City      string     `json:"city"`
+	State     string     `json:"state"`
+	Addresses []Endereco `json:"addresses"`
+}
+
+type BuscaCidadeUseCase struct {
+	enderecoClient clients.EnderecoClient
+}
+
+func NewBuscaCidadeUseCase(enderecoClient clients.EnderecoClient) *BuscaCidadeUseCase {
+	return &BuscaCidadeUseCase{
+		enderecoClient: enderecoClient,
+	}
+}
+
+// Execute devolve os CEPs dos logradouros da cidade encontrados pela ViaCEP. A ViaCEP também devolve
+// cidades com nomes parecidos, então só ficam os endereços cujo nome da cidade, sem acentos e sem caixa, é
+// igual ao pesquisado. O nome em City é o da ViaCEP, com acentos.
+func (u *BuscaCidadeUseCase) Execute(ctx context.Context, localidade *domain.Localidade, logradouro string) (*CidadeOutput, error) {
+	logradouro = strings.Join(strings.Fields(logradouro), " ")
+	if logradouro == "" {
+		logradouro = LogradouroPadrao
+	}
+
+	chave := domain.ChaveCidade(localidade.Name())
+	if utf8.RuneCountInString(chave) < tamanhoMinimoPesquisa || utf8.RuneCountInString(logradouro) < tamanhoMinimoPesquisa {
+		return nil, fmt.Errorf("%w: informe ao menos %d caracteres na cidade e no logradouro", erros.ErrInvalidAddressSearch, tamanhoMinimoPesquisa)
+	}
+
+	enderecos, err := u.enderecoClient.BuscaEnderecos(ctx, localidade.Uf(), chave, logradouro)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &CidadeOutput{State: localidade.Uf(), Addresses: []Endereco{}}
+	for _, endereco := range enderecos {
+		if domain.ChaveCidade(endereco.Localidade) != chave {
+			continue
+		}
+		dadosCep := DadosCep{
+			Cep:         endereco.Cep,
+			Logradouro:  endereco.Logradouro,
+			Complemento: endereco.Complemento,
+			Bairro:      endereco.Bairro,
+			Localidade:  endereco.Localidade,
+			Uf:          endereco.Uf,
+			Ibge:        endereco.Ibge,
+		}
+		output.Addresses = append(output.Addresses, *dadosCep.Endereco())
+	}
+
+	if len(output.Addresses) == 0 {
+		return nil, erros.ErrCityNotFound
+	}
+	output.City = output.Addresses[0].City
+
+	return output, nil
+}
@@ -1,11 +1,16 @@
 package usecases
 
 import (
+	"context"
+	"encoding/xml"
 	"fmt"
+	"time"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
-	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/pkg/observabilidade/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CalculaTemperaturasUseCase struct {
@@ -19,28 +24,119 @@ func NewCalculaTemperaturasUseCase(weatherapiClient clients.WeatherClient) *Calc
 }
 
 type DadosTemperaturas struct {
-	City       string `json:"city"`
-	Celcius    string `json:"temp_C"`
-	Fahrenheit string `json:"temp_F"`
-	Kelvin     string `json:"temp_K"`
+	XMLName    xml.Name `json:"-" xml:"temperaturas"`
+	City       string   `json:"city" xml:"city"`
+	Celcius    string   `json:"temp_C,omitempty" xml:"temp_C,omitempty"`
+	Fahrenheit string   `json:"temp_F,omitempty" xml:"temp_F,omitempty"`
+	Kelvin     string   `json:"temp_K,omitempty" xml:"temp_K,omitempty"`
+	Rankine    string   `json:"temp_R,omitempty" xml:"temp_R,omitempty"`
+
+	// Address só aparece na resposta quando pedido em ?incluir=endereco; Formata e V2 não o copiam.
+	Address *Endereco `json:"address,omitempty" xml:"address,omitempty"`
+
+	Temperatura domain.Temperatura `json:"-" xml:"-"`
+	ObservedAt  time.Time          `json:"-" xml:"-"`
+	// Condicao é o texto da condição do tempo no idioma da requisição, exposto somente pela V2.
+	Condicao string `json:"-" xml:"-"`
+
+	// Cep, Uf e Provedor não fazem parte da resposta; são guardados no histórico de consultas.
+	Cep      string `json:"-" xml:"-"`
+	Uf       string `json:"-" xml:"-"`
+	Provedor string `json:"-" xml:"-"`
+}
+
+type TemperaturaV2 struct {
+	Value  float64 `json:"value" xml:"value"`
+	Unit   string  `json:"unit" xml:"unit"`
+	Symbol string  `json:"symbol" xml:"symbol"`
+}
+
+type DadosTemperaturasV2 struct {
+	XMLName      xml.Name        `json:"-" xml:"temperaturas"`
+	City         string          `json:"city" xml:"city"`
+	Temperatures []TemperaturaV2 `json:"temperatures" xml:"temperatures>temperature"`
+	ObservedAt   time.Time       `json:"observed_at" xml:"observed_at"`
+	Condition    string          `json:"condition,omitempty" xml:"condition,omitempty"`
+	Address      *Endereco       `json:"address,omitempty" xml:"address,omitempty"`
 }
 
-func (u *CalculaTemperaturasUseCase) Execute(localidade *domain.Localidade) (*DadosTemperaturas, error) {
-	weatherResponse, err := u.weatherapiClient.ConsultaClima(localidade.Name())
+// Execute consulta o clima por "cidade, estado, país", como "Santa Rita, Paraíba, Brazil", para que o
+// provedor não escolha uma cidade homônima de outro estado ou país. Se ainda assim a região ou o país da
+// resposta forem outros, a leitura é recusada com erros.ErrLocationMismatch.
+func (u *CalculaTemperaturasUseCase) Execute(ctx context.Context, localidade *domain.Localidade) (*DadosTemperaturas, error) {
+	consulta := fmt.Sprintf("%s, %s, %s", localidade.Name(), localidade.Estado(), localidade.Pais())
+	weatherResponse, err := u.weatherapiClient.ConsultaClima(ctx, consulta)
 	if err != nil {
 		return nil, err
 	}
 
+	if !localidade.Corresponde(weatherResponse.Location.Region, weatherResponse.Location.Country) {
+		otel.AddSpanEvent(trace.SpanFromContext(ctx), "Localidade do provedor diverge da cidade", map[string]interface{}{
+			"consulta":        consulta,
+			"provedor":        weatherResponse.Provedor,
+			"regiao_recebida": weatherResponse.Location.Region,
+			"pais_recebido":   weatherResponse.Location.Country,
+		})
+		return nil, fmt.Errorf("%w: %s resolvida em %s, %s", erros.ErrLocationMismatch, consulta, weatherResponse.Location.Region, weatherResponse.Location.Country)
+	}
+
 	dadosTemperaturas := u.processaTemperaturas(weatherResponse)
 	dadosTemperaturas.City = localidade.Name()
+	dadosTemperaturas.Uf = localidade.Uf()
+	dadosTemperaturas.Provedor = weatherResponse.Provedor
 
 	return dadosTemperaturas, nil
 }
 
 func (u *CalculaTemperaturasUseCase) processaTemperaturas(weatherResponse *clients.WeatherResponse) *DadosTemperaturas {
-	return &DadosTemperaturas{
-		Celcius:    fmt.Sprintf("%.1f", weatherResponse.Current.TempC),
-		Fahrenheit: fmt.Sprintf("%.1f", helpers.CelsiusToFahrenheit(weatherResponse.Current.TempC)),
-		Kelvin:     fmt.Sprintf("%.1f", helpers.CelsiusToKelvin(weatherResponse.Current.TempC)),
+	dadosTemperaturas := &DadosTemperaturas{
+		Temperatura: domain.NewTemperatura(weatherResponse.Current.TempC),
+		ObservedAt:  time.Unix(int64(weatherResponse.Current.LastUpdatedEpoch), 0).UTC(),
+		Condicao:    weatherResponse.Current.Condition.Text,
 	}
+
+	return dadosTemperaturas.Formata(domain.OpcoesPadrao)
+}
+
+// Formata retorna uma cópia com as temperaturas nas unidades e no arredondamento das opções.
+func (d *DadosTemperaturas) Formata(opcoes domain.OpcoesTemperatura) *DadosTemperaturas {
+	opcoes = opcoes.ComPadrao(domain.OpcoesPadrao)
+
+	formatada := &DadosTemperaturas{
+		City:        d.City,
+		Temperatura: d.Temperatura,
+		ObservedAt:  d.ObservedAt,
+		Condicao:    d.Condicao,
+	}
+	campos := map[domain.Unidade]*string{
+		domain.Celsius:    &formatada.Celcius,
+		domain.Fahrenheit: &formatada.Fahrenheit,
+		domain.Kelvin:     &formatada.Kelvin,
+		domain.Rankine:    &formatada.Rankine,
+	}
+	for _, unidade := range opcoes.Unidades {
+		*campos[unidade] = d.Temperatura.Formata(unidade, opcoes)
+	}
+
+	return formatada
+}
+
+func (d *DadosTemperaturas) V2(opcoes domain.OpcoesTemperatura) *DadosTemperaturasV2 {
+	opcoes = opcoes.ComPadrao(domain.OpcoesPadrao)
+
+	dadosV2 := &DadosTemperaturasV2{
+		City:         d.City,
+		Temperatures: make([]TemperaturaV2, 0, len(opcoes.Unidades)),
+		ObservedAt:   d.ObservedAt,
+		Condition:    d.Condicao,
+	}
+	for _, unidade := range opcoes.Unidades {
+		dadosV2.Temperatures = append(dadosV2.Temperatures, TemperaturaV2{
+			Value:  d.Temperatura.Valor(unidade, opcoes),
+			Unit:   unidade.Nome(),
+			Symbol: unidade.Simbolo(),
+		})
+	}
+
+	return dadosV2
 }
@@ -1,11 +1,12 @@
 package usecases
 
 import (
-	"fmt"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
-	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/helpers"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -19,7 +20,7 @@ type WeatherApiClientMock struct {
 	mock.Mock
 }
 
-func (m *WeatherApiClientMock) ConsultaClima(cidade string) (*clients.WeatherResponse, error) {
+func (m *WeatherApiClientMock) ConsultaClima(ctx context.Context, cidade string) (*clients.WeatherResponse, error) {
 	args := m.Called(cidade)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,21 +37,21 @@ func (s *CalculaTemperaturasTestSuite) TestCalculaTemperaturas() {
 	weatherApiClientMock := new(WeatherApiClientMock)
 	calculaTemperaturasUseCase := NewCalculaTemperaturasUseCase(weatherApiClientMock)
 
-	cidade, _ := domain.NewLocalidade("São Paulo")
+	cidade, _ := domain.NewLocalidade("São Paulo", "SP")
 	expectedResponse := &clients.WeatherResponse{
 		Current: clients.Current{
 			TempC: 25.0,
 		},
 	}
 
-	expectedCelsius := fmt.Sprintf("%.1f", expectedResponse.Current.TempC)
-	expectedFahrenheit := fmt.Sprintf("%.1f", helpers.CelsiusToFahrenheit(expectedResponse.Current.TempC))
-	expectedKelvin := fmt.Sprintf("%.1f", helpers.CelsiusToKelvin(expectedResponse.Current.TempC))
+	expectedCelsius := "25.0"
+	expectedFahrenheit := "77.0"
+	expectedKelvin := "298.2"
 
-	weatherApiClientMock.On("ConsultaClima", cidade.Name()).Return(expectedResponse, nil)
+	weatherApiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(expectedResponse, nil)
 
 	//Act
-	dadosTemperaturas, err := calculaTemperaturasUseCase.Execute(cidade)
+	dadosTemperaturas, err := calculaTemperaturasUseCase.Execute(context.Background(), cidade)
 
 	//Assert
 	s.NoError(err)
@@ -60,3 +61,180 @@ func (s *CalculaTemperaturasTestSuite) TestCalculaTemperaturas() {
 
 	weatherApiClientMock.AssertExpectations(s.T())
 }
+
+func (s *CalculaTemperaturasTestSuite) TestCalculaTemperaturasRecusaLocalidadeDeOutroEstado() {
+	//Arrange
+	weatherApiClientMock := new(WeatherApiClientMock)
+	calculaTemperaturasUseCase := NewCalculaTemperaturasUseCase(weatherApiClientMock)
+
+	cidade, _ := domain.NewLocalidade("Santa Rita", "PB")
+	weatherApiClientMock.On("ConsultaClima", "Santa Rita, Paraíba, Brazil").Return(&clients.WeatherResponse{
+		Location: clients.Location{Name: "Santa Rita", Region: "Maranhao", Country: "Brazil"},
+		Current:  clients.Current{TempC: 30.0},
+	}, nil)
+
+	//Act
+	_, err := calculaTemperaturasUseCase.Execute(context.Background(), cidade)
+
+	//Assert
+	s.ErrorIs(err, erros.ErrLocationMismatch)
+	weatherApiClientMock.AssertExpectations(s.T())
+}
+
+func (s *CalculaTemperaturasTestSuite) TestCalculaTemperaturasAceitaRegiaoSemAcentos() {
+	//Arrange
+	weatherApiClientMock := new(WeatherApiClientMock)
+	calculaTemperaturasUseCase := NewCalculaTemperaturasUseCase(weatherApiClientMock)
+
+	cidade, _ := domain.NewLocalidade("Santa Rita", "PB")
+	weatherApiClientMock.On("ConsultaClima", "Santa Rita, Paraíba, Brazil").Return(&clients.WeatherResponse{
+		Location: clients.Location{Name: "Santa Rita", Region: "Paraiba", Country: "Brasil"},
+		Current:  clients.Current{TempC: 30.0},
+	}, nil)
+
+	//Act
+	dadosTemperaturas, err := calculaTemperaturasUseCase.Execute(context.Background(), cidade)
+
+	//Assert
+	s.NoError(err)
+	s.Equal("PB", dadosTemperaturas.Uf)
+	weatherApiClientMock.AssertExpectations(s.T())
+}
+
+func (s *CalculaTemperaturasTestSuite) TestConversoesDeTemperatura() {
+	precisao := func(valor int) *int { return &valor }
+
+	cenarios := []struct {
+		nome               string
+		tempC              float64
+		opcoes             domain.OpcoesTemperatura
+		expectedCelsius    string
+		expectedFahrenheit string
+		expectedKelvin     string
+		expectedRankine    string
+	}{
+		{
+			nome:               "padrao half-even com uma casa",
+			tempC:              20.1,
+			opcoes:             domain.OpcoesTemperatura{},
+			expectedCelsius:    "20.1",
+			expectedFahrenheit: "68.2",
+			expectedKelvin:     "293.2",
+		},
+		{
+			nome:               "ceil legado sem casas decimais",
+			tempC:              20.1,
+			opcoes:             domain.OpcoesTemperatura{Arredondamento: domain.ArredondamentoCeil, Precisao: precisao(0)},
+			expectedCelsius:    "20",
+			expectedFahrenheit: "69",
+			expectedKelvin:     "294",
+		},
+		{
+			nome:               "ceil legado nao arredonda celsius para cima",
+			tempC:              20.14,
+			opcoes:             domain.OpcoesTemperatura{Arredondamento: domain.ArredondamentoCeil, Precisao: precisao(1)},
+			expectedCelsius:    "20.1",
+			expectedFahrenheit: "68.3",
+			expectedKelvin:     "293.3",
+		},
+		{
+			nome:               "sem arredondamento",
+			tempC:              25,
+			opcoes:             domain.OpcoesTemperatura{Arredondamento: domain.SemArredondamento},
+			expectedCelsius:    "25",
+			expectedFahrenheit: "77",
+			expectedKelvin:     "298.15",
+		},
+		{
+			nome:            "rankine com duas casas",
+			tempC:           0,
+			opcoes:          domain.OpcoesTemperatura{Unidades: []domain.Unidade{domain.Rankine, domain.Celsius}, Precisao: precisao(2)},
+			expectedCelsius: "0.00",
+			expectedRankine: "491.67",
+		},
+		{
+			nome:               "temperatura negativa somente celsius e fahrenheit",
+			tempC:              -40,
+			opcoes:             domain.OpcoesTemperatura{Unidades: []domain.Unidade{domain.Celsius, domain.Fahrenheit}},
+			expectedCelsius:    "-40.0",
+			expectedFahrenheit: "-40.0",
+		},
+		{
+			nome:               "half-even arredonda para o par",
+			tempC:              -0.25,
+			opcoes:             domain.OpcoesTemperatura{Unidades: []domain.Unidade{domain.Celsius, domain.Kelvin, domain.Fahrenheit}},
+			expectedCelsius:    "-0.2",
+			expectedFahrenheit: "31.6",
+			expectedKelvin:     "272.9",
+		},
+	}
+
+	for _, cenario := range cenarios {
+		s.Run(cenario.nome, func() {
+			// Arrange
+			dadosTemperaturas := &DadosTemperaturas{Temperatura: domain.NewTemperatura(cenario.tempC)}
+
+			// Act
+			formatada := dadosTemperaturas.Formata(cenario.opcoes)
+
+			// Assert
+			s.Equal(cenario.expectedCelsius, formatada.Celcius)
+			s.Equal(cenario.expectedFahrenheit, formatada.Fahrenheit)
+			s.Equal(cenario.expectedKelvin, formatada.Kelvin)
+			s.Equal(cenario.expectedRankine, formatada.Rankine)
+		})
+	}
+}
+
+func (s *CalculaTemperaturasTestSuite) TestConversoesNumericas() {
+	cenarios := []struct {
+		tempC              float64
+		expectedFahrenheit float64
+		expectedKelvin     float64
+		expectedRankine    float64
+	}{
+		{0, 32, 273.15, 491.67},
+		{20.1, 68.18, 293.25, 527.85},
+		{100, 212, 373.15, 671.67},
+		{-273.15, -459.67, 0, 0},
+	}
+
+	for _, cenario := range cenarios {
+		temperatura := domain.NewTemperatura(cenario.tempC)
+
+		s.InDelta(cenario.tempC, temperatura.Em(domain.Celsius), 1e-9)
+		s.InDelta(cenario.expectedFahrenheit, temperatura.Em(domain.Fahrenheit), 1e-9)
+		s.InDelta(cenario.expectedKelvin, temperatura.Em(domain.Kelvin), 1e-9)
+		s.InDelta(cenario.expectedRankine, temperatura.Em(domain.Rankine), 1e-9)
+	}
+}
+
+func (s *CalculaTemperaturasTestSuite) TestCalculaTemperaturasV2() {
+	//Arrange
+	weatherApiClientMock := new(WeatherApiClientMock)
+	calculaTemperaturasUseCase := NewCalculaTemperaturasUseCase(weatherApiClientMock)
+
+	cidade, _ := domain.NewLocalidade("São Paulo", "SP")
+	weatherApiClientMock.On("ConsultaClima", "São Paulo, São Paulo, Brazil").Return(&clients.WeatherResponse{
+		Current: clients.Current{TempC: 20.1, LastUpdatedEpoch: 1749124800},
+	}, nil)
+	precisao := 2
+
+	//Act
+	dadosTemperaturas, err := calculaTemperaturasUseCase.Execute(context.Background(), cidade)
+	dadosV2 := dadosTemperaturas.V2(domain.OpcoesTemperatura{
+		Unidades: []domain.Unidade{domain.Kelvin, domain.Rankine},
+		Precisao: &precisao,
+	})
+
+	//Assert
+	s.NoError(err)
+	s.Equal("São Paulo", dadosV2.City)
+	s.Equal(time.Unix(1749124800, 0).UTC(), dadosV2.ObservedAt)
+	s.Equal([]TemperaturaV2{
+		{Value: 293.25, Unit: "kelvin", Symbol: "K"},
+		{Value: 527.85, Unit: "rankine", Symbol: "°R"},
+	}, dadosV2.Temperatures)
+
+	weatherApiClientMock.AssertExpectations(s.T())
+}
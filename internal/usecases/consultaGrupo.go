@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+)
+
+type LeituraGrupoOutput struct {
+	Cep          string          `json:"cep" xml:"cep"`
+	City         string          `json:"city,omitempty" xml:"city,omitempty"`
+	Temperatures []TemperaturaV2 `json:"temperatures,omitempty" xml:"temperatures>temperature,omitempty"`
+	ObservedAt   time.Time       `json:"observed_at,omitzero" xml:"observed_at,omitempty"`
+	Error        string          `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+type TemperaturasGrupoOutput struct {
+	XMLName  xml.Name             `json:"-" xml:"grupo"`
+	Group    string               `json:"group" xml:"group"`
+	Readings []LeituraGrupoOutput `json:"readings" xml:"readings>reading"`
+}
+
+// LeiturasCompartilhadas guarda as leituras dos grupos e limita as consultas simultâneas ao Serviço B para
+// todos os grupos e clientes do processo. Enquanto uma leitura está sendo consultada, os pedidos iguais
+// esperam por ela em vez de repetir a consulta.
+type LeiturasCompartilhadas struct {
+	vagas   chan struct{}
+	relogio func() time.Time
+
+	mu          sync.Mutex
+	entradas    map[string]leituraEmCache
+	emAndamento map[string]*consultaEmAndamento
+}
+
+type leituraEmCache struct {
+	dados    DadosTemperaturasOutputV2
+	expiraEm time.Time
+}
+
+type consultaEmAndamento struct {
+	pronta chan struct{}
+	dados  DadosTemperaturasOutputV2
+	err    error
+}
+
+func NewLeiturasCompartilhadas(concorrencia int) *LeiturasCompartilhadas {
+	return &LeiturasCompartilhadas{
+		vagas:       make(chan struct{}, concorrencia),
+		relogio:     time.Now,
+		entradas:    map[string]leituraEmCache{},
+		emAndamento: map[string]*consultaEmAndamento{},
+	}
+}
+
+// Busca devolve a leitura de chave do cache ou a consulta, guardando-a por validade. Erros não vão para o
+// cache.
+func (l *LeiturasCompartilhadas) Busca(ctx context.Context, chave string, validade time.Duration, consulta func(context.Context) (DadosTemperaturasOutputV2, error)) (DadosTemperaturasOutputV2, error) {
+	for {
+		l.mu.Lock()
+		if entrada, ok := l.entradas[chave]; ok && l.relogio().Before(entrada.expiraEm) {
+			l.mu.Unlock()
+			return entrada.dados, nil
+		}
+		andamento, ok := l.emAndamento[chave]
+		if !ok {
+			andamento = &consultaEmAndamento{pronta: make(chan struct{})}
+			l.emAndamento[chave] = andamento
+			l.mu.Unlock()
+			l.consulta(ctx, chave, validade, andamento, consulta)
+			return andamento.dados, andamento.err
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return DadosTemperaturasOutputV2{}, ctx.Err()
+		case <-andamento.pronta:
+		}
+		// Se quem consultava desistiu, quem ainda espera tenta de novo.
+		if errors.Is(andamento.err, context.Canceled) || errors.Is(andamento.err, context.DeadlineExceeded) {
+			continue
+		}
+		return andamento.dados, andamento.err
+	}
+}
+
+func (l *LeiturasCompartilhadas) consulta(ctx context.Context, chave string, validade time.Duration, andamento *consultaEmAndamento, consulta func(context.Context) (DadosTemperaturasOutputV2, error)) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.emAndamento, chave)
+		if andamento.err == nil && validade > 0 {
+			l.guarda(chave, andamento.dados, validade)
+		}
+		l.mu.Unlock()
+		close(andamento.pronta)
+	}()
+
+	select {
+	case <-ctx.Done():
+		andamento.err = ctx.Err()
+		return
+	case l.vagas <- struct{}{}:
+	}
+	defer func() { <-l.vagas }()
+
+	andamento.dados, andamento.err = consulta(ctx)
+}
+
+// guarda descarta as leituras vencidas a cada nova entrada, para que o cache não cresça além dos CEPs
+// consultados dentro da validade.
+func (l *LeiturasCompartilhadas) guarda(chave string, dados DadosTemperaturasOutputV2, validade time.Duration) {
+	agora := l.relogio()
+	for outra, entrada := range l.entradas {
+		if !agora.Before(entrada.expiraEm) {
+			delete(l.entradas, outra)
+		}
+	}
+	l.entradas[chave] = leituraEmCache{dados: dados, expiraEm: agora.Add(validade)}
+}
+
+// ConsultaGrupoUseCase lê as temperaturas de todos os CEPs de um grupo pelo mesmo caminho das consultas
+// individuais (CEP, cidade e clima no Serviço B), em paralelo e pelas leituras compartilhadas.
+type ConsultaGrupoUseCase struct {
+	service  *ProcessaTemperaturasService
+	leituras *LeiturasCompartilhadas
+	validade time.Duration
+}
+
+func NewConsultaGrupoUseCase(service *ProcessaTemperaturasService, leituras *LeiturasCompartilhadas, validade time.Duration) *ConsultaGrupoUseCase {
+	return &ConsultaGrupoUseCase{
+		service:  service,
+		leituras: leituras,
+		validade: validade,
+	}
+}
+
+// Execute devolve uma leitura por CEP, na ordem do grupo. Um CEP com erro traz em Error a mensagem pública
+// do erro, no idioma da requisição, e não interrompe os demais.
+func (u *ConsultaGrupoUseCase) Execute(ctx context.Context, nome string, ceps []string, opcoes domain.OpcoesTemperatura) *TemperaturasGrupoOutput {
+	output := &TemperaturasGrupoOutput{Group: nome, Readings: make([]LeituraGrupoOutput, len(ceps))}
+	chaveOpcoes := opcoes.Query().Encode()
+
+	var leituras sync.WaitGroup
+	for i, cep := range ceps {
+		leituras.Add(1)
+		go func() {
+			defer leituras.Done()
+
+			dados, err := u.leituras.Busca(ctx, cep+"?"+chaveOpcoes, u.validade, func(ctx context.Context) (DadosTemperaturasOutputV2, error) {
+				dados, err := u.service.ExecuteV2(ctx, DadosCepInput{Cep: cep, Opcoes: opcoes})
+				if err != nil {
+					return DadosTemperaturasOutputV2{}, err
+				}
+				return *dados, nil
+			})
+			if err != nil {
+				output.Readings[i] = LeituraGrupoOutput{Cep: cep, Error: i18n.Mensagem(i18n.DoContexto(ctx), erros.Classifica(err).Publico)}
+				return
+			}
+			output.Readings[i] = LeituraGrupoOutput{
+				Cep:          cep,
+				City:         dados.City,
+				Temperatures: dados.Temperatures,
+				ObservedAt:   dados.ObservedAt,
+			}
+		}()
+	}
+	leituras.Wait()
+
+	return output
+}
@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/erros"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/i18n"
+	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LeiturasCompartilhadasTestSuite struct {
+	suite.Suite
+	agora    time.Time
+	leituras *LeiturasCompartilhadas
+}
+
+func TestLeiturasCompartilhadasSuite(t *testing.T) {
+	suite.Run(t, new(LeiturasCompartilhadasTestSuite))
+}
+
+func (s *LeiturasCompartilhadasTestSuite) SetupTest() {
+	s.agora = time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	s.leituras = NewLeiturasCompartilhadas(2)
+	s.leituras.relogio = func() time.Time { return s.agora }
+}
+
+func (s *LeiturasCompartilhadasTestSuite) TestGuardaPelaValidade() {
+	// Arrange
+	consultas := 0
+	consulta := func(context.Context) (DadosTemperaturasOutputV2, error) {
+		consultas++
+		return DadosTemperaturasOutputV2{City: "São Paulo"}, nil
+	}
+
+	// Act
+	primeira, _ := s.leituras.Busca(context.Background(), "01001000", time.Minute, consulta)
+	s.agora = s.agora.Add(59 * time.Second)
+	segunda, _ := s.leituras.Busca(context.Background(), "01001000", time.Minute, consulta)
+	s.agora = s.agora.Add(time.Second)
+	_, _ = s.leituras.Busca(context.Background(), "01001000", time.Minute, consulta)
+
+	// Assert
+	s.Equal("São Paulo", primeira.City)
+	s.Equal(primeira, segunda)
+	s.Equal(2, consultas, "a leitura vence ao fim da validade")
+}
+
+func (s *LeiturasCompartilhadasTestSuite) TestErrosEValidadeZeroNaoVaoParaOCache() {
+	// Arrange
+	consultas := 0
+	falha := func(context.Context) (DadosTemperaturasOutputV2, error) {
+		consultas++
+		return DadosTemperaturasOutputV2{}, errors.New("serviço B indisponível")
+	}
+	semCache := func(context.Context) (DadosTemperaturasOutputV2, error) {
+		consultas++
+		return DadosTemperaturasOutputV2{}, nil
+	}
+
+	// Act
+	_, err := s.leituras.Busca(context.Background(), "01001000", time.Minute, falha)
+	_, _ = s.leituras.Busca(context.Background(), "01001000", time.Minute, falha)
+	_, _ = s.leituras.Busca(context.Background(), "20040002", 0, semCache)
+	_, _ = s.leituras.Busca(context.Background(), "20040002", 0, semCache)
+
+	// Assert
+	s.EqualError(err, "serviço B indisponível")
+	s.Equal(4, consultas)
+}
+
+func (s *LeiturasCompartilhadasTestSuite) TestConsultasIguaisSimultaneasSaoFeitasUmaVez() {
+	// Arrange
+	var consultas atomic.Int32
+	libera := make(chan struct{})
+	consulta := func(context.Context) (DadosTemperaturasOutputV2, error) {
+		consultas.Add(1)
+		<-libera
+		return DadosTemperaturasOutputV2{City: "São Paulo"}, nil
+	}
+
+	// Act
+	var pedidos sync.WaitGroup
+	cidades := make([]string, 5)
+	for i := range cidades {
+		pedidos.Add(1)
+		go func() {
+			defer pedidos.Done()
+			dados, _ := s.leituras.Busca(context.Background(), "01001000", time.Minute, consulta)
+			cidades[i] = dados.City
+		}()
+	}
+	s.Eventually(func() bool { return consultas.Load() == 1 }, time.Second, time.Millisecond)
+	close(libera)
+	pedidos.Wait()
+
+	// Assert
+	s.Equal(int32(1), consultas.Load())
+	s.Equal([]string{"São Paulo", "São Paulo", "São Paulo", "São Paulo", "São Paulo"}, cidades)
+}
+
+func (s *LeiturasCompartilhadasTestSuite) TestLimitaAsConsultasSimultaneas() {
+	// Arrange
+	var emAndamento, maximo atomic.Int32
+	consulta := func(context.Context) (DadosTemperaturasOutputV2, error) {
+		atual := emAndamento.Add(1)
+		defer emAndamento.Add(-1)
+		for {
+			anterior := maximo.Load()
+			if atual <= anterior || maximo.CompareAndSwap(anterior, atual) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return DadosTemperaturasOutputV2{}, nil
+	}
+
+	// Act
+	var pedidos sync.WaitGroup
+	for _, cep := range []string{"01001000", "20040002", "01310100", "30130010", "40020000", "80010000"} {
+		pedidos.Add(1)
+		go func() {
+			defer pedidos.Done()
+			s.leituras.Busca(context.Background(), cep, time.Minute, consulta)
+		}()
+	}
+	pedidos.Wait()
+
+	// Assert
+	s.Equal(int32(2), maximo.Load())
+}
+
+func (s *LeiturasCompartilhadasTestSuite) TestDesistenciaDeQuemConsultavaNaoAfetaQuemEspera() {
+	// Arrange
+	ctx, cancela := context.WithCancel(context.Background())
+	iniciada := make(chan struct{})
+	cancelada := func(ctx context.Context) (DadosTemperaturasOutputV2, error) {
+		close(iniciada)
+		<-ctx.Done()
+		return DadosTemperaturasOutputV2{}, ctx.Err()
+	}
+	go s.leituras.Busca(ctx, "01001000", time.Minute, cancelada)
+	<-iniciada
+
+	// Act
+	resultado := make(chan DadosTemperaturasOutputV2)
+	go func() {
+		dados, _ := s.leituras.Busca(context.Background(), "01001000", time.Minute, func(context.Context) (DadosTemperaturasOutputV2, error) {
+			return DadosTemperaturasOutputV2{City: "São Paulo"}, nil
+		})
+		resultado <- dados
+	}()
+	cancela()
+
+	// Assert
+	s.Equal("São Paulo", (<-resultado).City)
+}
+
+type CalculaTemperaturasClientMock struct {
+	mock.Mock
+}
+
+func (m *CalculaTemperaturasClientMock) CalculaTemperaturas(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (*clients.TemperaturasResponse, error) {
+	args := m.Called(cep)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*clients.TemperaturasResponse), args.Error(1)
+}
+
+func (m *CalculaTemperaturasClientMock) CalculaTemperaturasV2(ctx context.Context, cep string, opcoes domain.OpcoesTemperatura) (*clients.TemperaturasV2Response, error) {
+	args := m.Called(cep)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*clients.TemperaturasV2Response), args.Error(1)
+}
+
+type ConsultaGrupoTestSuite struct {
+	suite.Suite
+}
+
+func TestConsultaGrupoSuite(t *testing.T) {
+	suite.Run(t, new(ConsultaGrupoTestSuite))
+}
+
+func (s *ConsultaGrupoTestSuite) TestLeituraComFalhaNaoExpoeOsDetalhesDoUpstream() {
+	// Arrange
+	client := new(CalculaTemperaturasClientMock)
+	causa := fmt.Errorf("%w: 502 Bad Gateway: dial tcp 10.0.0.5:8080: connection refused", erros.ErrUpstreamUnavailable)
+	client.On("CalculaTemperaturasV2", "01001000").Return(nil, erros.NewErroUpstream("SERVICO_B", erros.TipoUpstreamIndisponivel, causa))
+	client.On("CalculaTemperaturasV2", "99999999").Return(nil, erros.ErrZipCodeNotFound)
+	useCase := NewConsultaGrupoUseCase(NewProcessaTemperaturasService(client), NewLeiturasCompartilhadas(2), 0)
+	ctx := i18n.NovoContexto(context.Background(), i18n.PortuguesBrasil)
+
+	// Act
+	output := useCase.Execute(ctx, "capitais", []string{"01001000", "99999999"}, domain.OpcoesTemperatura{})
+
+	// Assert
+	s.Equal("serviço externo indisponível", output.Readings[0].Error)
+	s.NotContains(output.Readings[0].Error, "10.0.0.5")
+	s.Equal("CEP não encontrado", output.Readings[1].Error)
+}
@@ -1,6 +1,10 @@
 package usecases
 
 import (
+	"context"
+	"encoding/xml"
+	"strings"
+
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
 )
@@ -16,6 +20,37 @@ type DadosCep struct {
 	Bairro      string
 	Localidade  string
 	Uf          string
+	Ibge        string
+}
+
+// Endereco é o endereço de um CEP já normalizado: CEP só com dígitos, sem espaços nas pontas e UF em
+// maiúsculas.
+type Endereco struct {
+	XMLName      xml.Name `json:"-" xml:"address"`
+	Cep          string   `json:"cep" xml:"cep"`
+	Street       string   `json:"street" xml:"street"`
+	Complement   string   `json:"complement,omitempty" xml:"complement,omitempty"`
+	Neighborhood string   `json:"neighborhood" xml:"neighborhood"`
+	City         string   `json:"city" xml:"city"`
+	State        string   `json:"state" xml:"state"`
+	Ibge         string   `json:"ibge,omitempty" xml:"ibge,omitempty"`
+}
+
+func (d *DadosCep) Endereco() *Endereco {
+	cep := strings.TrimSpace(d.Cep)
+	if cepDomain, err := domain.NewCep(cep); err == nil {
+		cep = cepDomain.Codigo()
+	}
+
+	return &Endereco{
+		Cep:          cep,
+		Street:       strings.TrimSpace(d.Logradouro),
+		Complement:   strings.TrimSpace(d.Complemento),
+		Neighborhood: strings.TrimSpace(d.Bairro),
+		City:         strings.TrimSpace(d.Localidade),
+		State:        strings.ToUpper(strings.TrimSpace(d.Uf)),
+		Ibge:         strings.TrimSpace(d.Ibge),
+	}
 }
 
 func NewConsultaCepUseCase(cepClient clients.CepClient) *ConsultaCepUseCase {
@@ -24,8 +59,8 @@ func NewConsultaCepUseCase(cepClient clients.CepClient) *ConsultaCepUseCase {
 	}
 }
 
-func (u *ConsultaCepUseCase) ConsultaCep(cep *domain.Cep) (*DadosCep, error) {
-	dadosCep, err := u.cepClient.ConsultaCep(cep.Codigo())
+func (u *ConsultaCepUseCase) ConsultaCep(ctx context.Context, cep *domain.Cep) (*DadosCep, error) {
+	dadosCep, err := u.cepClient.ConsultaCep(ctx, cep.Codigo())
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +72,7 @@ func (u *ConsultaCepUseCase) ConsultaCep(cep *domain.Cep) (*DadosCep, error) {
 		Bairro:      dadosCep.Bairro,
 		Localidade:  dadosCep.Localidade,
 		Uf:          dadosCep.Uf,
+		Ibge:        dadosCep.Ibge,
 	}, nil
 }
 
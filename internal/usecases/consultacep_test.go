@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"context"
 	"testing"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
@@ -20,7 +21,7 @@ type ViaCepClientMock struct {
 	mock.Mock
 }
 
-func (m *ViaCepClientMock) ConsultaCep(cep string) (*clients.DadosCepResponse, error) {
+func (m *ViaCepClientMock) ConsultaCep(ctx context.Context, cep string) (*clients.DadosCepResponse, error) {
 	args := m.Called(cep)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -53,7 +54,7 @@ func (s *ConsultaCepTestSuite) TestConsultaCepWithValidCep() {
 	s.cepClientMock.On("ConsultaCep", cep.Codigo()).Return(expectedResponse, nil)
 
 	// Act
-	dadosCep, err := s.consultaCepUseCase.ConsultaCep(cep)
+	dadosCep, err := s.consultaCepUseCase.ConsultaCep(context.Background(), cep)
 
 	// Assert
 	s.NoError(err)
@@ -69,7 +70,7 @@ func (s *ConsultaCepTestSuite) TestConsultaCepWithValidCep() {
 
 func (s *ConsultaCepTestSuite) TestConsultaCepWithUnexistCep() {
 	// Arrange
-	cep, _ := domain.NewCep("00000000")
+	cep, _ := domain.NewCep("99999999")
 	expectedResponse := &clients.DadosCepResponse{
 		Erro: "true",
 	}
@@ -78,7 +79,7 @@ func (s *ConsultaCepTestSuite) TestConsultaCepWithUnexistCep() {
 	s.cepClientMock.On("ConsultaCep", cep.Codigo()).Return(expectedResponse, expectedResponseError)
 
 	// Act
-	_, err := s.consultaCepUseCase.ConsultaCep(cep)
+	_, err := s.consultaCepUseCase.ConsultaCep(context.Background(), cep)
 
 	// Assert
 	s.Error(err)
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Representações CSV e protobuf (api/temperaturas.proto) das respostas dos serviços.
+
+var cabecalhoCSVV2 = []string{"city", "value", "unit", "symbol", "observed_at"}
+
+func (d *DadosTemperaturas) MarshalCSV() ([][]string, error) {
+	return registrosCSVV1(d.City, d.Celcius, d.Fahrenheit, d.Kelvin, d.Rankine), nil
+}
+
+func (d *DadosTemperaturas) MarshalProto() ([]byte, error) {
+	return marshalProtoV1(d.City, d.Celcius, d.Fahrenheit, d.Kelvin, d.Rankine), nil
+}
+
+func (d *DadosTemperaturasOutput) MarshalCSV() ([][]string, error) {
+	return registrosCSVV1(d.City, d.Celcius, d.Fahrenheit, d.Kelvin, d.Rankine), nil
+}
+
+func (d *DadosTemperaturasOutput) MarshalProto() ([]byte, error) {
+	return marshalProtoV1(d.City, d.Celcius, d.Fahrenheit, d.Kelvin, d.Rankine), nil
+}
+
+func (d *DadosTemperaturasV2) MarshalCSV() ([][]string, error) {
+	return registrosCSVV2(d.City, d.Temperatures, d.ObservedAt), nil
+}
+
+func (d *DadosTemperaturasV2) MarshalProto() ([]byte, error) {
+	return marshalProtoV2(d.City, d.Temperatures, d.ObservedAt), nil
+}
+
+func (d *DadosTemperaturasOutputV2) MarshalCSV() ([][]string, error) {
+	return registrosCSVV2(d.City, d.Temperatures, d.ObservedAt), nil
+}
+
+func (d *DadosTemperaturasOutputV2) MarshalProto() ([]byte, error) {
+	return marshalProtoV2(d.City, d.Temperatures, d.ObservedAt), nil
+}
+
+var cabecalhoCSVGrupo = []string{"cep", "city", "value", "unit", "symbol", "observed_at", "error"}
+
+// MarshalCSV usa receptor por valor porque o CSVEncoder recebe as leituras do grupo como lista.
+func (l LeituraGrupoOutput) MarshalCSV() ([][]string, error) {
+	registros := [][]string{cabecalhoCSVGrupo}
+	if l.Error != "" {
+		return append(registros, []string{l.Cep, "", "", "", "", "", l.Error}), nil
+	}
+	for _, registro := range registrosCSVV2(l.City, l.Temperatures, l.ObservedAt)[1:] {
+		registros = append(registros, append([]string{l.Cep}, append(registro, "")...))
+	}
+	return registros, nil
+}
+
+// registrosCSVV1 inclui apenas as colunas das unidades selecionadas.
+func registrosCSVV1(city, celsius, fahrenheit, kelvin, rankine string) [][]string {
+	cabecalho := []string{"city"}
+	registro := []string{city}
+	for _, coluna := range []struct{ nome, valor string }{
+		{"temp_C", celsius},
+		{"temp_F", fahrenheit},
+		{"temp_K", kelvin},
+		{"temp_R", rankine},
+	} {
+		if coluna.valor != "" {
+			cabecalho = append(cabecalho, coluna.nome)
+			registro = append(registro, coluna.valor)
+		}
+	}
+	return [][]string{cabecalho, registro}
+}
+
+func registrosCSVV2(city string, temperaturas []TemperaturaV2, observedAt time.Time) [][]string {
+	registros := [][]string{cabecalhoCSVV2}
+	for _, temperatura := range temperaturas {
+		registros = append(registros, []string{
+			city,
+			strconv.FormatFloat(temperatura.Value, 'f', -1, 64),
+			temperatura.Unit,
+			temperatura.Symbol,
+			observedAt.Format(time.RFC3339),
+		})
+	}
+	return registros
+}
+
+func marshalProtoV1(city, celsius, fahrenheit, kelvin, rankine string) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, city)
+	b = appendProtoString(b, 2, celsius)
+	b = appendProtoString(b, 3, fahrenheit)
+	b = appendProtoString(b, 4, kelvin)
+	b = appendProtoString(b, 5, rankine)
+	return b
+}
+
+func marshalProtoV2(city string, temperaturas []TemperaturaV2, observedAt time.Time) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, city)
+	for _, temperatura := range temperaturas {
+		var t []byte
+		t = protowire.AppendTag(t, 1, protowire.Fixed64Type)
+		t = protowire.AppendFixed64(t, math.Float64bits(temperatura.Value))
+		t = appendProtoString(t, 2, temperatura.Unit)
+		t = appendProtoString(t, 3, temperatura.Symbol)
+
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, t)
+	}
+
+	var ts []byte
+	ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, uint64(observedAt.Unix()))
+	if nanos := observedAt.Nanosecond(); nanos > 0 {
+		ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(nanos))
+	}
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, ts)
+
+	return b
+}
+
+func appendProtoString(b []byte, numero protowire.Number, valor string) []byte {
+	if valor == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, numero, protowire.BytesType)
+	return protowire.AppendString(b, valor)
+}
@@ -1,21 +1,37 @@
 package usecases
 
 import (
+	"context"
+	"encoding/xml"
 	"log"
+	"time"
 
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/domain"
 	"github.com/fabiohsgomes/go-expert-labs-deploy/internal/infra/clients"
 )
 
 type DadosCepInput struct {
-	Cep string `json:"cep"`
+	Cep    string                   `json:"cep"`
+	Opcoes domain.OpcoesTemperatura `json:"-"`
 }
 
 type DadosTemperaturasOutput struct {
-	City       string `json:"city"`
-	Celcius    string `json:"temp_C"`
-	Fahrenheit string `json:"temp_F"`
-	Kelvin     string `json:"temp_K"`
+	XMLName    xml.Name `json:"-" xml:"temperaturas"`
+	City       string   `json:"city" xml:"city"`
+	Celcius    string   `json:"temp_C,omitempty" xml:"temp_C,omitempty"`
+	Fahrenheit string   `json:"temp_F,omitempty" xml:"temp_F,omitempty"`
+	Kelvin     string   `json:"temp_K,omitempty" xml:"temp_K,omitempty"`
+	Rankine    string   `json:"temp_R,omitempty" xml:"temp_R,omitempty"`
+
+	ObservedAt time.Time `json:"-" xml:"-"`
+}
+
+type DadosTemperaturasOutputV2 struct {
+	XMLName      xml.Name        `json:"-" xml:"temperaturas"`
+	City         string          `json:"city" xml:"city"`
+	Temperatures []TemperaturaV2 `json:"temperatures" xml:"temperatures>temperature"`
+	ObservedAt   time.Time       `json:"observed_at" xml:"observed_at"`
+	Condition    string          `json:"condition,omitempty" xml:"condition,omitempty"`
 }
 
 type ProcessaTemperaturasService struct {
@@ -28,13 +44,13 @@ func NewProcessaTemperaturasService(client clients.CalculaTemperaturasClient) *P
 	}
 }
 
-func (s *ProcessaTemperaturasService) Execute(input DadosCepInput) (dados *DadosTemperaturasOutput, err error) {
+func (s *ProcessaTemperaturasService) Execute(ctx context.Context, input DadosCepInput) (dados *DadosTemperaturasOutput, err error) {
 	cep, err := domain.NewCep(input.Cep)
 	if err != nil {
 		return dados, err
 	}
 
-	response, err := s.client.CalculaTemperaturas(cep.Codigo())
+	response, err := s.client.CalculaTemperaturas(ctx, cep.Codigo(), input.Opcoes)
 	if err != nil {
 		log.Println(err.Error())
 		return dados, err
@@ -45,6 +61,36 @@ func (s *ProcessaTemperaturasService) Execute(input DadosCepInput) (dados *Dados
 		Celcius:    response.Celcius,
 		Fahrenheit: response.Fahrenheit,
 		Kelvin:     response.Kelvin,
+		Rankine:    response.Rankine,
+		ObservedAt: response.ObservedAt,
+	}
+
+	return dados, err
+}
+
+func (s *ProcessaTemperaturasService) ExecuteV2(ctx context.Context, input DadosCepInput) (dados *DadosTemperaturasOutputV2, err error) {
+	cep, err := domain.NewCep(input.Cep)
+	if err != nil {
+		return dados, err
+	}
+
+	response, err := s.client.CalculaTemperaturasV2(ctx, cep.Codigo(), input.Opcoes)
+	if err != nil {
+		log.Println(err.Error())
+		return dados, err
+	}
+
+	dados = &DadosTemperaturasOutputV2{
+		City:       response.City,
+		ObservedAt: response.ObservedAt,
+		Condition:  response.Condition,
+	}
+	for _, temperatura := range response.Temperatures {
+		dados.Temperatures = append(dados.Temperatures, TemperaturaV2{
+			Value:  temperatura.Value,
+			Unit:   temperatura.Unit,
+			Symbol: temperatura.Symbol,
+		})
 	}
 
 	return dados, err
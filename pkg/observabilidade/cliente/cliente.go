@@ -0,0 +1,41 @@
+package cliente
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Membro é o membro do baggage que leva o cliente autenticado no Serviço A até o Serviço B.
+const Membro = "cliente.id"
+
+// NovoContexto adiciona o cliente ao baggage, que o otelhttp propaga ao Serviço B junto com o trace.
+func NovoContexto(ctx context.Context, cliente string) context.Context {
+	membro, err := baggage.NewMemberRaw(Membro, cliente)
+	if err != nil {
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(membro)
+	if err != nil {
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// DoContexto retorna o cliente autenticado no Serviço A ou recebido no baggage pelo Serviço B. Sem
+// autenticação, retorna vazio.
+func DoContexto(ctx context.Context) string {
+	return baggage.FromContext(ctx).Member(Membro).Value()
+}
+
+// SemCliente remove o cliente do baggage, para que o informado por quem chama não seja tomado como
+// autenticado.
+func SemCliente(ctx context.Context) context.Context {
+	bag := baggage.FromContext(ctx)
+	if bag.Member(Membro).Key() == "" {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag.DeleteMember(Membro))
+}
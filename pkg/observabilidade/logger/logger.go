@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+)
+
+var nivel = new(slog.LevelVar)
+
+// Init direciona o slog e o pacote log para stderr no formato texto, filtrando pelo nível informado.
+// As chamadas a log.Printf passam a ser registradas no nível info.
+func Init(inicial slog.Level) {
+	nivel.Set(inicial)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: nivel})))
+}
+
+// DefineNivel altera o nível de todos os loggers criados por Init, sem recriá-los.
+func DefineNivel(novo slog.Level) {
+	nivel.Set(novo)
+}
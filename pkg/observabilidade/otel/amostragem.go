@@ -0,0 +1,41 @@
+package otel
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// amostrador aplica a taxa de amostragem vigente aos traces iniciados no serviço; a taxa pode ser
+// trocada em tempo de execução por DefineTaxaAmostragem.
+type amostrador struct {
+	atual atomic.Pointer[sdktrace.Sampler]
+}
+
+var amostragem = newAmostrador(1)
+
+func newAmostrador(taxa float64) *amostrador {
+	a := &amostrador{}
+	a.define(taxa)
+	return a
+}
+
+func (a *amostrador) define(taxa float64) {
+	sampler := sdktrace.TraceIDRatioBased(taxa)
+	a.atual.Store(&sampler)
+}
+
+func (a *amostrador) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return (*a.atual.Load()).ShouldSample(p)
+}
+
+func (a *amostrador) Description() string {
+	return fmt.Sprintf("AmostradorDinamico{%s}", (*a.atual.Load()).Description())
+}
+
+// DefineTaxaAmostragem troca a fração (0 a 1) dos traces amostrados. Requisições que já chegam com um
+// trace pai seguem a decisão do pai.
+func DefineTaxaAmostragem(taxa float64) {
+	amostragem.define(taxa)
+}
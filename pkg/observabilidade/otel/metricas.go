@@ -0,0 +1,51 @@
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// intervaloMetricas é o intervalo de exportação das métricas para o collector.
+var intervaloMetricas = 30 * time.Second
+
+// InitMetricas configura o provedor de métricas OTLP, enviadas ao mesmo collector dos traces.
+func InitMetricas(serviceName, collectorEndpoint string, tlsConfig *tls.Config) func(context.Context) error {
+	if collectorEndpoint == "" {
+		collectorEndpoint = "localhost:4317"
+	}
+
+	exporter, err := otlpmetricgrpc.New(context.Background(),
+		otlpmetricgrpc.WithEndpoint(collectorEndpoint),
+		otlpmetricgrpc.WithTLSCredentials(credenciais(tlsConfig)),
+	)
+	if err != nil {
+		log.Fatalf("falha ao criar o exportador OTLP de métricas: %v", err)
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(intervaloMetricas))),
+		sdkmetric.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+		)),
+	)
+
+	otel.SetMeterProvider(mp)
+
+	return mp.Shutdown
+}
+
+// GetMeter retorna um meter do provedor global. Os instrumentos criados antes de InitMetricas passam a
+// exportar assim que o provedor é configurado.
+func GetMeter(nome string) metric.Meter {
+	return otel.Meter(nome)
+}
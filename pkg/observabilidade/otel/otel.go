@@ -2,9 +2,9 @@ package otel
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -17,12 +17,13 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-// InitTracer configura o provedor de tracer OpenTelemetry para enviar traces via OTLP.
-func InitTracer(serviceName string) func(context.Context) error {
-	collectorEndpoint := os.Getenv("OTEL_COLLECTOR_ENDPOINT")
+// InitTracer configura o provedor de tracer OpenTelemetry para enviar traces via OTLP. Com tlsConfig
+// a conexão com o collector usa TLS; sem ele, a conexão é aberta.
+func InitTracer(serviceName, collectorEndpoint string, tlsConfig *tls.Config) func(context.Context) error {
 	if collectorEndpoint == "" {
 		collectorEndpoint = "localhost:4317" // Endereço padrão do gRPC do OTel Collector
 	}
@@ -36,7 +37,7 @@ func InitTracer(serviceName string) func(context.Context) error {
 	// As opções grpc.WithBlock() e grpc.WithTimeout() foram removidas.
 	conn, err := grpc.NewClient(
 		collectorEndpoint,
-		grpc.WithTransportCredentials(insecure.NewCredentials()), // Usar credenciais seguras em produção
+		grpc.WithTransportCredentials(credenciais(tlsConfig)),
 		// Não precisamos mais de WithBlock ou WithTimeout aqui, pois o contexto faz isso.
 		// grpc.WithBlock(), // DEPRECATED
 		// grpc.WithTimeout(5*time.Second), // DEPRECATED
@@ -54,6 +55,7 @@ func InitTracer(serviceName string) func(context.Context) error {
 	// Cria o provedor de tracer
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(amostragem)),
 		sdktrace.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceNameKey.String(serviceName),
@@ -81,6 +83,13 @@ func InitTracer(serviceName string) func(context.Context) error {
 	}
 }
 
+func credenciais(tlsConfig *tls.Config) credentials.TransportCredentials {
+	if tlsConfig == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(tlsConfig)
+}
+
 // GetTracer retorna um tracer para o serviço especificado.
 func GetTracer(serviceName string) trace.Tracer {
 	return otel.Tracer(serviceName)
@@ -0,0 +1,49 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header é o cabeçalho usado para receber e propagar o identificador da requisição.
+const Header = "X-Request-ID"
+
+const tamanhoMaximo = 128
+
+type chaveContexto struct{}
+
+// Gera cria um identificador aleatório de 32 caracteres hexadecimais.
+func Gera() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Valido aceita identificadores recebidos de clientes com até 128 caracteres entre letras, dígitos e - _ . :
+func Valido(id string) bool {
+	if id == "" || len(id) > tamanhoMaximo {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func NovoContexto(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveContexto{}, id)
+}
+
+// DoContexto retorna o identificador da requisição em andamento ou vazio.
+func DoContexto(ctx context.Context) string {
+	id, _ := ctx.Value(chaveContexto{}).(string)
+	return id
+}